@@ -0,0 +1,329 @@
+package acl
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"suwei.sa_token/core/adapter"
+)
+
+// Resource Access Control List
+// 资源访问控制列表
+//
+// Storage layout, one key per granted action | 存储结构，每个授权操作一个键:
+//   {prefix}acl:res:{type}:{id}:{subject}:{action}  -> create time   grant entry | 授权条目
+//   {prefix}acl:sub:{subject}:{type}:{id}:{action}  -> create time   subject index | 主体索引
+//
+// Grant and Revoke only set or delete whole keys, so concurrent writers on any number of
+// instances never overwrite each other's entries. Each segment is escaped, so a ":" or a glob
+// character inside a type, ID, subject or action cannot collide with another key or pattern.
+// Grant 和 Revoke 只设置或删除整个键，因此任意数量实例上的并发写入不会互相覆盖。每个片段都会转义，
+// 类型、ID、主体或操作中的 ":" 或通配字符不会与其他键或匹配模式冲突。
+//
+// Subjects | 主体:
+//   user:{loginID}   a single account | 单个账号
+//   role:{role}      every account having the role | 拥有该角色的所有账号
+//   *                everyone | 所有人
+//
+// Usage | 用法:
+//   store := acl.NewStore(storage, "satoken:")
+//   store.Grant(acl.UserSubject("42"), "document", "9", "read")
+//   store.Grant(acl.RoleSubject("editor"), "document", acl.Wildcard, "read", "write")
+//   store.Check([]string{acl.UserSubject("42")}, "document", "9", "read") // true
+
+// Constants for ACL | ACL常量
+const (
+	Wildcard = "*" // Any subject, resource ID or action | 任意主体、资源ID或操作
+
+	UserSubjectPrefix = "user:" // User subject prefix | 用户主体前缀
+	RoleSubjectPrefix = "role:" // Role subject prefix | 角色主体前缀
+
+	ResourceKeySuffix = "acl:res:" // Resource key suffix after prefix | 资源键后缀
+	SubjectKeySuffix  = "acl:sub:" // Subject key suffix after prefix | 主体键后缀
+
+	keySeparator = ":" // Separates escaped key segments | 分隔转义后的键片段
+)
+
+// Error variables | 错误变量
+var (
+	ErrInvalidGrant    = fmt.Errorf("invalid acl grant")
+	ErrInvalidResource = fmt.Errorf("acl resource type and id cannot be empty")
+	ErrInvalidACLData  = fmt.Errorf("invalid acl data")
+)
+
+// Key segment escaping, "%" first so escapes stay reversible | 键片段转义，"%"优先以保证可逆
+var (
+	segmentEscaper   = strings.NewReplacer("%", "%25", ":", "%3A", "*", "%2A", "?", "%3F", "[", "%5B", "]", "%5D", "\\", "%5C")
+	segmentUnescaper = strings.NewReplacer("%3A", ":", "%2A", "*", "%3F", "?", "%5B", "[", "%5D", "]", "%5C", "\\", "%25", "%")
+)
+
+// UserSubject Builds subject for a login ID | 构建登录ID主体
+func UserSubject(loginID string) string {
+	return UserSubjectPrefix + loginID
+}
+
+// RoleSubject Builds subject for a role | 构建角色主体
+func RoleSubject(role string) string {
+	return RoleSubjectPrefix + role
+}
+
+// Grant ACL grant | ACL授权
+type Grant struct {
+	Subject      string   `json:"subject"`      // Subject | 主体
+	ResourceType string   `json:"resourceType"` // Resource type | 资源类型
+	ResourceID   string   `json:"resourceId"`   // Resource ID, "*" for every resource of the type | 资源ID，"*"表示该类型的所有资源
+	Actions      []string `json:"actions"`      // Granted actions, "*" for all | 授权的操作，"*"表示全部
+	CreateTime   int64    `json:"createTime"`   // Creation timestamp | 创建时间戳
+}
+
+// Allows Checks if grant allows action | 检查授权是否允许该操作
+func (g *Grant) Allows(action string) bool {
+	for _, a := range g.Actions {
+		if a == Wildcard || a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// Store ACL store on top of adapter.Storage | 基于adapter.Storage的ACL存储
+type Store struct {
+	storage   adapter.Storage
+	keyPrefix string
+}
+
+// NewStore Creates a new ACL store | 创建新的ACL存储
+func NewStore(storage adapter.Storage, prefix string) *Store {
+	return &Store{
+		storage:   storage,
+		keyPrefix: prefix,
+	}
+}
+
+// Grant Grants actions on a resource to a subject | 将资源上的操作授权给主体
+func (s *Store) Grant(subject, resourceType, resourceID string, actions ...string) error {
+	if subject == "" || resourceType == "" || resourceID == "" || len(actions) == 0 {
+		return ErrInvalidGrant
+	}
+
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	for _, action := range actions {
+		if action == "" {
+			return ErrInvalidGrant
+		}
+		key := s.getEntryKey(resourceType, resourceID, subject, action)
+		if s.storage.Exists(key) {
+			continue // Keep the original create time | 保留原始创建时间
+		}
+		if err := s.storage.Set(key, now, 0); err != nil {
+			return fmt.Errorf("failed to store acl grant: %w", err)
+		}
+		if err := s.storage.Set(s.getIndexKey(subject, resourceType, resourceID, action), now, 0); err != nil {
+			return fmt.Errorf("failed to store acl index: %w", err)
+		}
+	}
+	return nil
+}
+
+// Revoke Revokes actions from a subject, no actions means all | 撤销主体的操作授权，不传操作表示全部撤销
+func (s *Store) Revoke(subject, resourceType, resourceID string, actions ...string) error {
+	if len(actions) == 0 {
+		keys, err := s.storage.Keys(s.getEntryKey(resourceType, resourceID, subject) + keySeparator + "*")
+		if err != nil {
+			return fmt.Errorf("failed to list acl grants: %w", err)
+		}
+		for _, key := range keys {
+			if segments := s.parseKey(key, ResourceKeySuffix); len(segments) == 4 {
+				actions = append(actions, segments[3])
+			}
+		}
+	}
+
+	for _, action := range actions {
+		if err := s.storage.Delete(
+			s.getEntryKey(resourceType, resourceID, subject, action),
+			s.getIndexKey(subject, resourceType, resourceID, action),
+		); err != nil {
+			return fmt.Errorf("failed to delete acl grant: %w", err)
+		}
+	}
+	return nil
+}
+
+// RevokeResource Revokes every grant on a resource | 撤销资源上的所有授权
+func (s *Store) RevokeResource(resourceType, resourceID string) error {
+	keys, err := s.storage.Keys(s.getEntryKey(resourceType, resourceID) + keySeparator + "*")
+	if err != nil {
+		return fmt.Errorf("failed to list acl grants: %w", err)
+	}
+	for _, key := range keys {
+		segments := s.parseKey(key, ResourceKeySuffix)
+		if len(segments) != 4 {
+			continue
+		}
+		if err := s.storage.Delete(key, s.getIndexKey(segments[2], resourceType, resourceID, segments[3])); err != nil {
+			return fmt.Errorf("failed to delete acl grant: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListByResource Lists grants on a resource | 列出资源上的授权
+func (s *Store) ListByResource(resourceType, resourceID string) ([]*Grant, error) {
+	keys, err := s.storage.Keys(s.getEntryKey(resourceType, resourceID) + keySeparator + "*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list acl grants: %w", err)
+	}
+
+	// key segments: type, id, subject, action | 键片段：类型、ID、主体、操作
+	return s.collectGrants(keys, ResourceKeySuffix, func(seg []string) (string, string, string, string) {
+		return seg[2], seg[0], seg[1], seg[3]
+	})
+}
+
+// ListBySubject Lists grants of a subject | 列出主体的授权
+func (s *Store) ListBySubject(subject string) ([]*Grant, error) {
+	keys, err := s.storage.Keys(s.getIndexKey(subject) + keySeparator + "*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list acl grants: %w", err)
+	}
+
+	// key segments: subject, type, id, action | 键片段：主体、类型、ID、操作
+	return s.collectGrants(keys, SubjectKeySuffix, func(seg []string) (string, string, string, string) {
+		return seg[0], seg[1], seg[2], seg[3]
+	})
+}
+
+// Check Checks if any subject may perform action on resource | 检查任一主体是否可以对资源执行操作
+// Grants on the exact resource and on the "*" resource of the type are both considered | 同时考虑具体资源和该类型"*"资源上的授权
+func (s *Store) Check(subjects []string, resourceType, resourceID, action string) bool {
+	if resourceType == "" || resourceID == "" {
+		return false
+	}
+
+	ids := []string{resourceID}
+	if resourceID != Wildcard {
+		ids = append(ids, Wildcard)
+	}
+	actions := []string{action}
+	if action != Wildcard {
+		actions = append(actions, Wildcard)
+	}
+	subjects = append([]string{Wildcard}, subjects...)
+
+	for _, id := range ids {
+		for _, subject := range subjects {
+			for _, a := range actions {
+				if s.storage.Exists(s.getEntryKey(resourceType, id, subject, a)) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// ============ Internal Methods | 内部方法 ============
+
+// collectGrants Groups action keys into grants, fields maps key segments to subject, type, id and action |
+// 将操作键聚合为授权，fields 将键片段映射为主体、类型、ID和操作
+func (s *Store) collectGrants(keys []string, suffix string, fields func([]string) (string, string, string, string)) ([]*Grant, error) {
+	type grantKey struct{ subject, resourceType, resourceID string }
+	grants := make(map[grantKey]*Grant)
+	createTimes := make(map[*Grant]map[string]int64)
+
+	for _, key := range keys {
+		segments := s.parseKey(key, suffix)
+		if len(segments) != 4 {
+			return nil, ErrInvalidACLData
+		}
+		subject, resourceType, resourceID, action := fields(segments)
+
+		data, err := s.storage.Get(key)
+		if err != nil || data == nil {
+			continue // Revoked meanwhile | 期间已被撤销
+		}
+		str, ok := data.(string)
+		if !ok {
+			return nil, ErrInvalidACLData
+		}
+		createTime, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidACLData, err)
+		}
+
+		gk := grantKey{subject, resourceType, resourceID}
+		g, exists := grants[gk]
+		if !exists {
+			g = &Grant{Subject: subject, ResourceType: resourceType, ResourceID: resourceID, CreateTime: createTime}
+			grants[gk] = g
+			createTimes[g] = make(map[string]int64)
+		}
+		g.Actions = append(g.Actions, action)
+		createTimes[g][action] = createTime
+		if createTime < g.CreateTime {
+			g.CreateTime = createTime
+		}
+	}
+
+	result := make([]*Grant, 0, len(grants))
+	for _, g := range grants {
+		times := createTimes[g]
+		// Actions in the order they were granted | 按授权顺序排列操作
+		sort.Slice(g.Actions, func(i, j int) bool {
+			if ti, tj := times[g.Actions[i]], times[g.Actions[j]]; ti != tj {
+				return ti < tj
+			}
+			return g.Actions[i] < g.Actions[j]
+		})
+		result = append(result, g)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.ResourceType != b.ResourceType {
+			return a.ResourceType < b.ResourceType
+		}
+		if a.ResourceID != b.ResourceID {
+			return a.ResourceID < b.ResourceID
+		}
+		return a.Subject < b.Subject
+	})
+	return result, nil
+}
+
+// getEntryKey Gets storage key of a grant entry or, with fewer segments, its prefix |
+// 获取授权条目的存储键，片段较少时获取其前缀
+func (s *Store) getEntryKey(resourceType, resourceID string, rest ...string) string {
+	return s.buildKey(ResourceKeySuffix, append([]string{resourceType, resourceID}, rest...))
+}
+
+// getIndexKey Gets storage key of a subject index entry or, with fewer segments, its prefix |
+// 获取主体索引条目的存储键，片段较少时获取其前缀
+func (s *Store) getIndexKey(subject string, rest ...string) string {
+	return s.buildKey(SubjectKeySuffix, append([]string{subject}, rest...))
+}
+
+// buildKey Joins escaped segments after the key suffix | 在键后缀后拼接转义后的片段
+func (s *Store) buildKey(suffix string, segments []string) string {
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		escaped[i] = segmentEscaper.Replace(segment)
+	}
+	return s.keyPrefix + suffix + strings.Join(escaped, keySeparator)
+}
+
+// parseKey Splits a key back into unescaped segments | 将键拆分还原为未转义的片段
+func (s *Store) parseKey(key, suffix string) []string {
+	rest, ok := strings.CutPrefix(key, s.keyPrefix+suffix)
+	if !ok {
+		return nil
+	}
+	segments := strings.Split(rest, keySeparator)
+	for i, segment := range segments {
+		segments[i] = segmentUnescaper.Replace(segment)
+	}
+	return segments
+}
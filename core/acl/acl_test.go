@@ -0,0 +1,137 @@
+package acl
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// mapStorage minimal in-memory adapter.Storage for tests
+type mapStorage map[string]any
+
+func (m mapStorage) Set(key string, value any, _ time.Duration) error { m[key] = value; return nil }
+func (m mapStorage) Get(key string) (any, error)                      { return m[key], nil }
+func (m mapStorage) Exists(key string) bool                           { _, ok := m[key]; return ok }
+func (m mapStorage) Expire(string, time.Duration) error               { return nil }
+func (m mapStorage) TTL(string) (time.Duration, error)                { return -1, nil }
+func (m mapStorage) Clear() error                                     { return nil }
+func (m mapStorage) Ping() error                                      { return nil }
+func (m mapStorage) Delete(keys ...string) error {
+	for _, k := range keys {
+		delete(m, k)
+	}
+	return nil
+}
+func (m mapStorage) Keys(pattern string) ([]string, error) {
+	var keys []string
+	for k := range m {
+		if strings.HasPrefix(k, strings.TrimSuffix(pattern, "*")) {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+func TestStoreGrantCheckRevoke(t *testing.T) {
+	s := NewStore(mapStorage{}, "satoken:")
+	user := UserSubject("42")
+
+	if err := s.Grant(user, "document", "9", "read"); err != nil {
+		t.Fatalf("Grant() error = %v", err)
+	}
+	if err := s.Grant(RoleSubject("editor"), "document", Wildcard, "read", "write"); err != nil {
+		t.Fatalf("Grant() error = %v", err)
+	}
+
+	if !s.Check([]string{user}, "document", "9", "read") {
+		t.Error("user 42 should read document 9")
+	}
+	if s.Check([]string{user}, "document", "9", "write") {
+		t.Error("user 42 should not write document 9")
+	}
+	if !s.Check([]string{UserSubject("7"), RoleSubject("editor")}, "document", "100", "write") {
+		t.Error("editor role should write any document")
+	}
+
+	grants, _ := s.ListBySubject(user)
+	if len(grants) != 1 || grants[0].ResourceID != "9" {
+		t.Errorf("ListBySubject() = %+v", grants)
+	}
+
+	if err := s.Revoke(user, "document", "9"); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if s.Check([]string{user}, "document", "9", "read") {
+		t.Error("revoked grant should not allow read")
+	}
+	if grants, _ := s.ListBySubject(user); len(grants) != 0 {
+		t.Errorf("ListBySubject() after revoke = %+v", grants)
+	}
+}
+
+func TestStoreEveryone(t *testing.T) {
+	s := NewStore(mapStorage{}, "")
+	_ = s.Grant(Wildcard, "page", "home", "read")
+
+	if !s.Check(nil, "page", "home", "read") {
+		t.Error("everyone should read page home")
+	}
+	grants, _ := s.ListByResource("page", "home")
+	if len(grants) != 1 || grants[0].Subject != Wildcard {
+		t.Errorf("ListByResource() = %+v", grants)
+	}
+}
+
+func TestStoreKeyEscaping(t *testing.T) {
+	storage := mapStorage{}
+	s := NewStore(storage, "satoken:")
+	user := UserSubject("42")
+
+	// "a:b"/"c" and "a"/"b:c" are distinct resources | "a:b"/"c" 与 "a"/"b:c" 是不同的资源
+	_ = s.Grant(user, "a:b", "c", "read")
+	if s.Check([]string{user}, "a", "b:c", "read") {
+		t.Error("a grant on a:b/c should not cover a/b:c")
+	}
+	// A literal "*" ID is escaped and matches only itself in listings | 字面量"*"ID会被转义
+	_ = s.Grant(user, "doc", "x*", "read")
+	if grants, _ := s.ListByResource("doc", "x"); len(grants) != 0 {
+		t.Errorf("ListByResource(doc, x) = %+v", grants)
+	}
+
+	grants, _ := s.ListBySubject(user)
+	if len(grants) != 2 || grants[0].ResourceType != "a:b" || grants[0].ResourceID != "c" || grants[1].ResourceID != "x*" {
+		t.Errorf("ListBySubject() = %+v", grants)
+	}
+}
+
+func TestStoreEntryPerAction(t *testing.T) {
+	storage := mapStorage{}
+	s := NewStore(storage, "")
+	other := NewStore(storage, "")
+	user := UserSubject("42")
+
+	// Writers on separate instances keep each other's actions | 不同实例上的写入互不覆盖
+	_ = s.Grant(user, "doc", "9", "read")
+	_ = other.Grant(user, "doc", "9", "write")
+	grants, _ := s.ListByResource("doc", "9")
+	if len(grants) != 1 || len(grants[0].Actions) != 2 {
+		t.Fatalf("ListByResource() = %+v", grants)
+	}
+
+	_ = other.Revoke(user, "doc", "9", "read")
+	if s.Check([]string{user}, "doc", "9", "read") || !s.Check([]string{user}, "doc", "9", "write") {
+		t.Error("Revoke() should drop only the read action")
+	}
+
+	// An empty resource ID never matches "*" grants | 空资源ID不会匹配"*"授权
+	_ = s.Grant(user, "doc", Wildcard, "read")
+	if s.Check([]string{user}, "doc", "", "read") {
+		t.Error("Check() with an empty resource ID should fail")
+	}
+
+	_ = s.RevokeResource("doc", "9")
+	_ = s.RevokeResource("doc", Wildcard)
+	if len(storage) != 0 {
+		t.Errorf("storage after RevokeResource() = %v", storage)
+	}
+}
@@ -8,6 +8,8 @@ type CookieOptions struct {
 	Value string
 	// MaxAge Cookie expiration time in seconds, 0 means delete cookie, -1 means session cookie | 过期时间（秒），0表示删除cookie，-1表示会话cookie
 	MaxAge int
+	// Session Session cookie without Max-Age, takes precedence over MaxAge | 不带Max-Age的会话cookie，优先于MaxAge
+	Session bool
 	// Path Cookie path | 路径
 	Path string
 	// Domain Cookie domain | 域名
@@ -20,6 +22,18 @@ type CookieOptions struct {
 	SameSite string
 }
 
+// HTTPMaxAge Max-Age in net/http terms: > 0 persistent, 0 session cookie, < 0 delete cookie |
+// net/http语义的Max-Age：> 0为持久cookie，0为会话cookie，< 0表示删除cookie
+func (o *CookieOptions) HTTPMaxAge() int {
+	switch {
+	case o.Session || o.MaxAge < 0:
+		return 0
+	case o.MaxAge == 0:
+		return -1
+	}
+	return o.MaxAge
+}
+
 // RequestContext defines request context interface for abstracting different web frameworks | 定义请求上下文接口，用于抽象不同Web框架的请求/响应
 type RequestContext interface {
 	// ============== Request Methods | 请求方法 ==============
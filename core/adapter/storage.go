@@ -20,7 +20,8 @@ type Storage interface {
 
 	// ============== Key Management | 键管理 ==============
 
-	// Keys gets all keys matching pattern (e.g., "user:*") | 获取匹配模式的所有键（如："user:*"）
+	// Keys gets all keys matching pattern (e.g., "user:*"), shared servers must iterate incrementally like Redis SCAN |
+	// 获取匹配模式的所有键（如："user:*"），共享服务端须像Redis SCAN那样增量遍历
 	Keys(pattern string) ([]string, error)
 
 	// Expire sets expiration time for key | 设置键的过期时间
@@ -37,3 +38,13 @@ type Storage interface {
 	// Ping checks if storage is accessible | 检查存储是否可访问
 	Ping() error
 }
+
+// AtomicStorage Optional Storage extension for records several instances update concurrently |
+// 可选的Storage扩展，用于多个实例并发更新的记录
+type AtomicStorage interface {
+	Storage
+
+	// CompareAndSwap sets key to newValue only if it currently holds oldValue, in one atomic step, and reports whether it did |
+	// 仅当键当前值为oldValue时以原子操作将其设为newValue，并返回是否已设置
+	CompareAndSwap(key string, oldValue, newValue string, expiration time.Duration) (bool, error)
+}
@@ -0,0 +1,260 @@
+package annotation
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"suwei.sa_token/core/context"
+	"suwei.sa_token/core/manager"
+)
+
+// Annotation Model
+// 注解模型
+//
+// An annotation combines checks that must all pass: login, disable, safe, role and
+// permission. Roles and permissions are matched with AND (default) or OR mode. Every
+// check except Ignore implies login. Integrations only adapt the result to their framework.
+// 注解组合多个必须全部通过的检查：登录、封禁、二级认证、角色和权限。角色和权限按AND（默认）
+// 或OR模式匹配。除Ignore外的每个检查都隐含登录检查。各框架集成只负责适配检查结果。
+//
+// Tag syntax | 标签语法:
+//   sa:"login"
+//   sa:"role=admin|manager,mode=or"
+//   sa:"permission=user:add|user:edit,permission_mode=and,disable,safe=pay"
+//   sa:"ignore"
+//
+// Struct-tag controller | 结构体标签控制器:
+//   type UserController struct {
+//       Info   gin.HandlerFunc `sa:"login"`
+//       Delete gin.HandlerFunc `sa:"role=admin,permission=user:delete,safe"`
+//   }
+
+// Tag constants | 标签常量
+const (
+	TagName = "sa" // Struct tag name | 结构体标签名
+
+	TagSaCheckLogin        = "sa_check_login"
+	TagSaCheckRole         = "sa_check_role"
+	TagSaCheckPermission   = "sa_check_permission"
+	TagSaCheckDisable      = "sa_check_disable"
+	TagSaCheckSafe         = "sa_check_safe"
+	TagSaIgnore            = "sa_ignore"
+	TagMode                = "mode"
+	TagRoleMode            = "role_mode"
+	TagPermissionMode      = "permission_mode"
+	TagValueSeparator      = "|"
+	TagAnnotationSeparator = ","
+	TagKeyValueSeparator   = "="
+	shortTagLogin          = "login"
+	shortTagRole           = "role"
+	shortTagPermission     = "permission"
+	shortTagDisable        = "disable"
+	shortTagSafe           = "safe"
+	shortTagIgnore         = "ignore"
+)
+
+// Mode Matching mode for roles and permissions | 角色和权限的匹配模式
+type Mode string
+
+const (
+	// ModeAnd All values are required | 需要全部满足
+	ModeAnd Mode = "and"
+	// ModeOr Any value is enough | 满足任一即可
+	ModeOr Mode = "or"
+)
+
+// Annotation Combined check description | 组合检查描述
+type Annotation struct {
+	CheckLogin      bool     `json:"checkLogin"`
+	CheckRole       []string `json:"checkRole"`
+	RoleMode        Mode     `json:"roleMode"`
+	CheckPermission []string `json:"checkPermission"`
+	PermissionMode  Mode     `json:"permissionMode"`
+	CheckDisable    bool     `json:"checkDisable"`
+	CheckSafe       bool     `json:"checkSafe"`
+	SafeService     string   `json:"safeService"` // Empty means manager.DefaultSafeService | 为空表示默认服务
+	Ignore          bool     `json:"ignore"`
+}
+
+// ParseTag Parses annotation tag | 解析注解标签
+func ParseTag(tag string) *Annotation {
+	ann := &Annotation{}
+
+	for _, part := range strings.Split(tag, TagAnnotationSeparator) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		key, value, _ := strings.Cut(part, TagKeyValueSeparator)
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		switch key {
+		case TagSaCheckLogin, shortTagLogin:
+			ann.CheckLogin = true
+		case TagSaCheckRole, shortTagRole:
+			ann.CheckRole = splitValues(value)
+		case TagSaCheckPermission, shortTagPermission:
+			ann.CheckPermission = splitValues(value)
+		case TagSaCheckDisable, shortTagDisable:
+			ann.CheckDisable = true
+		case TagSaCheckSafe, shortTagSafe:
+			ann.CheckSafe = true
+			ann.SafeService = value
+		case TagSaIgnore, shortTagIgnore:
+			ann.Ignore = true
+		case TagMode:
+			ann.RoleMode = Mode(strings.ToLower(value))
+			ann.PermissionMode = Mode(strings.ToLower(value))
+		case TagRoleMode:
+			ann.RoleMode = Mode(strings.ToLower(value))
+		case TagPermissionMode:
+			ann.PermissionMode = Mode(strings.ToLower(value))
+		}
+	}
+
+	return ann
+}
+
+// Validate Checks that modes are valid | 检查模式是否有效
+func (a *Annotation) Validate() bool {
+	return validMode(a.RoleMode) && validMode(a.PermissionMode)
+}
+
+// Check Runs all checks against the request | 对请求执行所有检查
+func (a *Annotation) Check(saCtx *context.SaTokenContext) error {
+	if a == nil || a.Ignore {
+		return nil
+	}
+
+	if err := saCtx.CheckLogin(); err != nil {
+		return err
+	}
+	loginID, err := saCtx.GetLoginID()
+	if err != nil {
+		return err
+	}
+	mgr := saCtx.GetManager()
+	tokenValue := saCtx.GetTokenValue()
+
+	if a.CheckDisable && mgr.IsDisable(loginID) {
+		return manager.ErrAccountDisabled
+	}
+
+	if a.CheckSafe {
+		if err := saCtx.CheckSafe(a.SafeService); err != nil {
+			return err
+		}
+	}
+
+	if len(a.CheckRole) > 0 {
+		ok := mgr.HasRoleByToken(tokenValue, a.CheckRole...)
+		if a.RoleMode == ModeOr {
+			ok = mgr.HasRoleOrByToken(tokenValue, a.CheckRole...)
+		}
+		if !ok {
+			return fmt.Errorf("%w: %s", manager.ErrRoleDenied, strings.Join(a.CheckRole, TagValueSeparator))
+		}
+	}
+
+	if len(a.CheckPermission) > 0 {
+		ok := mgr.HasPermissionByToken(tokenValue, a.CheckPermission...)
+		if a.PermissionMode == ModeOr {
+			ok = mgr.HasPermissionOrByToken(tokenValue, a.CheckPermission...)
+		}
+		if !ok {
+			return fmt.Errorf("%w: %s", manager.ErrPermissionDenied, strings.Join(a.CheckPermission, TagValueSeparator))
+		}
+	}
+
+	return nil
+}
+
+// IsIgnored Checks if any annotation ignores authentication | 检查是否有注解忽略认证
+func IsIgnored(annotations ...*Annotation) bool {
+	for _, ann := range annotations {
+		if ann != nil && ann.Ignore {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckAll Runs annotations in order, any Ignore skips all checks | 按顺序执行注解，任一Ignore跳过所有检查
+func CheckAll(saCtx *context.SaTokenContext, annotations ...*Annotation) error {
+	if IsIgnored(annotations...) {
+		return nil
+	}
+	if len(annotations) == 0 {
+		return saCtx.CheckLogin()
+	}
+	for _, ann := range annotations {
+		if err := ann.Check(saCtx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ============ Struct-tag Controllers | 结构体标签控制器 ============
+
+// BindStruct Wraps tagged handler fields of a controller pointer in place | 原地包装控制器指针中带标签的处理器字段
+// Fields whose type converts to H and carry a `sa` tag are replaced by wrap(handler, annotation) |
+// 类型可转换为H且带有 `sa` 标签的字段被替换为 wrap(handler, annotation)
+func BindStruct[H any](controller any, wrap func(handler H, ann *Annotation) H) error {
+	v := reflect.ValueOf(controller)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("controller must be a pointer to struct, got: %T", controller)
+	}
+	v = v.Elem()
+	t := v.Type()
+	handlerType := reflect.TypeOf((*H)(nil)).Elem()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup(TagName)
+		if !ok || !field.IsExported() {
+			continue
+		}
+		if !field.Type.ConvertibleTo(handlerType) || !handlerType.ConvertibleTo(field.Type) {
+			return fmt.Errorf("field %s: type %s is not a %s handler", field.Name, field.Type, handlerType)
+		}
+
+		fv := v.Field(i)
+		if fv.IsNil() {
+			return fmt.Errorf("field %s: handler is nil", field.Name)
+		}
+
+		ann := ParseTag(tag)
+		if !ann.Validate() {
+			return fmt.Errorf("field %s: invalid annotation %q", field.Name, tag)
+		}
+
+		handler := fv.Convert(handlerType).Interface().(H)
+		fv.Set(reflect.ValueOf(wrap(handler, ann)).Convert(field.Type))
+	}
+	return nil
+}
+
+// ============ Internal Methods | 内部方法 ============
+
+// splitValues Splits "a|b" values | 拆分 "a|b" 形式的值
+func splitValues(value string) []string {
+	if value == "" {
+		return nil
+	}
+	parts := strings.Split(value, TagValueSeparator)
+	values := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			values = append(values, p)
+		}
+	}
+	return values
+}
+
+// validMode Checks mode value | 检查模式值
+func validMode(mode Mode) bool {
+	return mode == "" || mode == ModeAnd || mode == ModeOr
+}
@@ -0,0 +1,85 @@
+package annotation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTag(t *testing.T) {
+	ann := ParseTag("login, role=admin|manager, permission=user:add|user:edit, mode=or, permission_mode=and, disable, safe=pay")
+
+	want := &Annotation{
+		CheckLogin:      true,
+		CheckRole:       []string{"admin", "manager"},
+		RoleMode:        ModeOr,
+		CheckPermission: []string{"user:add", "user:edit"},
+		PermissionMode:  ModeAnd,
+		CheckDisable:    true,
+		CheckSafe:       true,
+		SafeService:     "pay",
+	}
+	if !reflect.DeepEqual(ann, want) {
+		t.Errorf("ParseTag() = %+v, want %+v", ann, want)
+	}
+
+	legacy := ParseTag("sa_check_role=admin,sa_ignore")
+	if !legacy.Ignore || !reflect.DeepEqual(legacy.CheckRole, []string{"admin"}) {
+		t.Errorf("legacy tag parsed as %+v", legacy)
+	}
+
+	if ParseTag("role=admin,mode=xor").Validate() {
+		t.Error("invalid mode should not validate")
+	}
+}
+
+func TestBindStruct(t *testing.T) {
+	type handler func(string) string
+	type controller struct {
+		Info   handler `sa:"login"`
+		Delete handler `sa:"role=admin"`
+		Public handler
+	}
+
+	ctrl := &controller{
+		Info:   func(s string) string { return "info:" + s },
+		Delete: func(s string) string { return "delete:" + s },
+		Public: func(s string) string { return "public:" + s },
+	}
+
+	var seen []string
+	err := BindStruct(ctrl, func(h func(string) string, ann *Annotation) func(string) string {
+		return func(s string) string {
+			seen = append(seen, s)
+			return h(s) + "|" + string(ann.RoleMode) + joinRoles(ann)
+		}
+	})
+	if err != nil {
+		t.Fatalf("BindStruct() error = %v", err)
+	}
+
+	if got := ctrl.Info("a"); got != "info:a|" {
+		t.Errorf("Info = %q", got)
+	}
+	if got := ctrl.Delete("b"); got != "delete:b|admin" {
+		t.Errorf("Delete = %q", got)
+	}
+	if got := ctrl.Public("c"); got != "public:c" {
+		t.Errorf("untagged field should not be wrapped, got %q", got)
+	}
+	if !reflect.DeepEqual(seen, []string{"a", "b"}) {
+		t.Errorf("wrapped calls = %v", seen)
+	}
+
+	if err := BindStruct(*ctrl, func(h func(string) string, _ *Annotation) func(string) string { return h }); err == nil {
+		t.Error("non-pointer controller should fail")
+	}
+}
+
+// joinRoles joins roles for assertions | 拼接角色用于断言
+func joinRoles(ann *Annotation) string {
+	result := ""
+	for _, r := range ann.CheckRole {
+		result += r
+	}
+	return result
+}
@@ -8,6 +8,7 @@ import (
 	"suwei.sa_token/core/adapter"
 	"suwei.sa_token/core/banner"
 	"suwei.sa_token/core/config"
+	"suwei.sa_token/core/jwk"
 	"suwei.sa_token/core/manager"
 )
 
@@ -17,21 +18,38 @@ type Builder struct {
 	tokenName              string
 	timeout                int64
 	activeTimeout          int64
+	refreshTokenTimeout    int64
 	isConcurrent           bool
 	isShare                bool
 	maxLoginCount          int
 	tokenStyle             config.TokenStyle
 	autoRenew              bool
 	jwtSecretKey           string
+	jwtKeySet              *jwk.KeySet
+	jwtMode                config.JwtMode
 	isLog                  bool
 	isPrintBanner          bool
 	isReadBody             bool
 	isReadHeader           bool
 	isReadCookie           bool
+	isReadQuery            bool
+	tokenSources           []config.TokenSource
+	tokenPrefix            string
+	authHeader             string
+	isWriteHeader          bool
+	isRememberMe           bool
+	csrfHeaderName         string
+	csrfFieldName          string
+	csrfCookieName         string
 	dataRefreshPeriod      int64
 	tokenSessionCheckLogin bool
 	keyPrefix              string
+	tokenHashPepper        string
+	tokenSignKeySet        *jwk.KeySet
+	permissionIgnoreCase   bool
 	cookieConfig           *config.CookieConfig
+	tokenBinding           *config.TokenBindingConfig
+	jwtRevocation          *config.JwtRevocationConfig
 }
 
 // NewBuilder creates a new builder with default configuration | 创建新的构建器（使用默认配置）
@@ -40,16 +58,26 @@ func NewBuilder() *Builder {
 		tokenName:              config.DefaultTokenName,
 		timeout:                config.DefaultTimeout,
 		activeTimeout:          config.NoLimit,
+		refreshTokenTimeout:    config.DefaultRefreshTimeout,
 		isConcurrent:           true,
 		isShare:                true,
 		maxLoginCount:          config.DefaultMaxLoginCount,
 		tokenStyle:             config.TokenStyleUUID,
+		jwtMode:                config.JwtModeSimple,
 		autoRenew:              true,
 		isLog:                  false,
 		isPrintBanner:          true,
 		isReadBody:             false,
 		isReadHeader:           true,
 		isReadCookie:           false,
+		isReadQuery:            false,
+		tokenPrefix:            config.DefaultTokenPrefix,
+		authHeader:             config.DefaultAuthHeader,
+		isWriteHeader:          false,
+		isRememberMe:           true,
+		csrfHeaderName:         config.DefaultCsrfHeader,
+		csrfFieldName:          config.DefaultCsrfField,
+		csrfCookieName:         config.DefaultCsrfCookie,
 		dataRefreshPeriod:      config.NoLimit,
 		tokenSessionCheckLogin: true,
 		keyPrefix:              "satoken:",
@@ -94,6 +122,18 @@ func (b *Builder) ActiveTimeout(seconds int64) *Builder {
 	return b
 }
 
+// RefreshTokenTimeout sets refresh token timeout in seconds | 设置刷新令牌超时时间（秒）
+func (b *Builder) RefreshTokenTimeout(seconds int64) *Builder {
+	b.refreshTokenTimeout = seconds
+	return b
+}
+
+// RefreshTokenTimeoutDuration sets refresh token timeout with duration | 设置刷新令牌超时时间（时间段）
+func (b *Builder) RefreshTokenTimeoutDuration(d time.Duration) *Builder {
+	b.refreshTokenTimeout = int64(d.Seconds())
+	return b
+}
+
 // IsConcurrent sets whether to allow concurrent login | 设置是否允许并发登录
 func (b *Builder) IsConcurrent(concurrent bool) *Builder {
 	b.isConcurrent = concurrent
@@ -130,6 +170,18 @@ func (b *Builder) JwtSecretKey(key string) *Builder {
 	return b
 }
 
+// JwtKeySet sets JWT signing key set, takes precedence over JwtSecretKey | 设置JWT签名密钥集，优先于JwtSecretKey
+func (b *Builder) JwtKeySet(keySet *jwk.KeySet) *Builder {
+	b.jwtKeySet = keySet
+	return b
+}
+
+// JwtMode sets JWT storage mode: simple, mixin or stateless | 设置JWT存储模式：simple、mixin或stateless
+func (b *Builder) JwtMode(mode config.JwtMode) *Builder {
+	b.jwtMode = mode
+	return b
+}
+
 // IsLog sets whether to enable logging | 设置是否输出日志
 func (b *Builder) IsLog(isLog bool) *Builder {
 	b.isLog = isLog
@@ -160,6 +212,60 @@ func (b *Builder) IsReadCookie(isRead bool) *Builder {
 	return b
 }
 
+// IsReadQuery sets whether to read token from query string | 设置是否从查询参数读取Token
+func (b *Builder) IsReadQuery(isRead bool) *Builder {
+	b.isReadQuery = isRead
+	return b
+}
+
+// TokenSources sets the order in which token sources are tried | 设置尝试读取Token来源的顺序
+func (b *Builder) TokenSources(sources ...config.TokenSource) *Builder {
+	b.tokenSources = sources
+	return b
+}
+
+// TokenPrefix sets the prefix stripped from header values, e.g. "Bearer" | 设置从请求头值中去除的前缀，例如 "Bearer"
+func (b *Builder) TokenPrefix(prefix string) *Builder {
+	b.tokenPrefix = prefix
+	return b
+}
+
+// AuthHeader sets the extra header carrying the token, empty disables | 设置额外携带Token的请求头，为空则不读取
+func (b *Builder) AuthHeader(header string) *Builder {
+	b.authHeader = header
+	return b
+}
+
+// IsWriteHeader sets whether to write issued tokens to the response header | 设置是否将签发的Token写入响应头
+func (b *Builder) IsWriteHeader(isWrite bool) *Builder {
+	b.isWriteHeader = isWrite
+	return b
+}
+
+// IsRememberMe sets whether logins default to a persistent cookie | 设置登录是否默认使用持久Cookie
+func (b *Builder) IsRememberMe(isRememberMe bool) *Builder {
+	b.isRememberMe = isRememberMe
+	return b
+}
+
+// CsrfHeaderName sets the header carrying the CSRF token | 设置携带CSRF令牌的请求头
+func (b *Builder) CsrfHeaderName(name string) *Builder {
+	b.csrfHeaderName = name
+	return b
+}
+
+// CsrfFieldName sets the form field carrying the CSRF token | 设置携带CSRF令牌的表单字段
+func (b *Builder) CsrfFieldName(name string) *Builder {
+	b.csrfFieldName = name
+	return b
+}
+
+// CsrfCookieName sets the cookie the CSRF token is issued in, empty disables | 设置下发CSRF令牌的Cookie，为空则不下发
+func (b *Builder) CsrfCookieName(name string) *Builder {
+	b.csrfCookieName = name
+	return b
+}
+
 // DataRefreshPeriod sets data refresh period | 设置数据刷新周期
 func (b *Builder) DataRefreshPeriod(seconds int64) *Builder {
 	b.dataRefreshPeriod = seconds
@@ -226,6 +332,18 @@ func (b *Builder) CookieMaxAge(maxAge int) *Builder {
 	return b
 }
 
+// TokenBinding sets the client attributes tokens are bound to at login | 设置登录时Token绑定的客户端属性
+func (b *Builder) TokenBinding(binding *config.TokenBindingConfig) *Builder {
+	b.tokenBinding = binding
+	return b
+}
+
+// JwtRevocation enables the jti revocation list checked on every JWT validation | 启用每次JWT校验时检查的jti撤销列表
+func (b *Builder) JwtRevocation(revocation *config.JwtRevocationConfig) *Builder {
+	b.jwtRevocation = revocation
+	return b
+}
+
 // CookieConfig sets complete cookie configuration | 设置完整的Cookie配置
 func (b *Builder) CookieConfig(cfg *config.CookieConfig) *Builder {
 	b.cookieConfig = cfg
@@ -246,6 +364,24 @@ func (b *Builder) KeyPrefix(prefix string) *Builder {
 	return b
 }
 
+// TokenHashPepper sets the pepper for storing hashed tokens instead of raw values | 设置存储Token哈希而非原始值的密钥
+func (b *Builder) TokenHashPepper(pepper string) *Builder {
+	b.tokenHashPepper = pepper
+	return b
+}
+
+// TokenSignKeySet sets the HMAC keys tagging opaque tokens as "random.tag" | 设置为不透明Token添加标签（"随机值.标签"）的HMAC密钥集
+func (b *Builder) TokenSignKeySet(keySet *jwk.KeySet) *Builder {
+	b.tokenSignKeySet = keySet
+	return b
+}
+
+// PermissionIgnoreCase sets whether to match permissions case-insensitively | 设置权限匹配是否忽略大小写
+func (b *Builder) PermissionIgnoreCase(ignoreCase bool) *Builder {
+	b.permissionIgnoreCase = ignoreCase
+	return b
+}
+
 // NeverExpire sets token to never expire | 设置Token永不过期
 func (b *Builder) NeverExpire() *Builder {
 	b.timeout = config.NoLimit
@@ -264,53 +400,67 @@ func (b *Builder) UnlimitedLogin() *Builder {
 	return b
 }
 
-// Validate validates the builder configuration | 验证构建器配置
+// Validate validates the builder configuration with the same rules as config.Config.Validate | 按config.Config.Validate的规则验证构建器配置
 func (b *Builder) Validate() error {
+	return b.validate(b.config())
+}
+
+// validate checks the storage and the assembled configuration | 校验存储和组装后的配置
+func (b *Builder) validate(cfg *config.Config) error {
 	if b.storage == nil {
 		return fmt.Errorf("storage is required, please call Storage() method")
 	}
-
-	if b.tokenName == "" {
-		return fmt.Errorf("tokenName cannot be empty")
-	}
-
-	if b.tokenStyle == config.TokenStyleJWT && b.jwtSecretKey == "" {
-		return fmt.Errorf("jwtSecretKey is required when TokenStyle is JWT")
-	}
-
-	if !b.isReadHeader && !b.isReadCookie && !b.isReadBody {
-		return fmt.Errorf("at least one of IsReadHeader, IsReadCookie, or IsReadBody must be true")
-	}
-
-	return nil
+	return cfg.Validate()
 }
 
-// Build builds Manager and prints startup banner | 构建Manager并打印启动Banner
-func (b *Builder) Build() *manager.Manager {
-	// Validate configuration | 验证配置
-	if err := b.Validate(); err != nil {
-		panic(fmt.Sprintf("invalid configuration: %v", err))
-	}
-
-	cfg := &config.Config{
+// config assembles the configuration of the builder | 组装构建器的配置
+func (b *Builder) config() *config.Config {
+	return &config.Config{
 		TokenName:              b.tokenName,
 		Timeout:                b.timeout,
 		ActiveTimeout:          b.activeTimeout,
+		RefreshTokenTimeout:    b.refreshTokenTimeout,
 		IsConcurrent:           b.isConcurrent,
 		IsShare:                b.isShare,
 		MaxLoginCount:          b.maxLoginCount,
 		IsReadBody:             b.isReadBody,
 		IsReadHeader:           b.isReadHeader,
 		IsReadCookie:           b.isReadCookie,
+		IsReadQuery:            b.isReadQuery,
+		TokenSources:           b.tokenSources,
+		TokenPrefix:            b.tokenPrefix,
+		AuthHeader:             b.authHeader,
+		IsWriteHeader:          b.isWriteHeader,
+		IsRememberMe:           b.isRememberMe,
+		CsrfHeaderName:         b.csrfHeaderName,
+		CsrfFieldName:          b.csrfFieldName,
+		CsrfCookieName:         b.csrfCookieName,
 		TokenStyle:             b.tokenStyle,
 		DataRefreshPeriod:      b.dataRefreshPeriod,
 		TokenSessionCheckLogin: b.tokenSessionCheckLogin,
 		AutoRenew:              b.autoRenew,
 		JwtSecretKey:           b.jwtSecretKey,
+		JwtKeySet:              b.jwtKeySet,
+		JwtMode:                b.jwtMode,
 		IsLog:                  b.isLog,
 		IsPrintBanner:          b.isPrintBanner,
 		KeyPrefix:              b.keyPrefix,
+		PermissionIgnoreCase:   b.permissionIgnoreCase,
+		TokenHashPepper:        b.tokenHashPepper,
+		TokenSignKeySet:        b.tokenSignKeySet,
 		CookieConfig:           b.cookieConfig,
+		TokenBinding:           b.tokenBinding,
+		JwtRevocation:          b.jwtRevocation,
+	}
+}
+
+// Build builds Manager and prints startup banner | 构建Manager并打印启动Banner
+func (b *Builder) Build() *manager.Manager {
+	cfg := b.config()
+
+	// Validate configuration | 验证配置
+	if err := b.validate(cfg); err != nil {
+		panic(fmt.Sprintf("invalid configuration: %v", err))
 	}
 
 	// Print startup banner with full configuration | 打印启动Banner和完整配置
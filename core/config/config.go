@@ -1,6 +1,12 @@
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"suwei.sa_token/core/jwk"
+)
 
 // TokenStyle Token generation style | Token生成风格
 type TokenStyle string
@@ -24,8 +30,38 @@ const (
 	TokenStyleTimestamp TokenStyle = "timestamp"
 	// TokenStyleTik Short ID style (like TikTok) | Tik风格短ID（类似抖音）
 	TokenStyleTik TokenStyle = "tik"
+	// TokenStyleULID Lexicographically sortable ULID | 可按字典序排序的ULID
+	TokenStyleULID TokenStyle = "ulid"
+	// TokenStyleSnowflake Time-ordered snowflake ID, predictable | 按时间排序的雪花ID，可被预测
+	TokenStyleSnowflake TokenStyle = "snowflake"
+	// TokenStylePasetoV4Public PASETO v4.public, Ed25519-signed claims | PASETO v4.public，Ed25519签名的声明
+	TokenStylePasetoV4Public TokenStyle = "paseto-v4-public"
+	// TokenStylePasetoV4Local PASETO v4.local, encrypted claims | PASETO v4.local，加密的声明
+	TokenStylePasetoV4Local TokenStyle = "paseto-v4-local"
+)
+
+// BindingPolicy What happens when a request comes from another client than the login | 请求来自与登录时不同的客户端时的处理方式
+type BindingPolicy string
+
+const (
+	// BindingPolicyReject Reject the request | 拒绝请求
+	BindingPolicyReject BindingPolicy = "reject"
+	// BindingPolicySafe Allow only while second-level auth is open | 仅在二级认证开启期间允许
+	BindingPolicySafe BindingPolicy = "safe"
+	// BindingPolicyEvent Allow and only emit an event | 允许请求，仅触发事件
+	BindingPolicyEvent BindingPolicy = "event"
 )
 
+// IsValid checks if the BindingPolicy is valid | 检查BindingPolicy是否有效
+func (bp BindingPolicy) IsValid() bool {
+	switch bp {
+	case BindingPolicyReject, BindingPolicySafe, BindingPolicyEvent:
+		return true
+	default:
+		return false
+	}
+}
+
 // SameSiteMode Cookie SameSite attribute values | Cookie的SameSite属性值
 type SameSiteMode string
 
@@ -38,27 +74,108 @@ const (
 	SameSiteNone SameSiteMode = "None"
 )
 
+// TokenSource Where a token is read from | Token读取来源
+type TokenSource string
+
+const (
+	// TokenSourceHeader TokenName header and AuthHeader | TokenName请求头和AuthHeader
+	TokenSourceHeader TokenSource = "header"
+	// TokenSourceCookie Cookie named TokenName | 名为TokenName的Cookie
+	TokenSourceCookie TokenSource = "cookie"
+	// TokenSourceBody JSON or form body field named TokenName | 名为TokenName的JSON或表单字段
+	TokenSourceBody TokenSource = "body"
+	// TokenSourceQuery Query parameter named TokenName | 名为TokenName的查询参数
+	TokenSourceQuery TokenSource = "query"
+)
+
+// DefaultTokenSources Default token source order | 默认Token来源顺序
+var DefaultTokenSources = []TokenSource{TokenSourceHeader, TokenSourceCookie, TokenSourceBody, TokenSourceQuery}
+
+// IsValid checks if the TokenSource is valid | 检查TokenSource是否有效
+func (ts TokenSource) IsValid() bool {
+	switch ts {
+	case TokenSourceHeader, TokenSourceCookie, TokenSourceBody, TokenSourceQuery:
+		return true
+	default:
+		return false
+	}
+}
+
+// JwtMode How JWT tokens relate to storage (only effective when TokenStyle=JWT) | JWT Token与存储的关系（仅在TokenStyle=JWT时生效）
+type JwtMode string
+
+const (
+	// JwtModeSimple JWT is only the token value, storage stays authoritative | JWT仅作为Token值，存储仍是权威来源
+	JwtModeSimple JwtMode = "simple"
+	// JwtModeMixin Login state, permissions and roles live in claims, storage only keeps logout/kickout lists | 登录状态、权限和角色保存在声明中，存储只保留登出/踢下线列表
+	JwtModeMixin JwtMode = "mixin"
+	// JwtModeStateless Claims only, storage is never used for tokens | 只使用声明，Token不使用存储
+	JwtModeStateless JwtMode = "stateless"
+)
+
+// IsValid checks if the JwtMode is valid | 检查JwtMode是否有效
+func (jm JwtMode) IsValid() bool {
+	switch jm {
+	case JwtModeSimple, JwtModeMixin, JwtModeStateless:
+		return true
+	default:
+		return false
+	}
+}
+
 // Default configuration constants | 默认配置常量
 const (
-	DefaultTokenName     = "satoken"
-	DefaultTimeout       = 2592000 // 30 days in seconds | 30天（秒）
-	DefaultMaxLoginCount = 12      // Maximum concurrent logins | 最大并发登录数
-	DefaultCookiePath    = "/"
-	NoLimit              = -1 // No limit flag | 不限制标志
+	DefaultTokenName      = "satoken"
+	DefaultTokenPrefix    = "Bearer"
+	DefaultAuthHeader     = "Authorization"
+	DefaultCsrfHeader     = "X-CSRF-Token"
+	DefaultCsrfField      = "_csrf"
+	DefaultCsrfCookie     = "XSRF-TOKEN"
+	DefaultTimeout        = 2592000 // 30 days in seconds | 30天（秒）
+	DefaultRefreshTimeout = 2592000 // Refresh token lifetime, 30 days in seconds | 刷新令牌有效期，30天（秒）
+	DefaultMaxLoginCount  = 12      // Maximum concurrent logins | 最大并发登录数
+	DefaultCookiePath     = "/"
+	DefaultBindingSafe    = "binding"
+	NoLimit               = -1 // No limit flag | 不限制标志
+	MinTokenPepperLength  = 16 // Minimum TokenHashPepper length in bytes | TokenHashPepper的最小长度（字节）
+
+	DefaultRevocationSync = 10     // Seconds between revocation list syncs | 撤销列表同步间隔（秒）
+	DefaultRevocationSize = 100000 // Expected number of revoked tokens | 预期的已撤销Token数量
 )
 
-// IsValid checks if the TokenStyle is valid | 检查TokenStyle是否有效
+// registeredStyles Custom token styles with a registered generator | 已注册生成器的自定义Token风格
+var registeredStyles sync.Map
+
+// RegisterTokenStyle Marks a custom style as valid, called by token.RegisterGenerator | 将自定义风格标记为有效，由 token.RegisterGenerator 调用
+func RegisterTokenStyle(style TokenStyle) {
+	registeredStyles.Store(style, struct{}{})
+}
+
+// IsValid checks if the TokenStyle is built in or registered | 检查TokenStyle是内置的或已注册的
 func (ts TokenStyle) IsValid() bool {
 	switch ts {
 	case TokenStyleUUID, TokenStyleSimple, TokenStyleRandom32,
 		TokenStyleRandom64, TokenStyleRandom128, TokenStyleJWT,
-		TokenStyleHash, TokenStyleTimestamp, TokenStyleTik:
+		TokenStyleHash, TokenStyleTimestamp, TokenStyleTik,
+		TokenStyleULID, TokenStyleSnowflake,
+		TokenStylePasetoV4Public, TokenStylePasetoV4Local:
 		return true
 	default:
-		return false
+		_, ok := registeredStyles.Load(ts)
+		return ok
 	}
 }
 
+// IsClaims checks if tokens of the style carry signed claims (JWT, PASETO) | 检查该风格的Token是否携带签名声明（JWT、PASETO）
+func (ts TokenStyle) IsClaims() bool {
+	return ts == TokenStyleJWT || ts.IsPaseto()
+}
+
+// IsPaseto checks if the style is a PASETO version | 检查该风格是否为PASETO
+func (ts TokenStyle) IsPaseto() bool {
+	return ts == TokenStylePasetoV4Public || ts == TokenStylePasetoV4Local
+}
+
 // Config Sa-Token configuration | Sa-Token配置
 type Config struct {
 	// TokenName Token name (also used as Cookie name) | Token名称（同时也是Cookie名称）
@@ -70,6 +187,9 @@ type Config struct {
 	// ActiveTimeout Token minimum activity frequency in seconds. If Token is not accessed for this time, it will be frozen. -1 means no limit | Token最低活跃频率（单位：秒），如果Token超过此时间没有访问，则会被冻结。-1代表不限制，永不冻结
 	ActiveTimeout int64
 
+	// RefreshTokenTimeout Refresh token expiration time in seconds, -1 for never expire, 0 uses DefaultRefreshTimeout | 刷新令牌超时时间（单位：秒，-1代表永不过期，0使用DefaultRefreshTimeout）
+	RefreshTokenTimeout int64
+
 	// IsConcurrent Allow concurrent login for the same account (true=allow concurrent login, false=new login kicks out old login) | 是否允许同一账号并发登录（为true时允许一起登录，为false时新登录挤掉旧登录）
 	IsConcurrent bool
 
@@ -88,6 +208,34 @@ type Config struct {
 	// IsReadCookie Try to read Token from Cookie (default: false) | 是否尝试从Cookie里读取Token（默认：false）
 	IsReadCookie bool
 
+	// IsReadQuery Try to read Token from query string (default: false, URLs leak into logs and Referer) | 是否尝试从查询参数里读取Token（默认：false，URL会泄露到日志和Referer中）
+	IsReadQuery bool
+
+	// TokenSources Order in which sources are tried, each still gated by its IsReadXxx flag (default: header, cookie, body, query) | 尝试读取来源的顺序，各来源仍受对应IsReadXxx开关控制（默认：header、cookie、body、query）
+	TokenSources []TokenSource
+
+	// TokenPrefix Prefix stripped from header values, e.g. "Bearer" in "Bearer <token>", empty disables (default: "Bearer") | 从请求头值中去除的前缀，例如 "Bearer <token>" 中的 "Bearer"，为空则不处理（默认："Bearer"）
+	TokenPrefix string
+
+	// AuthHeader Extra header read besides TokenName, empty disables (default: "Authorization") | 除TokenName外额外读取的请求头，为空则不读取（默认："Authorization"）
+	AuthHeader string
+
+	// IsWriteHeader Write newly issued tokens to the TokenName response header (default: false) | 是否将新签发的Token写入名为TokenName的响应头（默认：false）
+	IsWriteHeader bool
+
+	// IsRememberMe Persistent ("remember me") token cookie for logins that do not choose, false gives a session cookie (default: true) |
+	// 未指定的登录使用持久（"记住我"）Token Cookie，为false时使用会话Cookie（默认：true）
+	IsRememberMe bool
+
+	// CsrfHeaderName Header carrying the CSRF token on unsafe cookie requests (default: "X-CSRF-Token") | 通过Cookie认证的非安全请求携带CSRF令牌的请求头（默认："X-CSRF-Token"）
+	CsrfHeaderName string
+
+	// CsrfFieldName Form field carrying the CSRF token when the header is absent (default: "_csrf") | 请求头缺失时携带CSRF令牌的表单字段（默认："_csrf"）
+	CsrfFieldName string
+
+	// CsrfCookieName Script-readable cookie the CSRF token is issued in on cookie logins, empty disables (default: "XSRF-TOKEN") | Cookie登录时下发CSRF令牌的可被脚本读取的Cookie，为空则不下发（默认："XSRF-TOKEN"）
+	CsrfCookieName string
+
 	// TokenStyle Token generation style | Token风格
 	TokenStyle TokenStyle
 
@@ -103,6 +251,12 @@ type Config struct {
 	// JwtSecretKey JWT secret key (only effective when TokenStyle=JWT) | JWT密钥（只有TokenStyle=JWT时，此配置才生效）
 	JwtSecretKey string
 
+	// JwtKeySet Keys for RS256/ES256/EdDSA/HS256 signing with kid headers, takes precedence over JwtSecretKey | 带kid头的RS256/ES256/EdDSA/HS256签名密钥集，优先于JwtSecretKey
+	JwtKeySet *jwk.KeySet
+
+	// JwtMode JWT storage mode: simple, mixin or stateless (default: simple, only effective when TokenStyle=JWT) | JWT存储模式：simple、mixin或stateless（默认：simple，只有TokenStyle=JWT时才生效）
+	JwtMode JwtMode
+
 	// IsLog Enable operation logging | 是否输出操作日志
 	IsLog bool
 
@@ -113,8 +267,71 @@ type Config struct {
 	// Set to empty "" to be compatible with Java sa-token default behavior | 设置为空""以兼容Java sa-token默认行为
 	KeyPrefix string
 
+	// TokenHashPepper Server secret for storing HMAC-SHA256 hashes of tokens instead of raw values, empty stores raw tokens |
+	// 服务端密钥，设置后存储Token的HMAC-SHA256哈希而非原始值，为空时存储原始Token
+	TokenHashPepper string
+
+	// TokenSignKeySet HMAC keys tagging non-JWT tokens as "random.tag" so forged tokens are rejected without storage, nil disables |
+	// 为非JWT Token添加标签（"随机值.标签"）的HMAC密钥集，伪造的Token无需访问存储即可被拒绝，为nil时不启用
+	TokenSignKeySet *jwk.KeySet
+
+	// PermissionIgnoreCase Match permissions case-insensitively (default: false) | 权限匹配是否忽略大小写（默认：false）
+	PermissionIgnoreCase bool
+
 	// CookieConfig Cookie configuration | Cookie配置
 	CookieConfig *CookieConfig
+
+	// TokenBinding Binds tokens to client attributes at login, nil disables | 登录时将Token绑定到客户端属性，为nil时不启用
+	TokenBinding *TokenBindingConfig
+
+	// JwtRevocation Revocation list by jti consulted on every JWT check, nil disables | 每次JWT校验时查询的按jti撤销列表，为nil时不启用
+	JwtRevocation *JwtRevocationConfig
+}
+
+// TokenBindingConfig Client attributes a token is bound to at login | 登录时Token绑定的客户端属性
+type TokenBindingConfig struct {
+	// IPv4Prefix Bits of the client IPv4 subnet to bind, e.g. 24, 0 skips IPv4 | 绑定的客户端IPv4子网位数，例如24，为0时不绑定IPv4
+	IPv4Prefix int
+
+	// IPv6Prefix Bits of the client IPv6 subnet to bind, e.g. 64, 0 skips IPv6 | 绑定的客户端IPv6子网位数，例如64，为0时不绑定IPv6
+	IPv6Prefix int
+
+	// UserAgent Bind a hash of the User-Agent header | 绑定User-Agent请求头的哈希
+	UserAgent bool
+
+	// DeviceIDHeader Header carrying a client-provided device ID, empty skips it | 携带客户端设备ID的请求头，为空时不绑定
+	DeviceIDHeader string
+
+	// Policy Action on mismatch (default: reject) | 不匹配时的处理方式（默认：reject）
+	Policy BindingPolicy
+
+	// SafeService Second-level auth service checked by BindingPolicySafe (default: "binding") | BindingPolicySafe检查的二级认证服务（默认："binding"）
+	SafeService string
+}
+
+// JwtRevocationConfig Revocation list of JWT and PASETO tokens by jti | 按jti撤销JWT和PASETO Token的列表
+type JwtRevocationConfig struct {
+	// SyncInterval Seconds before the local filter is reloaded from storage (default: 10) | 本地过滤器从存储重新加载前的秒数（默认：10）
+	SyncInterval int64
+
+	// Capacity Expected number of revoked tokens, sizes the local filter (default: 100000) | 预期的已撤销Token数量，决定本地过滤器大小（默认：100000）
+	Capacity int
+}
+
+// GetSyncInterval Gets the sync interval, DefaultRevocationSync if not set | 获取同步间隔，未设置时返回DefaultRevocationSync
+func (r *JwtRevocationConfig) GetSyncInterval() time.Duration {
+	if r.SyncInterval > 0 {
+		return time.Duration(r.SyncInterval) * time.Second
+	}
+	return DefaultRevocationSync * time.Second
+}
+
+// GetCapacity Gets the expected number of revoked tokens, DefaultRevocationSize if not set | 获取预期的已撤销Token数量，未设置时返回DefaultRevocationSize
+func (r *JwtRevocationConfig) GetCapacity() int {
+	if r.Capacity > 0 {
+		return r.Capacity
+	}
+	return DefaultRevocationSize
 }
 
 // CookieConfig Cookie configuration | Cookie配置
@@ -144,20 +361,31 @@ func DefaultConfig() *Config {
 		TokenName:              DefaultTokenName,
 		Timeout:                DefaultTimeout,
 		ActiveTimeout:          NoLimit,
+		RefreshTokenTimeout:    DefaultRefreshTimeout,
 		IsConcurrent:           true,
 		IsShare:                true,
 		MaxLoginCount:          DefaultMaxLoginCount,
 		IsReadBody:             false,
 		IsReadHeader:           true,
 		IsReadCookie:           false,
+		IsReadQuery:            false,
+		TokenPrefix:            DefaultTokenPrefix,
+		AuthHeader:             DefaultAuthHeader,
+		IsWriteHeader:          false,
+		IsRememberMe:           true,
+		CsrfHeaderName:         DefaultCsrfHeader,
+		CsrfFieldName:          DefaultCsrfField,
+		CsrfCookieName:         DefaultCsrfCookie,
 		TokenStyle:             TokenStyleUUID,
 		DataRefreshPeriod:      NoLimit,
 		TokenSessionCheckLogin: true,
 		AutoRenew:              true,
 		JwtSecretKey:           "",
+		JwtMode:                JwtModeSimple,
 		IsLog:                  false,
 		IsPrintBanner:          true,
 		KeyPrefix:              "satoken:",
+		PermissionIgnoreCase:   false,
 		CookieConfig: &CookieConfig{
 			Domain:   "",
 			Path:     DefaultCookiePath,
@@ -181,9 +409,25 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid TokenStyle: %s", c.TokenStyle)
 	}
 
-	// Check JWT secret key when using JWT style
-	if c.TokenStyle == TokenStyleJWT && c.JwtSecretKey == "" {
-		return fmt.Errorf("JwtSecretKey is required when TokenStyle is JWT")
+	// Check JWT keys when using JWT style, there is no default secret
+	if c.TokenStyle == TokenStyleJWT && !c.HasJwtKey() {
+		return fmt.Errorf("JwtSecretKey or JwtKeySet is required when TokenStyle is JWT")
+	}
+	if c.TokenStyle.IsPaseto() && (c.JwtKeySet == nil || c.JwtKeySet.Len() == 0) {
+		return fmt.Errorf("JwtKeySet is required when TokenStyle is %s", c.TokenStyle)
+	}
+
+	// Check JwtMode, claim modes only make sense for JWT tokens
+	if c.JwtMode != "" && !c.JwtMode.IsValid() {
+		return fmt.Errorf("invalid JwtMode: %s", c.JwtMode)
+	}
+	if c.GetJwtMode() != JwtModeSimple && !c.TokenStyle.IsClaims() {
+		return fmt.Errorf("JwtMode %s requires TokenStyle jwt or paseto", c.JwtMode)
+	}
+
+	// Check the CSRF secret, stateless cookie logins derive CSRF tokens with a symmetric secret
+	if c.IsReadCookie && c.GetJwtMode() == JwtModeStateless && c.JwtSecretKey == "" && c.TokenHashPepper == "" {
+		return fmt.Errorf("JwtSecretKey or TokenHashPepper is required for CSRF tokens when IsReadCookie is set in JwtMode stateless")
 	}
 
 	// Check Timeout
@@ -196,14 +440,75 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("ActiveTimeout must be >= -1, got: %d", c.ActiveTimeout)
 	}
 
+	// Check RefreshTokenTimeout
+	if c.RefreshTokenTimeout < NoLimit {
+		return fmt.Errorf("RefreshTokenTimeout must be >= -1, got: %d", c.RefreshTokenTimeout)
+	}
+
 	// Check MaxLoginCount
 	if c.MaxLoginCount < NoLimit {
 		return fmt.Errorf("MaxLoginCount must be >= -1, got: %d", c.MaxLoginCount)
 	}
 
+	// Check TokenHashPepper, a short pepper is guessable
+	if c.TokenHashPepper != "" && len(c.TokenHashPepper) < MinTokenPepperLength {
+		return fmt.Errorf("TokenHashPepper must be at least %d bytes, got: %d", MinTokenPepperLength, len(c.TokenHashPepper))
+	}
+
+	// Check TokenSignKeySet, tags are HMACs so only secret keys work
+	if c.TokenSignKeySet != nil {
+		if c.TokenStyle.IsClaims() {
+			return fmt.Errorf("TokenSignKeySet does not apply to TokenStyle %s, claims are signed with JwtKeySet", c.TokenStyle)
+		}
+		if _, err := c.TokenSignKeySet.SigningKey(); err != nil {
+			return fmt.Errorf("TokenSignKeySet: %w", err)
+		}
+		for _, key := range c.TokenSignKeySet.Keys() {
+			if key.Algorithm != jwk.AlgHS256 {
+				return fmt.Errorf("TokenSignKeySet only accepts HMAC keys, got %s key: %s", key.Algorithm, key.ID)
+			}
+		}
+	}
+
+	// Check TokenBinding
+	if b := c.TokenBinding; b != nil {
+		if b.Policy != "" && !b.Policy.IsValid() {
+			return fmt.Errorf("invalid TokenBinding.Policy: %s", b.Policy)
+		}
+		if b.Policy == BindingPolicySafe && c.GetJwtMode() == JwtModeStateless {
+			return fmt.Errorf("TokenBinding.Policy safe needs storage, not supported in JwtMode stateless")
+		}
+		if b.IPv4Prefix < 0 || b.IPv4Prefix > 32 {
+			return fmt.Errorf("TokenBinding.IPv4Prefix must be 0-32, got: %d", b.IPv4Prefix)
+		}
+		if b.IPv6Prefix < 0 || b.IPv6Prefix > 128 {
+			return fmt.Errorf("TokenBinding.IPv6Prefix must be 0-128, got: %d", b.IPv6Prefix)
+		}
+	}
+
+	// Check JwtRevocation, only claim tokens carry a jti
+	if r := c.JwtRevocation; r != nil {
+		if !c.TokenStyle.IsClaims() {
+			return fmt.Errorf("JwtRevocation requires TokenStyle jwt or paseto")
+		}
+		if r.SyncInterval < 0 {
+			return fmt.Errorf("JwtRevocation.SyncInterval must be >= 0, got: %d", r.SyncInterval)
+		}
+		if r.Capacity < 0 {
+			return fmt.Errorf("JwtRevocation.Capacity must be >= 0, got: %d", r.Capacity)
+		}
+	}
+
 	// Check if at least one read source is enabled
-	if !c.IsReadHeader && !c.IsReadCookie && !c.IsReadBody {
-		return fmt.Errorf("at least one of IsReadHeader, IsReadCookie, or IsReadBody must be true")
+	if !c.IsReadHeader && !c.IsReadCookie && !c.IsReadBody && !c.IsReadQuery {
+		return fmt.Errorf("at least one of IsReadHeader, IsReadCookie, IsReadBody, or IsReadQuery must be true")
+	}
+
+	// Check TokenSources
+	for _, source := range c.TokenSources {
+		if !source.IsValid() {
+			return fmt.Errorf("invalid TokenSource: %s", source)
+		}
 	}
 
 	return nil
@@ -216,9 +521,41 @@ func (c *Config) Clone() *Config {
 		cookieConfig := *c.CookieConfig
 		newConfig.CookieConfig = &cookieConfig
 	}
+	if c.TokenBinding != nil {
+		tokenBinding := *c.TokenBinding
+		newConfig.TokenBinding = &tokenBinding
+	}
+	if c.JwtRevocation != nil {
+		jwtRevocation := *c.JwtRevocation
+		newConfig.JwtRevocation = &jwtRevocation
+	}
+	if c.TokenSources != nil {
+		newConfig.TokenSources = append([]TokenSource(nil), c.TokenSources...)
+	}
 	return &newConfig
 }
 
+// GetTokenSources Gets the token source order, DefaultTokenSources if not set | 获取Token来源顺序，未设置时返回DefaultTokenSources
+func (c *Config) GetTokenSources() []TokenSource {
+	if len(c.TokenSources) == 0 {
+		return DefaultTokenSources
+	}
+	return c.TokenSources
+}
+
+// HasJwtKey Checks if a JWT secret or key set is configured | 检查是否配置了JWT密钥或密钥集
+func (c *Config) HasJwtKey() bool {
+	return c.JwtSecretKey != "" || (c.JwtKeySet != nil && c.JwtKeySet.Len() > 0)
+}
+
+// GetJwtMode Gets the JWT mode, JwtModeSimple if not set | 获取JWT模式，未设置时返回JwtModeSimple
+func (c *Config) GetJwtMode() JwtMode {
+	if c.JwtMode == "" {
+		return JwtModeSimple
+	}
+	return c.JwtMode
+}
+
 // SetTokenName Set Token name | 设置Token名称
 func (c *Config) SetTokenName(name string) *Config {
 	c.TokenName = name
@@ -237,6 +574,12 @@ func (c *Config) SetActiveTimeout(timeout int64) *Config {
 	return c
 }
 
+// SetRefreshTokenTimeout Set refresh token timeout duration | 设置刷新令牌超时时间
+func (c *Config) SetRefreshTokenTimeout(timeout int64) *Config {
+	c.RefreshTokenTimeout = timeout
+	return c
+}
+
 // SetIsConcurrent Set whether to allow concurrent login | 设置是否允许并发登录
 func (c *Config) SetIsConcurrent(isConcurrent bool) *Config {
 	c.IsConcurrent = isConcurrent
@@ -273,6 +616,60 @@ func (c *Config) SetIsReadCookie(isReadCookie bool) *Config {
 	return c
 }
 
+// SetIsReadQuery Set whether to read Token from query string | 设置是否从查询参数读取Token
+func (c *Config) SetIsReadQuery(isReadQuery bool) *Config {
+	c.IsReadQuery = isReadQuery
+	return c
+}
+
+// SetTokenSources Set token source order | 设置Token来源顺序
+func (c *Config) SetTokenSources(sources ...TokenSource) *Config {
+	c.TokenSources = sources
+	return c
+}
+
+// SetTokenPrefix Set prefix stripped from header values | 设置从请求头值中去除的前缀
+func (c *Config) SetTokenPrefix(prefix string) *Config {
+	c.TokenPrefix = prefix
+	return c
+}
+
+// SetAuthHeader Set extra header carrying the token | 设置额外携带Token的请求头
+func (c *Config) SetAuthHeader(header string) *Config {
+	c.AuthHeader = header
+	return c
+}
+
+// SetIsWriteHeader Set whether to write issued tokens to the response header | 设置是否将签发的Token写入响应头
+func (c *Config) SetIsWriteHeader(isWriteHeader bool) *Config {
+	c.IsWriteHeader = isWriteHeader
+	return c
+}
+
+// SetIsRememberMe Set whether logins default to a persistent cookie | 设置登录是否默认使用持久Cookie
+func (c *Config) SetIsRememberMe(isRememberMe bool) *Config {
+	c.IsRememberMe = isRememberMe
+	return c
+}
+
+// SetCsrfHeaderName Set header carrying the CSRF token | 设置携带CSRF令牌的请求头
+func (c *Config) SetCsrfHeaderName(name string) *Config {
+	c.CsrfHeaderName = name
+	return c
+}
+
+// SetCsrfFieldName Set form field carrying the CSRF token | 设置携带CSRF令牌的表单字段
+func (c *Config) SetCsrfFieldName(name string) *Config {
+	c.CsrfFieldName = name
+	return c
+}
+
+// SetCsrfCookieName Set cookie the CSRF token is issued in | 设置下发CSRF令牌的Cookie
+func (c *Config) SetCsrfCookieName(name string) *Config {
+	c.CsrfCookieName = name
+	return c
+}
+
 // SetTokenStyle Set Token generation style | 设置Token风格
 func (c *Config) SetTokenStyle(style TokenStyle) *Config {
 	c.TokenStyle = style
@@ -297,6 +694,18 @@ func (c *Config) SetJwtSecretKey(key string) *Config {
 	return c
 }
 
+// SetJwtKeySet Set JWT signing key set | 设置JWT签名密钥集
+func (c *Config) SetJwtKeySet(keySet *jwk.KeySet) *Config {
+	c.JwtKeySet = keySet
+	return c
+}
+
+// SetJwtMode Set JWT storage mode | 设置JWT存储模式
+func (c *Config) SetJwtMode(mode JwtMode) *Config {
+	c.JwtMode = mode
+	return c
+}
+
 // SetAutoRenew Set whether to auto-renew Token | 设置是否自动续期
 func (c *Config) SetAutoRenew(autoRenew bool) *Config {
 	c.AutoRenew = autoRenew
@@ -315,12 +724,42 @@ func (c *Config) SetIsPrintBanner(isPrint bool) *Config {
 	return c
 }
 
+// SetTokenHashPepper Set the pepper for hashed token storage | 设置Token哈希存储的密钥
+func (c *Config) SetTokenHashPepper(pepper string) *Config {
+	c.TokenHashPepper = pepper
+	return c
+}
+
+// SetTokenSignKeySet Set the HMAC keys tagging opaque tokens | 设置为不透明Token添加标签的HMAC密钥集
+func (c *Config) SetTokenSignKeySet(keySet *jwk.KeySet) *Config {
+	c.TokenSignKeySet = keySet
+	return c
+}
+
 // SetKeyPrefix Set storage key prefix | 设置存储键前缀
 func (c *Config) SetKeyPrefix(prefix string) *Config {
 	c.KeyPrefix = prefix
 	return c
 }
 
+// SetPermissionIgnoreCase Set whether to match permissions case-insensitively | 设置权限匹配是否忽略大小写
+func (c *Config) SetPermissionIgnoreCase(ignoreCase bool) *Config {
+	c.PermissionIgnoreCase = ignoreCase
+	return c
+}
+
+// SetTokenBinding Set token binding configuration | 设置Token绑定配置
+func (c *Config) SetTokenBinding(binding *TokenBindingConfig) *Config {
+	c.TokenBinding = binding
+	return c
+}
+
+// SetJwtRevocation Set JWT revocation list configuration | 设置JWT撤销列表配置
+func (c *Config) SetJwtRevocation(revocation *JwtRevocationConfig) *Config {
+	c.JwtRevocation = revocation
+	return c
+}
+
 // SetCookieConfig Set cookie configuration | 设置Cookie配置
 func (c *Config) SetCookieConfig(cookieConfig *CookieConfig) *Config {
 	c.CookieConfig = cookieConfig
@@ -1,21 +1,32 @@
 package context
 
 import (
+	"encoding/json"
+	"errors"
 	"strings"
+	"time"
 
 	"suwei.sa_token/core/adapter"
+	"suwei.sa_token/core/config"
 	"suwei.sa_token/core/manager"
+	"suwei.sa_token/core/policy"
+	"suwei.sa_token/core/security"
 )
 
 const (
-	bearerPrefix = "Bearer "
-	authHeader   = "Authorization"
+	contentTypeHeader = "Content-Type"
+	exposeHeaders     = "Access-Control-Expose-Headers"
 )
 
 // SaTokenContext Sa-Token context for current request | Sa-Token上下文，用于当前请求
 type SaTokenContext struct {
 	ctx     adapter.RequestContext
 	manager *manager.Manager
+
+	tokenValue    string             // Resolved token | 已解析的Token
+	tokenSource   config.TokenSource // Source of tokenValue, empty if set or missing | tokenValue的来源，设置或缺失时为空
+	tokenResolved bool               // Whether tokenValue is resolved | tokenValue是否已解析
+	cookieRenewed bool               // Whether the cookie was renewed in this request | 本次请求是否已续期Cookie
 }
 
 // NewContext creates a new Sa-Token context | 创建新的Sa-Token上下文
@@ -26,89 +37,331 @@ func NewContext(ctx adapter.RequestContext, mgr *manager.Manager) *SaTokenContex
 	}
 }
 
-// extractBearerToken 从 Authorization 头中提取 Bearer Token
-func extractBearerToken(auth string) string {
-	auth = strings.TrimSpace(auth)
-	if auth == "" {
-		return ""
+// stripPrefix 去除请求头值中的Token前缀（不区分大小写），没有前缀时原样返回
+func stripPrefix(value, prefix string) string {
+	value = strings.TrimSpace(value)
+	if prefix == "" || len(value) <= len(prefix) {
+		return value
 	}
-
-	// 支持大小写不敏感的 Bearer 前缀
-	if len(auth) > 7 && strings.EqualFold(auth[:7], bearerPrefix) {
-		return strings.TrimSpace(auth[7:])
+	if strings.EqualFold(value[:len(prefix)], prefix) && value[len(prefix)] == ' ' {
+		return strings.TrimSpace(value[len(prefix)+1:])
 	}
-
-	return auth
+	return value
 }
 
-// GetTokenValue gets token value from current request | 获取当前请求的Token值
+// GetTokenValue gets token value from current request, resolved once per context | 获取当前请求的Token值，每个上下文只解析一次
+// Sources are tried in cfg.TokenSources order, each only if its IsReadXxx flag is on |
+// 按 cfg.TokenSources 顺序尝试各来源，仅在对应的 IsReadXxx 开启时读取
 func (c *SaTokenContext) GetTokenValue() string {
+	if c.tokenResolved {
+		return c.tokenValue
+	}
+
 	cfg := c.manager.GetConfig()
+	for _, source := range cfg.GetTokenSources() {
+		if token := c.readToken(cfg, source); token != "" {
+			c.tokenValue, c.tokenSource = token, source
+			break
+		}
+	}
+	c.tokenResolved = true
+	return c.tokenValue
+}
 
-	// 1. 尝试从Header获取
-	if cfg.IsReadHeader {
-		// 从自定义 token 名称的 Header 获取
-		if token := strings.TrimSpace(c.ctx.GetHeader(cfg.TokenName)); token != "" {
+// readToken 从单个来源读取Token
+func (c *SaTokenContext) readToken(cfg *config.Config, source config.TokenSource) string {
+	switch source {
+	case config.TokenSourceHeader:
+		if !cfg.IsReadHeader {
+			return ""
+		}
+		if token := stripPrefix(c.ctx.GetHeader(cfg.TokenName), cfg.TokenPrefix); token != "" {
 			return token
 		}
-
-		// 从 Authorization 头获取
-		if auth := c.ctx.GetHeader(authHeader); auth != "" {
-			if token := extractBearerToken(auth); token != "" {
-				return token
-			}
+		if cfg.AuthHeader != "" {
+			return stripPrefix(c.ctx.GetHeader(cfg.AuthHeader), cfg.TokenPrefix)
+		}
+	case config.TokenSourceCookie:
+		if cfg.IsReadCookie {
+			return strings.TrimSpace(c.ctx.GetCookie(cfg.TokenName))
+		}
+	case config.TokenSourceBody:
+		if cfg.IsReadBody {
+			return c.readBodyToken(cfg.TokenName)
 		}
+	case config.TokenSourceQuery:
+		if cfg.IsReadQuery {
+			return strings.TrimSpace(c.ctx.GetQuery(cfg.TokenName))
+		}
+	}
+	return ""
+}
+
+// readBodyToken 从JSON请求体或表单中读取Token
+func (c *SaTokenContext) readBodyToken(tokenName string) string {
+	if !strings.Contains(strings.ToLower(c.ctx.GetHeader(contentTypeHeader)), "json") {
+		return strings.TrimSpace(c.ctx.GetPostForm(tokenName))
+	}
+
+	body, err := c.ctx.GetBody()
+	if err != nil || len(body) == 0 {
+		return ""
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return ""
 	}
+	token, _ := fields[tokenName].(string)
+	return strings.TrimSpace(token)
+}
+
+// SetTokenValue sets the token of this context and hands it to the response, the cookie follows Config.IsRememberMe |
+// 设置当前上下文的Token并交给响应，Cookie类型由Config.IsRememberMe决定
+func (c *SaTokenContext) SetTokenValue(tokenValue string) {
+	c.setTokenValue(tokenValue, c.manager.GetConfig().IsRememberMe)
+}
+
+// setTokenValue writes the response header if IsWriteHeader and the cookie if IsReadCookie |
+// IsWriteHeader开启时写入响应头，IsReadCookie开启时写入Cookie
+func (c *SaTokenContext) setTokenValue(tokenValue string, rememberMe bool) {
+	c.tokenValue, c.tokenSource = tokenValue, ""
+	c.tokenResolved = true
 
-	// 2. 尝试从Cookie获取
+	cfg := c.manager.GetConfig()
+	if cfg.IsWriteHeader {
+		c.ctx.SetHeader(cfg.TokenName, tokenValue)
+		// Let browsers read the header on cross-origin requests | 允许浏览器在跨域请求中读取该响应头
+		c.ctx.SetHeader(exposeHeaders, cfg.TokenName)
+	}
 	if cfg.IsReadCookie {
-		if token := strings.TrimSpace(c.ctx.GetCookie(cfg.TokenName)); token != "" {
-			return token
-		}
+		c.writeTokenCookie(tokenValue, rememberMe)
 	}
+}
 
-	// 3. 尝试从Query参数获取
-	if token := strings.TrimSpace(c.ctx.GetQuery(cfg.TokenName)); token != "" {
-		return token
+// Login logs in and hands the new token to the response, the cookie follows Config.IsRememberMe |
+// 登录并将新Token交给响应，Cookie类型由Config.IsRememberMe决定
+func (c *SaTokenContext) Login(loginID string, device ...string) (string, error) {
+	opts := &manager.LoginOptions{}
+	if len(device) > 0 {
+		opts.Device = device[0]
 	}
+	return c.LoginWithOptions(loginID, opts)
+}
 
-	return ""
+// LoginRemember logs in with a persistent ("remember me") or session cookie | 登录，使用持久（"记住我"）Cookie或会话Cookie
+func (c *SaTokenContext) LoginRemember(loginID string, rememberMe bool, device ...string) (string, error) {
+	opts := &manager.LoginOptions{RememberMe: &rememberMe}
+	if len(device) > 0 {
+		opts.Device = device[0]
+	}
+	return c.LoginWithOptions(loginID, opts)
+}
+
+// LoginWithOptions logs in with per-login options and hands the new token to the response |
+// 使用单次登录选项登录并将新Token交给响应
+func (c *SaTokenContext) LoginWithOptions(loginID string, opts *manager.LoginOptions) (string, error) {
+	opts = c.bindClient(opts)
+	tokenValue, err := c.manager.LoginWithOptions(loginID, opts)
+	if err != nil {
+		return "", err
+	}
+	if err := c.handOut(tokenValue, opts); err != nil {
+		return "", err
+	}
+	return tokenValue, nil
+}
+
+// LoginWithRefreshToken logs in with a refresh token bound to this client and hands the access token to the response |
+// 登录并获取绑定到当前客户端的刷新令牌，将访问令牌交给响应
+func (c *SaTokenContext) LoginWithRefreshToken(loginID string, opts *manager.LoginOptions) (*security.RefreshTokenInfo, error) {
+	opts = c.bindClient(opts)
+	info, err := c.manager.LoginWithRefreshTokenOptions(loginID, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.handOut(info.AccessToken, opts); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// bindClient Sets the fingerprint of this client on a copy of opts unless one is given |
+// 在opts的副本上设置当前客户端指纹，已指定时不覆盖
+func (c *SaTokenContext) bindClient(opts *manager.LoginOptions) *manager.LoginOptions {
+	fp := c.ClientFingerprint()
+	if fp == nil || (opts != nil && opts.Fingerprint != nil) {
+		return opts
+	}
+	bound := manager.LoginOptions{}
+	if opts != nil {
+		bound = *opts
+	}
+	bound.Fingerprint = fp
+	return &bound
+}
+
+// handOut Hands a new token to the response with its CSRF cookie | 将新Token及其CSRF Cookie交给响应
+func (c *SaTokenContext) handOut(tokenValue string, opts *manager.LoginOptions) error {
+	rememberMe := opts.IsRememberMe(c.manager.GetConfig())
+	c.setTokenValue(tokenValue, rememberMe)
+	if c.manager.GetConfig().IsReadCookie {
+		return c.writeCSRFCookie(c.cookieMaxAge(rememberMe))
+	}
+	return nil
+}
+
+// Logout logs out the current token and clears its cookie | 注销当前Token并清除Cookie
+func (c *SaTokenContext) Logout() error {
+	if tokenValue := c.GetTokenValue(); tokenValue != "" {
+		// Stateless JWTs cannot be revoked, clearing the cookie is all that is left |
+		// 无状态JWT无法注销，只能清除Cookie
+		if err := c.manager.LogoutByToken(tokenValue); err != nil && !errors.Is(err, manager.ErrJwtModeUnsupported) {
+			return err
+		}
+	}
+	c.tokenValue, c.tokenSource = "", ""
+	if c.manager.GetConfig().IsReadCookie {
+		c.clearTokenCookie()
+		c.clearCSRFCookie()
+	}
+	return nil
 }
 
 // IsLogin 检查当前请求是否已登录
 func (c *SaTokenContext) IsLogin() bool {
 	token := c.GetTokenValue()
-	return c.manager.IsLogin(token)
+	if !c.manager.IsLogin(token) || c.checkBinding(token) != nil {
+		return false
+	}
+	c.renewCookie()
+	return true
 }
 
 // CheckLogin 检查登录（未登录抛出错误）
 func (c *SaTokenContext) CheckLogin() error {
 	token := c.GetTokenValue()
-	return c.manager.CheckLogin(token)
+	if err := c.manager.CheckLogin(token); err != nil {
+		return err
+	}
+	if err := c.checkBinding(token); err != nil {
+		return err
+	}
+	c.renewCookie()
+	return nil
 }
 
 // GetLoginID 获取当前登录ID
 func (c *SaTokenContext) GetLoginID() (string, error) {
 	token := c.GetTokenValue()
-	return c.manager.GetLoginID(token)
+	loginID, err := c.manager.GetLoginID(token)
+	if err != nil {
+		return "", err
+	}
+	if err := c.checkBinding(token); err != nil {
+		return "", err
+	}
+	return loginID, nil
+}
+
+// ClientFingerprint Gets the fingerprint of the requesting client, nil when TokenBinding is off |
+// 获取请求客户端的指纹，未开启TokenBinding时返回nil
+func (c *SaTokenContext) ClientFingerprint() *manager.ClientFingerprint {
+	binding := c.manager.GetConfig().TokenBinding
+	if binding == nil {
+		return nil
+	}
+	deviceID := ""
+	if binding.DeviceIDHeader != "" {
+		deviceID = c.ctx.GetHeader(binding.DeviceIDHeader)
+	}
+	return c.manager.NewClientFingerprint(c.ctx.GetClientIP(), c.ctx.GetUserAgent(), deviceID)
+}
+
+// checkBinding Checks the token is used by the client it was bound to | 检查Token是否由其绑定的客户端使用
+func (c *SaTokenContext) checkBinding(token string) error {
+	if c.manager.GetConfig().TokenBinding == nil {
+		return nil
+	}
+	return c.manager.CheckBinding(token, c.ClientFingerprint())
+}
+
+// GetTokenInfo 获取当前Token的信息，包括登录时传入的额外数据
+func (c *SaTokenContext) GetTokenInfo() (*manager.TokenInfo, error) {
+	token := c.GetTokenValue()
+	if !c.manager.IsLogin(token) {
+		return nil, manager.ErrNotLogin
+	}
+	return c.manager.GetTokenInfo(token)
 }
 
-// HasPermission 检查是否有指定权限
+// GetExtra 获取登录时传入的额外数据
+func (c *SaTokenContext) GetExtra(key string) (any, bool) {
+	info, err := c.GetTokenInfo()
+	if err != nil || info.Extra == nil {
+		return nil, false
+	}
+	value, ok := info.Extra[key]
+	return value, ok
+}
+
+// HasPermission 检查是否有指定权限（JWT mixin/stateless模式下读取声明）
 func (c *SaTokenContext) HasPermission(permission string) bool {
+	return c.manager.HasPermissionByToken(c.GetTokenValue(), permission)
+}
+
+// HasRole 检查是否有指定角色（JWT mixin/stateless模式下读取声明）
+func (c *SaTokenContext) HasRole(role string) bool {
+	return c.manager.HasRoleByToken(c.GetTokenValue(), role)
+}
+
+// CheckDisable Checks if current account is disabled | 检查当前账号是否被封禁
+func (c *SaTokenContext) CheckDisable() error {
 	loginID, err := c.GetLoginID()
 	if err != nil {
-		return false
+		return err
+	}
+	if c.manager.IsDisable(loginID) {
+		return manager.ErrAccountDisabled
 	}
-	return c.manager.HasPermission(loginID, permission)
+	return nil
 }
 
-// HasRole 检查是否有指定角色
-func (c *SaTokenContext) HasRole(role string) bool {
+// OpenSafe Opens second-level auth for current token | 为当前Token开启二级认证
+func (c *SaTokenContext) OpenSafe(service string, duration time.Duration) error {
+	return c.manager.OpenSafe(c.GetTokenValue(), service, duration)
+}
+
+// IsSafe Checks second-level auth of current token | 检查当前Token是否处于二级认证状态
+func (c *SaTokenContext) IsSafe(service string) bool {
+	return c.manager.IsSafe(c.GetTokenValue(), service)
+}
+
+// CheckSafe Checks second-level auth, returns error if not open | 检查二级认证，未开启返回错误
+func (c *SaTokenContext) CheckSafe(service string) error {
+	return c.manager.CheckSafe(c.GetTokenValue(), service)
+}
+
+// CloseSafe Closes second-level auth of current token | 关闭当前Token的二级认证
+func (c *SaTokenContext) CloseSafe(service string) error {
+	return c.manager.CloseSafe(c.GetTokenValue(), service)
+}
+
+// Authorize Checks ABAC policies for action on resource | 检查对资源执行操作的ABAC策略
+func (c *SaTokenContext) Authorize(action string, resource *policy.Resource) error {
 	loginID, err := c.GetLoginID()
 	if err != nil {
-		return false
+		return err
+	}
+	return c.manager.CheckAuthorize(loginID, action, resource)
+}
+
+// CheckAccess Checks resource ACL for current login | 检查当前登录账号的资源ACL
+func (c *SaTokenContext) CheckAccess(resourceType, resourceID, action string) error {
+	loginID, err := c.GetLoginID()
+	if err != nil {
+		return err
 	}
-	return c.manager.HasRole(loginID, role)
+	return c.manager.CheckAccess(loginID, resourceType, resourceID, action)
 }
 
 // GetRequestContext 获取原始请求上下文
@@ -0,0 +1,389 @@
+package context
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"suwei.sa_token/core/adapter"
+	"suwei.sa_token/core/config"
+	"suwei.sa_token/core/jwk"
+	"suwei.sa_token/core/manager"
+)
+
+// mapStorage minimal in-memory adapter.Storage for tests, safe for the async auto-renew
+type mapStorage struct {
+	mu sync.Mutex
+	m  map[string]any
+}
+
+func (s *mapStorage) Set(key string, value any, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[key] = value
+	return nil
+}
+func (s *mapStorage) Get(key string) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m[key], nil
+}
+func (s *mapStorage) Exists(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.m[key]
+	return ok
+}
+func (s *mapStorage) Delete(keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, k := range keys {
+		delete(s.m, k)
+	}
+	return nil
+}
+func (s *mapStorage) Keys(pattern string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for k := range s.m {
+		if strings.HasPrefix(k, strings.TrimSuffix(pattern, "*")) {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+func (s *mapStorage) Expire(string, time.Duration) error { return nil }
+func (s *mapStorage) TTL(string) (time.Duration, error)  { return -1, nil }
+func (s *mapStorage) Clear() error                       { return nil }
+func (s *mapStorage) Ping() error                        { return nil }
+
+// fakeContext Request with headers, cookies, query, form and body | 带请求头、Cookie、查询参数、表单和请求体的请求
+type fakeContext struct {
+	adapter.RequestContext
+	method   string
+	header   map[string]string
+	cookie   map[string]string
+	query    map[string]string
+	form     map[string]string
+	body     string
+	response map[string]string
+	cookies  []*adapter.CookieOptions
+	clientIP string
+	ua       string
+}
+
+func (f *fakeContext) GetMethod() string             { return f.method }
+func (f *fakeContext) GetHeader(key string) string   { return f.header[key] }
+func (f *fakeContext) GetCookie(key string) string   { return f.cookie[key] }
+func (f *fakeContext) GetQuery(key string) string    { return f.query[key] }
+func (f *fakeContext) GetPostForm(key string) string { return f.form[key] }
+func (f *fakeContext) GetBody() ([]byte, error)      { return []byte(f.body), nil }
+func (f *fakeContext) GetClientIP() string           { return f.clientIP }
+func (f *fakeContext) GetUserAgent() string          { return f.ua }
+func (f *fakeContext) SetCookieWithOptions(options *adapter.CookieOptions) {
+	f.cookies = append(f.cookies, options)
+}
+func (f *fakeContext) SetHeader(key, value string) {
+	if f.response == nil {
+		f.response = map[string]string{}
+	}
+	f.response[key] = value
+}
+
+func newManager(cfg *config.Config) *manager.Manager {
+	return manager.NewManager(&mapStorage{m: map[string]any{}}, cfg)
+}
+
+func TestGetTokenValueSources(t *testing.T) {
+	cfg := config.DefaultConfig()
+	mgr := newManager(cfg)
+
+	cases := []struct {
+		name string
+		ctx  *fakeContext
+		want string
+	}{
+		{"token header", &fakeContext{header: map[string]string{"satoken": "Bearer a"}}, "a"},
+		{"auth header", &fakeContext{header: map[string]string{"Authorization": "bearer b"}}, "b"},
+		{"raw auth header", &fakeContext{header: map[string]string{"Authorization": "c"}}, "c"},
+		{"query disabled", &fakeContext{query: map[string]string{"satoken": "d"}}, ""},
+		{"cookie disabled", &fakeContext{cookie: map[string]string{"satoken": "e"}}, ""},
+	}
+	for _, tc := range cases {
+		if got := NewContext(tc.ctx, mgr).GetTokenValue(); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	cfg.SetIsReadHeader(false).SetIsReadQuery(true).SetIsReadBody(true).SetIsReadCookie(true).
+		SetTokenSources(config.TokenSourceQuery, config.TokenSourceBody, config.TokenSourceCookie)
+	both := &fakeContext{query: map[string]string{"satoken": "q"}, cookie: map[string]string{"satoken": "k"}}
+	if got := NewContext(both, mgr).GetTokenValue(); got != "q" {
+		t.Errorf("ordered sources: got %q, want q", got)
+	}
+
+	jsonBody := &fakeContext{header: map[string]string{"Content-Type": "application/json"}, body: `{"satoken":"j"}`}
+	if got := NewContext(jsonBody, mgr).GetTokenValue(); got != "j" {
+		t.Errorf("json body: got %q, want j", got)
+	}
+	form := &fakeContext{form: map[string]string{"satoken": "f"}}
+	if got := NewContext(form, mgr).GetTokenValue(); got != "f" {
+		t.Errorf("form body: got %q, want f", got)
+	}
+}
+
+func TestTokenPrefixAndAuthHeader(t *testing.T) {
+	cfg := config.DefaultConfig().SetTokenPrefix("Token").SetAuthHeader("X-Auth")
+	mgr := newManager(cfg)
+
+	if got := NewContext(&fakeContext{header: map[string]string{"X-Auth": "Token abc"}}, mgr).GetTokenValue(); got != "abc" {
+		t.Errorf("custom prefix: got %q, want abc", got)
+	}
+	if got := NewContext(&fakeContext{header: map[string]string{"Authorization": "Bearer abc"}}, mgr).GetTokenValue(); got != "" {
+		t.Errorf("disabled Authorization header: got %q", got)
+	}
+}
+
+func TestLoginWritesHeader(t *testing.T) {
+	mgr := newManager(config.DefaultConfig().SetIsWriteHeader(true))
+	ctx := &fakeContext{}
+	saCtx := NewContext(ctx, mgr)
+
+	token, err := saCtx.Login("1001")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if ctx.response["satoken"] != token || ctx.response["Access-Control-Expose-Headers"] != "satoken" {
+		t.Errorf("response headers = %v", ctx.response)
+	}
+	if saCtx.GetTokenValue() != token || !saCtx.IsLogin() {
+		t.Error("context should use the issued token")
+	}
+}
+
+func TestCookieLifecycle(t *testing.T) {
+	cfg := config.DefaultConfig().SetIsReadCookie(true)
+	cfg.CookieConfig.SameSite = config.SameSiteStrict
+	cfg.CookieConfig.Secure = true
+	mgr := newManager(cfg)
+
+	// Remember me: persistent cookie for the token timeout | 记住我：有效期为Token超时时间的持久Cookie
+	login := &fakeContext{}
+	token, err := NewContext(login, mgr).Login("1001")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	cookie := login.cookies[0]
+	if cookie.Value != token || cookie.MaxAge != int(cfg.Timeout) || cookie.SameSite != "Strict" || !cookie.Secure || !cookie.HttpOnly {
+		t.Errorf("remember-me cookie = %+v", cookie)
+	}
+
+	// Auto-renew re-issues the persistent cookie once per request | 自动续期在每个请求中最多重新签发一次持久Cookie
+	renew := &fakeContext{cookie: map[string]string{"satoken": token}}
+	saCtx := NewContext(renew, mgr)
+	if !saCtx.IsLogin() || saCtx.CheckLogin() != nil || len(renew.cookies) != 2 || renew.cookies[0].MaxAge != int(cfg.Timeout) {
+		t.Errorf("renewed cookies = %+v", renew.cookies)
+	}
+
+	// Logout clears the cookie | 登出清除Cookie
+	if err := saCtx.Logout(); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	if last := renew.cookies[len(renew.cookies)-1]; last.Value != "" || last.MaxAge != 0 || last.Session || mgr.IsLogin(token) {
+		t.Errorf("logout cookie = %+v", last)
+	}
+
+	// Session cookie is never extended | 会话Cookie不会被续期
+	session := &fakeContext{}
+	token, _ = NewContext(session, mgr).LoginRemember("1002", false)
+	if !session.cookies[0].Session || session.cookies[0].HTTPMaxAge() != 0 {
+		t.Errorf("session cookie = %+v", session.cookies[0])
+	}
+	again := &fakeContext{cookie: map[string]string{"satoken": token}}
+	if err := NewContext(again, mgr).CheckLogin(); err != nil || len(again.cookies) != 0 {
+		t.Errorf("session cookie renewed: error = %v, cookies = %+v", err, again.cookies)
+	}
+}
+
+func TestCheckCSRF(t *testing.T) {
+	mgr := newManager(config.DefaultConfig().SetIsReadCookie(true))
+
+	// Cookie login issues a script-readable CSRF cookie | Cookie登录下发可被脚本读取的CSRF Cookie
+	login := &fakeContext{}
+	token, err := NewContext(login, mgr).Login("1001")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	csrf := login.cookies[1]
+	if csrf.Name != config.DefaultCsrfCookie || csrf.Value == "" || csrf.HttpOnly {
+		t.Fatalf("csrf cookie = %+v", csrf)
+	}
+
+	cookie := map[string]string{"satoken": token}
+	cases := []struct {
+		name string
+		ctx  *fakeContext
+		ok   bool
+	}{
+		{"safe method", &fakeContext{method: "GET", cookie: cookie}, true},
+		{"missing csrf", &fakeContext{method: "POST", cookie: cookie}, false},
+		{"wrong csrf", &fakeContext{method: "POST", cookie: cookie, header: map[string]string{"X-CSRF-Token": "x"}}, false},
+		{"csrf header", &fakeContext{method: "POST", cookie: cookie, header: map[string]string{"X-CSRF-Token": csrf.Value}}, true},
+		{"csrf field", &fakeContext{method: "delete", cookie: cookie, form: map[string]string{"_csrf": csrf.Value}}, true},
+		{"header token", &fakeContext{method: "POST", header: map[string]string{"satoken": token}}, true},
+		{"no token", &fakeContext{method: "POST"}, true},
+	}
+	for _, tc := range cases {
+		err := NewContext(tc.ctx, mgr).CheckCSRF()
+		if (err == nil) != tc.ok {
+			t.Errorf("%s: CheckCSRF() error = %v", tc.name, err)
+		}
+	}
+
+	// Logout drops the CSRF token with the session | 登出时CSRF令牌随会话一起删除
+	logout := &fakeContext{method: "POST", cookie: cookie, header: map[string]string{"X-CSRF-Token": csrf.Value}}
+	if err := NewContext(logout, mgr).Logout(); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	if err := mgr.CheckCSRFToken(token, csrf.Value); err == nil {
+		t.Error("csrf token should be removed on logout")
+	}
+
+	// Stateless tokens signed by a key set derive CSRF tokens with TokenHashPepper |
+	// 使用密钥集签名的无状态Token使用TokenHashPepper派生CSRF令牌
+	key, _ := jwk.GenerateKey("k1", jwk.AlgEdDSA)
+	ks := jwk.NewKeySet()
+	ks.SetSigningKey(key)
+	cfg := config.DefaultConfig().SetIsReadCookie(true).SetTokenStyle(config.TokenStyleJWT).SetJwtKeySet(ks).SetJwtMode(config.JwtModeStateless)
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should require a CSRF secret for stateless cookie logins")
+	}
+	mgr = newManager(cfg.SetTokenHashPepper("0123456789abcdef-pepper"))
+	token, _ = mgr.Login("1001")
+	derived, err := mgr.GetCSRFToken(token)
+	if err != nil || mgr.CheckCSRFToken(token, derived) != nil {
+		t.Errorf("stateless GetCSRFToken() error = %v", err)
+	}
+	unkeyed := newManager(cfg.Clone().SetTokenHashPepper(""))
+	if _, err := unkeyed.GetCSRFToken(token); !errors.Is(err, manager.ErrCSRFSecretMissing) {
+		t.Errorf("GetCSRFToken() without a secret error = %v", err)
+	}
+}
+
+func TestContextJwtClaims(t *testing.T) {
+	mgr := newManager(config.DefaultConfig().SetTokenStyle(config.TokenStyleJWT).SetJwtSecretKey("secret").SetJwtMode(config.JwtModeStateless))
+	mgr.SetJwtClaimsLoader(func(string) ([]string, []string, error) {
+		return []string{"user:*"}, []string{"admin"}, nil
+	})
+	token, _ := mgr.Login("1001")
+
+	// Permission checks read the claims | 权限检查读取声明
+	saCtx := NewContext(&fakeContext{header: map[string]string{"satoken": token}}, mgr)
+	if !saCtx.IsLogin() || !saCtx.HasPermission("user:add") || !saCtx.HasRole("admin") {
+		t.Error("stateless context should read permissions and roles from claims")
+	}
+
+	// Stateless tokens cannot be revoked, Logout still clears the request | 无状态Token无法注销，Logout仍会清除请求中的Token
+	if err := saCtx.Logout(); err != nil {
+		t.Errorf("context Logout() error = %v", err)
+	}
+}
+
+func TestContextLoginWithOptions(t *testing.T) {
+	mgr := newManager(config.DefaultConfig().SetIsReadCookie(true))
+
+	login := &fakeContext{}
+	token, err := NewContext(login, mgr).LoginWithOptions("1001", &manager.LoginOptions{
+		Timeout: 600,
+		Extra:   map[string]any{"tenantId": "t1"},
+	})
+	if err != nil {
+		t.Fatalf("LoginWithOptions() error = %v", err)
+	}
+	if login.cookies[0].MaxAge != 600 {
+		t.Errorf("cookie MaxAge = %d, want the login timeout 600", login.cookies[0].MaxAge)
+	}
+	saCtx := NewContext(&fakeContext{header: map[string]string{"satoken": token}}, mgr)
+	if tenant, ok := saCtx.GetExtra("tenantId"); !ok || tenant != "t1" {
+		t.Errorf("GetExtra() = %v, %v", tenant, ok)
+	}
+}
+
+func TestContextRememberMeDefault(t *testing.T) {
+	for _, remember := range []bool{true, false} {
+		mgr := newManager(config.DefaultConfig().SetIsReadCookie(true).SetRefreshTokenTimeout(3600).SetIsRememberMe(remember))
+		logins := map[string]func(*SaTokenContext) error{
+			"Login": func(c *SaTokenContext) error {
+				_, err := c.Login("1001")
+				return err
+			},
+			"LoginWithOptions": func(c *SaTokenContext) error {
+				_, err := c.LoginWithOptions("1001", nil)
+				return err
+			},
+			"LoginWithRefreshToken": func(c *SaTokenContext) error {
+				_, err := c.LoginWithRefreshToken("1001", nil)
+				return err
+			},
+		}
+		for name, login := range logins {
+			ctx := &fakeContext{}
+			if err := login(NewContext(ctx, mgr)); err != nil {
+				t.Fatalf("%s() error = %v", name, err)
+			}
+			if persistent := !ctx.cookies[0].Session; persistent != remember {
+				t.Errorf("%s() with IsRememberMe=%v wrote cookie %+v", name, remember, ctx.cookies[0])
+			}
+		}
+	}
+}
+
+func TestContextTokenBinding(t *testing.T) {
+	mgr := newManager(config.DefaultConfig().SetTokenBinding(&config.TokenBindingConfig{IPv4Prefix: 24, UserAgent: true, DeviceIDHeader: "X-Device-Id"}))
+	client := func(ip, ua, device string, tokenValue string) *SaTokenContext {
+		return NewContext(&fakeContext{
+			clientIP: ip,
+			ua:       ua,
+			header:   map[string]string{"satoken": tokenValue, "X-Device-Id": device},
+		}, mgr)
+	}
+
+	// Login binds the token to the requesting client | 登录时将Token绑定到发起请求的客户端
+	tokenValue, err := client("10.0.0.7", "Firefox", "d1", "").LoginWithOptions("1001", nil)
+	if err != nil {
+		t.Fatalf("LoginWithOptions() error = %v", err)
+	}
+	if err := client("10.0.0.99", "Firefox", "d1", tokenValue).CheckLogin(); err != nil {
+		t.Errorf("CheckLogin() from the bound client error = %v", err)
+	}
+
+	stolen := client("192.168.1.5", "curl", "d1", tokenValue)
+	if err := stolen.CheckLogin(); !errors.Is(err, manager.ErrTokenBindingMismatch) {
+		t.Errorf("CheckLogin() from another client error = %v", err)
+	}
+	if stolen.IsLogin() {
+		t.Error("IsLogin() from another client should be false")
+	}
+	if _, err := stolen.GetLoginID(); !errors.Is(err, manager.ErrTokenBindingMismatch) {
+		t.Errorf("GetLoginID() from another client error = %v", err)
+	}
+
+	// Addresses with a port and refreshed tokens stay bound | 带端口的地址及刷新后的Token保持绑定
+	info, err := client("10.0.0.7:51234", "Firefox", "d1", "").LoginWithRefreshToken("1002", nil)
+	if err != nil {
+		t.Fatalf("LoginWithRefreshToken() error = %v", err)
+	}
+	next, err := mgr.RefreshAccessToken(info.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshAccessToken() error = %v", err)
+	}
+	if err := client("10.0.0.8:443", "Firefox", "d1", next.AccessToken).CheckLogin(); err != nil {
+		t.Errorf("CheckLogin() of the refreshed token from the bound client error = %v", err)
+	}
+	if err := client("192.168.1.5:443", "Firefox", "d1", next.AccessToken).CheckLogin(); !errors.Is(err, manager.ErrTokenBindingMismatch) {
+		t.Errorf("CheckLogin() of the refreshed token from another client error = %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package context
+
+import (
+	"math"
+
+	"suwei.sa_token/core/adapter"
+	"suwei.sa_token/core/config"
+)
+
+// Cookie lifecycle | Cookie生命周期
+//
+// Cookie Max-Age follows adapter.CookieOptions: > 0 persistent, -1 session cookie, 0 delete. Persistent
+// cookies live for CookieConfig.MaxAge, else the token timeout, else "forever" when the token never expires.
+// Cookie的Max-Age遵循adapter.CookieOptions：> 0为持久Cookie，-1为会话Cookie，0为删除。持久Cookie的有效期为
+// CookieConfig.MaxAge，其次为Token的超时时间，Token永不过期时为"永久"。
+
+const (
+	sessionCookieMaxAge = -1
+	deleteCookieMaxAge  = 0
+	foreverCookieMaxAge = math.MaxInt32
+)
+
+// writeTokenCookie 写入Token Cookie，rememberMe为false时写入会话Cookie
+func (c *SaTokenContext) writeTokenCookie(tokenValue string, rememberMe bool) {
+	c.ctx.SetCookieWithOptions(cookieOptions(c.manager.GetConfig(), tokenValue, c.cookieMaxAge(rememberMe)))
+}
+
+// cookieMaxAge 计算持久Cookie或会话Cookie的Max-Age
+func (c *SaTokenContext) cookieMaxAge(rememberMe bool) int {
+	if rememberMe {
+		return persistentMaxAge(c.manager.GetConfig(), c.manager.GetTokenTimeout(c.tokenValue))
+	}
+	return sessionCookieMaxAge
+}
+
+// clearTokenCookie 删除Token Cookie
+func (c *SaTokenContext) clearTokenCookie() {
+	c.ctx.SetCookieWithOptions(cookieOptions(c.manager.GetConfig(), "", deleteCookieMaxAge))
+}
+
+// renewCookie 自动续期时延长"记住我"Cookie，每个请求最多一次；会话Cookie无需续期
+func (c *SaTokenContext) renewCookie() {
+	if c.cookieRenewed || c.tokenSource != config.TokenSourceCookie {
+		return
+	}
+	c.cookieRenewed = true
+
+	cfg := c.manager.GetConfig()
+	if !cfg.AutoRenew || c.manager.GetTokenTimeout(c.tokenValue) <= 0 || !c.manager.IsRememberMe(c.tokenValue) {
+		return
+	}
+	c.writeTokenCookie(c.tokenValue, true)
+	_ = c.writeCSRFCookie(c.cookieMaxAge(true))
+}
+
+// persistentMaxAge 计算持久Cookie的Max-Age，timeout为Token的超时时间（秒）
+func persistentMaxAge(cfg *config.Config, timeout int64) int {
+	if cfg.CookieConfig != nil && cfg.CookieConfig.MaxAge > 0 {
+		return cfg.CookieConfig.MaxAge
+	}
+	if timeout > 0 && timeout < foreverCookieMaxAge {
+		return int(timeout)
+	}
+	return foreverCookieMaxAge
+}
+
+// cookieOptions 根据CookieConfig构建Token Cookie选项
+func cookieOptions(cfg *config.Config, value string, maxAge int) *adapter.CookieOptions {
+	cookieConfig := cfg.CookieConfig
+	if cookieConfig == nil {
+		cookieConfig = config.DefaultConfig().CookieConfig
+	}
+	path := cookieConfig.Path
+	if path == "" {
+		path = config.DefaultCookiePath
+	}
+	return &adapter.CookieOptions{
+		Name:     cfg.TokenName,
+		Value:    value,
+		MaxAge:   maxAge,
+		Session:  maxAge == sessionCookieMaxAge,
+		Path:     path,
+		Domain:   cookieConfig.Domain,
+		Secure:   cookieConfig.Secure,
+		HttpOnly: cookieConfig.HttpOnly,
+		SameSite: string(cookieConfig.SameSite),
+	}
+}
+
+// csrfCookieOptions 构建CSRF Cookie选项，与Token Cookie相同但允许脚本读取
+func csrfCookieOptions(cfg *config.Config, value string, maxAge int) *adapter.CookieOptions {
+	options := cookieOptions(cfg, value, maxAge)
+	options.Name = cfg.CsrfCookieName
+	options.HttpOnly = false
+	return options
+}
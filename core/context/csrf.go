@@ -0,0 +1,79 @@
+package context
+
+import (
+	"net/http"
+	"strings"
+
+	"suwei.sa_token/core/config"
+)
+
+// CSRF protection | CSRF防护
+//
+// Only requests whose token was read from the cookie need protection: browsers attach cookies
+// to cross-site requests but never header, body or query tokens. Safe methods must not change
+// state and are exempt. Cookie logins hand the CSRF token to the page in a script-readable
+// cookie (CsrfCookieName); the page echoes it in CsrfHeaderName or the CsrfFieldName form field.
+// 只有从Cookie读取Token的请求需要防护：浏览器会在跨站请求中附带Cookie，但不会附带请求头、请求体或查询参数
+// 中的Token。安全方法不得修改状态，因此被豁免。Cookie登录时通过可被脚本读取的Cookie（CsrfCookieName）
+// 将CSRF令牌交给页面，页面再通过CsrfHeaderName请求头或CsrfFieldName表单字段回传。
+
+// safeMethods Methods exempt from CSRF checks | 豁免CSRF检查的方法
+var safeMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+// CheckCSRF Checks the CSRF token of unsafe requests authenticated by cookie | 检查通过Cookie认证的非安全请求的CSRF令牌
+func (c *SaTokenContext) CheckCSRF() error {
+	if safeMethods[strings.ToUpper(c.ctx.GetMethod())] {
+		return nil
+	}
+	tokenValue := c.GetTokenValue()
+	if c.tokenSource != config.TokenSourceCookie {
+		return nil
+	}
+	return c.manager.CheckCSRFToken(tokenValue, c.readCSRFToken())
+}
+
+// GetCSRFToken Gets the CSRF token of the current login, issuing one if missing | 获取当前登录的CSRF令牌，不存在时签发
+func (c *SaTokenContext) GetCSRFToken() (string, error) {
+	return c.manager.GetCSRFToken(c.GetTokenValue())
+}
+
+// readCSRFToken 从请求头或表单字段读取提交的CSRF令牌
+func (c *SaTokenContext) readCSRFToken() string {
+	cfg := c.manager.GetConfig()
+	if cfg.CsrfHeaderName != "" {
+		if csrfToken := strings.TrimSpace(c.ctx.GetHeader(cfg.CsrfHeaderName)); csrfToken != "" {
+			return csrfToken
+		}
+	}
+	if cfg.CsrfFieldName != "" {
+		return strings.TrimSpace(c.ctx.GetPostForm(cfg.CsrfFieldName))
+	}
+	return ""
+}
+
+// writeCSRFCookie 将当前登录的CSRF令牌写入可被脚本读取的Cookie，未配置CsrfCookieName时跳过
+func (c *SaTokenContext) writeCSRFCookie(maxAge int) error {
+	cfg := c.manager.GetConfig()
+	if cfg.CsrfCookieName == "" {
+		return nil
+	}
+	csrfToken, err := c.GetCSRFToken()
+	if err != nil {
+		return err
+	}
+	c.ctx.SetCookieWithOptions(csrfCookieOptions(cfg, csrfToken, maxAge))
+	return nil
+}
+
+// clearCSRFCookie 删除CSRF Cookie
+func (c *SaTokenContext) clearCSRFCookie() {
+	cfg := c.manager.GetConfig()
+	if cfg.CsrfCookieName != "" {
+		c.ctx.SetCookieWithOptions(csrfCookieOptions(cfg, "", deleteCookieMaxAge))
+	}
+}
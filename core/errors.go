@@ -3,6 +3,9 @@ package core
 import (
 	"errors"
 	"fmt"
+
+	"suwei.sa_token/core/manager"
+	"suwei.sa_token/core/policy"
 )
 
 // Common error definitions for better error handling and internationalization support
@@ -25,6 +28,9 @@ var (
 
 	// ErrInvalidDevice indicates the device identifier is invalid | 设备标识无效
 	ErrInvalidDevice = fmt.Errorf("invalid device: the device identifier is not valid")
+
+	// ErrTokenBindingMismatch indicates the token is used from another client than it was bound to | Token在绑定之外的客户端上使用
+	ErrTokenBindingMismatch = fmt.Errorf("token binding mismatch: the token is bound to another client")
 )
 
 // ============ Authorization Errors | 授权错误 ============
@@ -35,6 +41,15 @@ var (
 
 	// ErrRoleDenied indicates insufficient role | 角色权限不足
 	ErrRoleDenied = fmt.Errorf("role denied: you don't have the required role")
+
+	// ErrAccessDenied indicates policies did not allow the action | 策略未允许该操作
+	ErrAccessDenied = fmt.Errorf("access denied: no policy allows this action on the resource")
+
+	// ErrNotSafe indicates second-level authentication is required | 需要二级认证
+	ErrNotSafe = fmt.Errorf("not safe: second-level authentication is required for this operation")
+
+	// ErrCSRFInvalid indicates the CSRF token is missing or does not match | CSRF令牌缺失或不匹配
+	ErrCSRFInvalid = fmt.Errorf("csrf check failed: the csrf token is missing or does not match the session")
 )
 
 // ============ Account Errors | 账号错误 ============
@@ -159,12 +174,74 @@ func NewRoleDeniedError(role string) *SaTokenError {
 		WithContext("role", role)
 }
 
+// NewAccessDeniedError Creates an access denied error | 创建访问拒绝错误
+func NewAccessDeniedError(action string, resource *policy.Resource) *SaTokenError {
+	err := NewError(CodePermissionDenied, "access denied", ErrAccessDenied).
+		WithContext("action", action)
+	if resource != nil {
+		err.WithContext("resourceType", resource.Type).WithContext("resourceId", resource.ID)
+	}
+	return err
+}
+
+// NewResourceAccessDeniedError Creates an ACL access denied error | 创建ACL访问拒绝错误
+func NewResourceAccessDeniedError(resourceType, resourceID, action string) *SaTokenError {
+	return NewError(CodePermissionDenied, "access denied", ErrAccessDenied).
+		WithContext("action", action).
+		WithContext("resourceType", resourceType).
+		WithContext("resourceId", resourceID)
+}
+
+// NewNotSafeError Creates a second-level auth required error | 创建需要二级认证错误
+func NewNotSafeError(service string) *SaTokenError {
+	return NewError(CodeNotSafe, "second-level authentication required", ErrNotSafe).
+		WithContext("service", service)
+}
+
+// NewCSRFError Creates a CSRF check failed error | 创建CSRF校验失败错误
+func NewCSRFError() *SaTokenError {
+	return NewError(CodeCSRFInvalid, "invalid csrf token", ErrCSRFInvalid)
+}
+
 // NewAccountDisabledError Creates an account disabled error | 创建账号禁用错误
 func NewAccountDisabledError(loginID string) *SaTokenError {
 	return NewError(CodeAccountDisabled, "account disabled", ErrAccountDisabled).
 		WithContext("loginID", loginID)
 }
 
+// ToSaTokenError Converts errors from core sub packages into SaTokenError | 将核心子包的错误转换为SaTokenError
+func ToSaTokenError(err error) *SaTokenError {
+	var saErr *SaTokenError
+	if errors.As(err, &saErr) {
+		return saErr
+	}
+
+	switch {
+	case errors.Is(err, manager.ErrNotLogin):
+		return NewError(CodeNotLogin, "user not logged in", err)
+	case errors.Is(err, manager.ErrAccountDisabled):
+		return NewError(CodeAccountDisabled, "account disabled", err)
+	case errors.Is(err, manager.ErrAccessDenied):
+		return NewError(CodePermissionDenied, "access denied", err)
+	case errors.Is(err, manager.ErrPermissionDenied):
+		return NewError(CodePermissionDenied, "permission denied", err)
+	case errors.Is(err, manager.ErrRoleDenied):
+		return NewError(CodePermissionDenied, "role denied", err)
+	case errors.Is(err, manager.ErrNotSafe):
+		return NewError(CodeNotSafe, "second-level authentication required", err)
+	case errors.Is(err, manager.ErrInvalidCSRFToken):
+		return NewError(CodeCSRFInvalid, "invalid csrf token", err)
+	case errors.Is(err, manager.ErrTokenBindingMismatch):
+		return NewError(CodeTokenBindingMismatch, "token bound to another client", err)
+	case errors.Is(err, manager.ErrInvalidClientIP):
+		return NewError(CodeBadRequest, "client ip cannot be parsed", err)
+	case errors.Is(err, manager.ErrInvalidTicket):
+		return NewError(CodeNotLogin, "invalid or expired ticket", err)
+	default:
+		return NewError(CodeServerError, err.Error(), err)
+	}
+}
+
 // ============ Error Checking Helpers | 错误检查辅助函数 ============
 
 // IsNotLoginError Checks if error is a not login error | 检查是否为未登录错误
@@ -177,6 +254,11 @@ func IsPermissionDeniedError(err error) bool {
 	return errors.Is(err, ErrPermissionDenied)
 }
 
+// IsAccessDeniedError Checks if error is an access denied error | 检查是否为访问拒绝错误
+func IsAccessDeniedError(err error) bool {
+	return errors.Is(err, ErrAccessDenied)
+}
+
 // IsAccountDisabledError Checks if error is an account disabled error | 检查是否为账号禁用错误
 func IsAccountDisabledError(err error) bool {
 	return errors.Is(err, ErrAccountDisabled)
@@ -208,13 +290,16 @@ const (
 	CodeServerError      = 500 // Internal server error | 服务器内部错误
 
 	// Sa-Token specific error codes (10000-19999) | Sa-Token 特定错误码 (10000-19999)
-	CodeTokenInvalid     = 10001 // Token is invalid or malformed | Token无效或格式错误
-	CodeTokenExpired     = 10002 // Token has expired | Token已过期
-	CodeAccountDisabled  = 10003 // Account is disabled | 账号已被禁用
-	CodeKickedOut        = 10004 // User has been kicked out | 用户已被踢下线
-	CodeActiveTimeout    = 10005 // Session inactive timeout | Session活跃超时
-	CodeMaxLoginCount    = 10006 // Maximum login count reached | 达到最大登录数量
-	CodeStorageError     = 10007 // Storage backend error | 存储后端错误
-	CodeInvalidParameter = 10008 // Invalid parameter | 无效参数
-	CodeSessionError     = 10009 // Session operation error | Session操作错误
+	CodeTokenInvalid         = 10001 // Token is invalid or malformed | Token无效或格式错误
+	CodeTokenExpired         = 10002 // Token has expired | Token已过期
+	CodeAccountDisabled      = 10003 // Account is disabled | 账号已被禁用
+	CodeKickedOut            = 10004 // User has been kicked out | 用户已被踢下线
+	CodeActiveTimeout        = 10005 // Session inactive timeout | Session活跃超时
+	CodeMaxLoginCount        = 10006 // Maximum login count reached | 达到最大登录数量
+	CodeStorageError         = 10007 // Storage backend error | 存储后端错误
+	CodeInvalidParameter     = 10008 // Invalid parameter | 无效参数
+	CodeSessionError         = 10009 // Session operation error | Session操作错误
+	CodeNotSafe              = 10010 // Second-level auth required | 需要二级认证
+	CodeCSRFInvalid          = 10011 // CSRF token missing or mismatched | CSRF令牌缺失或不匹配
+	CodeTokenBindingMismatch = 10012 // Token used from another client | Token在其他客户端上使用
 )
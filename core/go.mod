@@ -6,3 +6,8 @@ require (
 	github.com/golang-jwt/jwt/v5 v5.2.1
 	github.com/google/uuid v1.6.0
 )
+
+require (
+	golang.org/x/crypto v0.23.0
+	golang.org/x/sys v0.20.0 // indirect
+)
@@ -0,0 +1,246 @@
+package core
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Localized Error Messages
+// 错误消息本地化
+//
+// A MessageCatalog maps SaTokenError codes to messages per language. English and Chinese are
+// built in, more languages can be registered. Error renderers pick the language from the
+// request's Accept-Language header and replace the message of known codes; unknown codes keep
+// their original message.
+// MessageCatalog 按语言将SaTokenError错误码映射为消息，内置英文和中文，可注册更多语言。错误渲染器根据
+// 请求的 Accept-Language 请求头选择语言，并替换已知错误码的消息；未知错误码保留原始消息。
+//
+// Usage | 用法:
+//   core.GetMessageCatalog().RegisterLanguage("ja", map[int]string{core.CodeNotLogin: "ログインしていません"})
+//   core.GetMessageCatalog().SetFallback(core.LangZH)  // when Accept-Language is missing or unsupported | 缺少或不支持时使用
+
+// Language constants | 语言常量
+const (
+	LangEN = "en"
+	LangZH = "zh"
+
+	HeaderAcceptLanguage = "Accept-Language"
+)
+
+// defaultMessages Built-in messages | 内置消息
+var defaultMessages = map[string]map[int]string{
+	LangEN: {
+		CodeBadRequest:           "bad request",
+		CodeNotLogin:             "user not logged in",
+		CodePermissionDenied:     "permission denied",
+		CodeNotFound:             "resource not found",
+		CodeServerError:          "internal server error",
+		CodeTokenInvalid:         "invalid token",
+		CodeTokenExpired:         "token expired",
+		CodeAccountDisabled:      "account disabled",
+		CodeKickedOut:            "kicked out",
+		CodeActiveTimeout:        "session inactive timeout",
+		CodeMaxLoginCount:        "maximum login count reached",
+		CodeStorageError:         "storage error",
+		CodeInvalidParameter:     "invalid parameter",
+		CodeSessionError:         "session error",
+		CodeNotSafe:              "second-level authentication required",
+		CodeCSRFInvalid:          "invalid csrf token",
+		CodeTokenBindingMismatch: "token bound to another client",
+	},
+	LangZH: {
+		CodeBadRequest:           "请求参数错误",
+		CodeNotLogin:             "未登录",
+		CodePermissionDenied:     "权限不足",
+		CodeNotFound:             "资源不存在",
+		CodeServerError:          "服务器内部错误",
+		CodeTokenInvalid:         "Token无效",
+		CodeTokenExpired:         "Token已过期",
+		CodeAccountDisabled:      "账号已被禁用",
+		CodeKickedOut:            "已被踢下线",
+		CodeActiveTimeout:        "会话活跃超时",
+		CodeMaxLoginCount:        "已达到最大登录数量",
+		CodeStorageError:         "存储服务异常",
+		CodeInvalidParameter:     "参数无效",
+		CodeSessionError:         "会话操作失败",
+		CodeNotSafe:              "需要二级认证",
+		CodeCSRFInvalid:          "CSRF令牌无效",
+		CodeTokenBindingMismatch: "Token已绑定到其他客户端",
+	},
+}
+
+// MessageCatalog Error messages by language and code | 按语言和错误码组织的错误消息
+type MessageCatalog struct {
+	mu       sync.RWMutex
+	fallback string
+	messages map[string]map[int]string
+}
+
+// NewMessageCatalog Creates a catalog with en/zh built in, falling back to en | 创建内置en/zh的消息目录，默认回退到en
+func NewMessageCatalog() *MessageCatalog {
+	c := &MessageCatalog{
+		fallback: LangEN,
+		messages: make(map[string]map[int]string),
+	}
+	for lang, messages := range defaultMessages {
+		c.RegisterLanguage(lang, messages)
+	}
+	return c
+}
+
+// RegisterLanguage Adds or overrides messages of a language, e.g. "ja" or "zh-TW" | 添加或覆盖某语言的消息，例如 "ja" 或 "zh-TW"
+func (c *MessageCatalog) RegisterLanguage(lang string, messages map[int]string) *MessageCatalog {
+	lang = normalizeLang(lang)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.messages[lang] == nil {
+		c.messages[lang] = make(map[int]string, len(messages))
+	}
+	for code, message := range messages {
+		c.messages[lang][code] = message
+	}
+	return c
+}
+
+// SetFallback Sets the language used when no requested language is supported | 设置请求语言均不支持时使用的语言
+func (c *MessageCatalog) SetFallback(lang string) *MessageCatalog {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fallback = normalizeLang(lang)
+	return c
+}
+
+// Languages Gets the registered languages | 获取已注册的语言
+func (c *MessageCatalog) Languages() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	langs := make([]string, 0, len(c.messages))
+	for lang := range c.messages {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Message Gets the message of a code in a language | 获取某语言下错误码对应的消息
+func (c *MessageCatalog) Message(lang string, code int) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	message, ok := c.messages[normalizeLang(lang)][code]
+	return message, ok
+}
+
+// Resolve Picks the best registered language for an Accept-Language header | 根据 Accept-Language 请求头选择最合适的已注册语言
+// Tags are tried by descending q, each as an exact match and then by its primary subtag
+// ("zh-CN" matches "zh"); the fallback is returned when nothing matches.
+// 按q值从高到低尝试各语言标签，先精确匹配再匹配主标签（"zh-CN" 匹配 "zh"）；均不匹配时返回回退语言。
+func (c *MessageCatalog) Resolve(acceptLanguage string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, tag := range parseAcceptLanguage(acceptLanguage) {
+		if _, ok := c.messages[tag]; ok {
+			return tag
+		}
+		if i := strings.Index(tag, "-"); i > 0 {
+			if _, ok := c.messages[tag[:i]]; ok {
+				return tag[:i]
+			}
+		}
+	}
+	return c.fallback
+}
+
+// Localize Returns a copy of err with the message of lang, err itself is not modified |
+// 返回使用lang消息的err副本，不修改err本身
+func (c *MessageCatalog) Localize(err *SaTokenError, lang string) *SaTokenError {
+	message, ok := c.Message(lang, err.Code)
+	if !ok {
+		if message, ok = c.Message(c.getFallback(), err.Code); !ok {
+			return err
+		}
+	}
+	localized := *err
+	localized.Message = message
+	return &localized
+}
+
+// getFallback Gets the fallback language | 获取回退语言
+func (c *MessageCatalog) getFallback() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.fallback
+}
+
+// Global catalog | 全局消息目录
+var (
+	messageCatalogMu sync.RWMutex
+	messageCatalog   = NewMessageCatalog()
+)
+
+// SetMessageCatalog Sets the global message catalog, nil restores the built-in one | 设置全局消息目录，nil恢复内置目录
+func SetMessageCatalog(catalog *MessageCatalog) {
+	if catalog == nil {
+		catalog = NewMessageCatalog()
+	}
+	messageCatalogMu.Lock()
+	defer messageCatalogMu.Unlock()
+	messageCatalog = catalog
+}
+
+// GetMessageCatalog Gets the global message catalog | 获取全局消息目录
+func GetMessageCatalog() *MessageCatalog {
+	messageCatalogMu.RLock()
+	defer messageCatalogMu.RUnlock()
+	return messageCatalog
+}
+
+// LocalizeError Converts err and localizes it for the request's Accept-Language, ctx may be nil |
+// 转换err并按请求的 Accept-Language 本地化，ctx可为nil
+func LocalizeError(ctx RequestContext, err error) *SaTokenError {
+	catalog := GetMessageCatalog()
+	acceptLanguage := ""
+	if ctx != nil {
+		acceptLanguage = ctx.GetHeader(HeaderAcceptLanguage)
+	}
+	return catalog.Localize(ToSaTokenError(err), catalog.Resolve(acceptLanguage))
+}
+
+// parseAcceptLanguage Parses language tags ordered by descending q, skipping "*" and q=0 |
+// 解析按q值降序排列的语言标签，跳过 "*" 和 q=0
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = normalizeLang(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag: tag, q: q})
+		}
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
+
+// normalizeLang Lowercases a language tag and uses "-" as separator | 将语言标签转为小写并使用 "-" 分隔
+func normalizeLang(lang string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(lang)), "_", "-")
+}
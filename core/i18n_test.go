@@ -0,0 +1,52 @@
+package core
+
+import (
+	"testing"
+
+	"suwei.sa_token/core/manager"
+)
+
+func TestMessageCatalogResolve(t *testing.T) {
+	catalog := NewMessageCatalog().RegisterLanguage("zh_TW", map[int]string{CodeNotLogin: "未登入"})
+
+	cases := map[string]string{
+		"":                        LangEN,
+		"zh-CN,zh;q=0.9,en;q=0.8": LangZH,
+		"ZH-tw":                   "zh-tw",
+		"fr-FR, en;q=0.5":         LangEN,
+		"en;q=0.3, zh;q=0.7":      LangZH,
+		"de, *;q=0.1":             LangEN,
+		"zh;q=0, en-US":           LangEN,
+	}
+	for header, want := range cases {
+		if got := catalog.Resolve(header); got != want {
+			t.Errorf("Resolve(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestLocalizeError(t *testing.T) {
+	catalog := NewMessageCatalog().RegisterLanguage("ja", map[int]string{CodeNotLogin: "ログインしていません"})
+	SetMessageCatalog(catalog)
+	defer SetMessageCatalog(nil)
+
+	original := NewNotSafeError("pay")
+	zh := LocalizeError(&fakeContext{header: map[string]string{HeaderAcceptLanguage: "zh-CN"}}, original)
+	if zh.Message != "需要二级认证" || original.Message != "second-level authentication required" {
+		t.Errorf("zh: message = %q, original = %q", zh.Message, original.Message)
+	}
+
+	// Missing code in a registered language uses the fallback | 已注册语言缺少的错误码使用回退语言
+	ja := &fakeContext{header: map[string]string{HeaderAcceptLanguage: "ja"}}
+	if got := LocalizeError(ja, original).Message; got != "second-level authentication required" {
+		t.Errorf("ja fallback: message = %q", got)
+	}
+	if got := LocalizeError(ja, manager.ErrNotLogin).Message; got != "ログインしていません" {
+		t.Errorf("ja: message = %q", got)
+	}
+
+	// Unknown codes keep their message | 未知错误码保留原始消息
+	if got := LocalizeError(nil, NewError(499, "custom", nil)).Message; got != "custom" {
+		t.Errorf("unknown code: message = %q", got)
+	}
+}
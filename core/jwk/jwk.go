@@ -0,0 +1,452 @@
+package jwk
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// JSON Web Keys
+// JSON Web 密钥
+//
+// A KeySet holds the key JWTs are signed with and the keys they are verified with. Every key has
+// an ID written to the "kid" header, so verification picks the matching key. Rotate makes a new
+// key current and keeps the previous one for verification during a grace period, which should
+// cover the token timeout. Public keys are published as a JWKS document (RFC 7517) so other
+// services can verify tokens; HMAC keys are secret and never published.
+// KeySet 保存签名JWT的密钥以及验证JWT的密钥。每个密钥都有写入 "kid" 头的ID，验证时据此选择对应的密钥。
+// Rotate 将新密钥设为当前密钥，旧密钥在宽限期内仍可用于验证，宽限期应覆盖Token超时时间。公钥以JWKS
+// 文档（RFC 7517）发布，供其他服务验证Token；HMAC密钥是私密的，永远不会发布。
+//
+// Usage | 用法:
+//   key, _ := jwk.GenerateKey("2026-10", jwk.AlgES256)
+//   ks := jwk.NewKeySet()
+//   ks.SetSigningKey(key)
+//   cfg.SetJwtKeySet(ks)
+//   http.Handle("/.well-known/jwks.json", ks)       // publish public keys | 发布公钥
+//
+//   next, _ := jwk.GenerateKey("2026-11", jwk.AlgES256)
+//   ks.Rotate(next, 30*24*time.Hour)                // old key verifies for 30 days | 旧密钥继续验证30天
+
+// Signing algorithms | 签名算法
+const (
+	AlgHS256 = "HS256" // HMAC SHA-256, secret key | HMAC SHA-256，对称密钥
+	AlgRS256 = "RS256" // RSA PKCS#1 v1.5 SHA-256 | RSA PKCS#1 v1.5 SHA-256
+	AlgES256 = "ES256" // ECDSA P-256 SHA-256 | ECDSA P-256 SHA-256
+	AlgEdDSA = "EdDSA" // Ed25519 | Ed25519
+)
+
+// Constants for key sets | 密钥集常量
+const (
+	RSAKeyBits      = 2048               // Size of generated RSA keys | 生成的RSA密钥长度
+	JWKSContentType = "application/json" // Content type of the JWKS document | JWKS文档的内容类型
+	JWKSCacheMaxAge = 5 * time.Minute    // Cache lifetime of the JWKS document | JWKS文档的缓存时间
+	KeyUseSignature = "sig"              // Public key use | 公钥用途
+	headerCache     = "Cache-Control"    // Response cache header | 响应缓存头
+	headerType      = "Content-Type"     // Response content type header | 响应内容类型头
+	curveP256       = "P-256"            // JWK name of P-256 | P-256的JWK名称
+	curveEd25519    = "Ed25519"          // JWK name of Ed25519 | Ed25519的JWK名称
+	ktyRSA, ktyEC   = "RSA", "EC"        // JWK key types | JWK密钥类型
+	ktyOKP          = "OKP"              // JWK octet key pair type | JWK八位字节密钥对类型
+)
+
+// Error variables | 错误变量
+var (
+	ErrMissingKeyID         = fmt.Errorf("jwk: key id cannot be empty")
+	ErrUnsupportedKey       = fmt.Errorf("jwk: unsupported key type")
+	ErrUnsupportedAlgorithm = fmt.Errorf("jwk: unsupported algorithm")
+	ErrDuplicateKey         = fmt.Errorf("jwk: duplicate key id")
+	ErrKeyNotFound          = fmt.Errorf("jwk: key not found")
+	ErrNoSigningKey         = fmt.Errorf("jwk: no signing key")
+)
+
+// ============ Key | 密钥 ============
+
+// Key Signing or verification key with its ID and algorithm | 带ID和算法的签名或验证密钥
+type Key struct {
+	ID        string
+	Algorithm string
+
+	signKey   any       // Private key or HMAC secret, nil for verification-only keys | 私钥或HMAC密钥，仅验证密钥为nil
+	verifyKey any       // Public key or HMAC secret | 公钥或HMAC密钥
+	notAfter  time.Time // End of the rotation grace period, zero means none | 轮换宽限期结束时间，零值表示不限
+}
+
+// NewHMACKey Creates an HS256 key from a secret | 使用密钥创建HS256密钥
+func NewHMACKey(id string, secret []byte) (*Key, error) {
+	if id == "" {
+		return nil, ErrMissingKeyID
+	}
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("%w: empty hmac secret", ErrUnsupportedKey)
+	}
+	return &Key{ID: id, Algorithm: AlgHS256, signKey: secret, verifyKey: secret}, nil
+}
+
+// NewKey Creates a signing key, the algorithm follows the key type (RSA, ECDSA P-256, Ed25519) |
+// 创建签名密钥，算法由密钥类型决定（RSA、ECDSA P-256、Ed25519）
+func NewKey(id string, private crypto.PrivateKey) (*Key, error) {
+	if id == "" {
+		return nil, ErrMissingKeyID
+	}
+	switch k := private.(type) {
+	case *rsa.PrivateKey:
+		return &Key{ID: id, Algorithm: AlgRS256, signKey: k, verifyKey: &k.PublicKey}, nil
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%w: ecdsa curve %s", ErrUnsupportedKey, k.Curve.Params().Name)
+		}
+		return &Key{ID: id, Algorithm: AlgES256, signKey: k, verifyKey: &k.PublicKey}, nil
+	case ed25519.PrivateKey:
+		return &Key{ID: id, Algorithm: AlgEdDSA, signKey: k, verifyKey: k.Public()}, nil
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKey, private)
+	}
+}
+
+// NewPublicKey Creates a verification-only key | 创建仅用于验证的密钥
+func NewPublicKey(id string, public crypto.PublicKey) (*Key, error) {
+	if id == "" {
+		return nil, ErrMissingKeyID
+	}
+	switch k := public.(type) {
+	case *rsa.PublicKey:
+		return &Key{ID: id, Algorithm: AlgRS256, verifyKey: k}, nil
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%w: ecdsa curve %s", ErrUnsupportedKey, k.Curve.Params().Name)
+		}
+		return &Key{ID: id, Algorithm: AlgES256, verifyKey: k}, nil
+	case ed25519.PublicKey:
+		return &Key{ID: id, Algorithm: AlgEdDSA, verifyKey: k}, nil
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKey, public)
+	}
+}
+
+// GenerateKey Generates a new asymmetric signing key | 生成新的非对称签名密钥
+func GenerateKey(id string, algorithm string) (*Key, error) {
+	var (
+		private crypto.PrivateKey
+		err     error
+	)
+	switch algorithm {
+	case AlgRS256:
+		private, err = rsa.GenerateKey(rand.Reader, RSAKeyBits)
+	case AlgES256:
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, algorithm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate %s key: %w", algorithm, err)
+	}
+	return NewKey(id, private)
+}
+
+// SignKey Gets the private key or HMAC secret, nil for verification-only keys | 获取私钥或HMAC密钥，仅验证密钥返回nil
+func (k *Key) SignKey() any {
+	return k.signKey
+}
+
+// VerifyKey Gets the public key or HMAC secret | 获取公钥或HMAC密钥
+func (k *Key) VerifyKey() any {
+	return k.verifyKey
+}
+
+// CanSign Checks if the key can sign | 检查密钥是否可以签名
+func (k *Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+// IsPublic Checks if the key can be published, i.e. is asymmetric | 检查密钥是否可以发布（即非对称密钥）
+func (k *Key) IsPublic() bool {
+	return k.Algorithm != AlgHS256
+}
+
+// NotAfter Gets the end of the rotation grace period, zero if none | 获取轮换宽限期的结束时间，不限时为零值
+func (k *Key) NotAfter() time.Time {
+	return k.notAfter
+}
+
+// expired Checks if the grace period has ended | 检查宽限期是否已结束
+func (k *Key) expired(now time.Time) bool {
+	return !k.notAfter.IsZero() && !now.Before(k.notAfter)
+}
+
+// ============ Key Set | 密钥集 ============
+
+// KeySet Current signing key plus verification keys | 当前签名密钥及验证密钥
+type KeySet struct {
+	mu      sync.RWMutex
+	current string
+	keys    map[string]*Key
+}
+
+// NewKeySet Creates an empty key set | 创建空密钥集
+func NewKeySet() *KeySet {
+	return &KeySet{keys: make(map[string]*Key)}
+}
+
+// SetSigningKey Adds a key and signs new tokens with it, the previous signing key keeps verifying |
+// 添加密钥并用其签名新Token，之前的签名密钥继续用于验证
+func (ks *KeySet) SetSigningKey(key *Key) error {
+	return ks.Rotate(key, 0)
+}
+
+// Rotate Signs new tokens with key; the previous signing key verifies until grace elapses,
+// grace < 0 removes it at once and grace == 0 keeps it until Remove |
+// 使用新密钥签名新Token；之前的签名密钥在宽限期内继续验证，grace < 0 立即移除，grace == 0 保留至调用 Remove
+func (ks *KeySet) Rotate(key *Key, grace time.Duration) error {
+	if key == nil || !key.CanSign() {
+		return fmt.Errorf("%w: key cannot sign", ErrNoSigningKey)
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if _, exists := ks.keys[key.ID]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateKey, key.ID)
+	}
+	if previous, ok := ks.keys[ks.current]; ok {
+		switch {
+		case grace < 0:
+			delete(ks.keys, previous.ID)
+		case grace > 0:
+			previous.notAfter = time.Now().Add(grace)
+		}
+	}
+	ks.keys[key.ID] = key
+	ks.current = key.ID
+	return nil
+}
+
+// Add Adds a verification key, e.g. one published by another service | 添加验证密钥，例如其他服务发布的密钥
+func (ks *KeySet) Add(key *Key) error {
+	if key == nil || key.ID == "" {
+		return ErrMissingKeyID
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if _, exists := ks.keys[key.ID]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateKey, key.ID)
+	}
+	ks.keys[key.ID] = key
+	return nil
+}
+
+// Remove Removes a key, removing the signing key leaves the set unable to sign | 移除密钥，移除签名密钥后无法再签名
+func (ks *KeySet) Remove(id string) bool {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if _, exists := ks.keys[id]; !exists {
+		return false
+	}
+	delete(ks.keys, id)
+	if ks.current == id {
+		ks.current = ""
+	}
+	return true
+}
+
+// SigningKey Gets the key new tokens are signed with | 获取签名新Token的密钥
+func (ks *KeySet) SigningKey() (*Key, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key, ok := ks.keys[ks.current]
+	if !ok {
+		return nil, ErrNoSigningKey
+	}
+	return key, nil
+}
+
+// Lookup Gets a key by ID, keys past their grace period are not returned | 根据ID获取密钥，超过宽限期的密钥不会返回
+func (ks *KeySet) Lookup(id string) (*Key, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key, ok := ks.keys[id]
+	if !ok || key.expired(time.Now()) {
+		return nil, false
+	}
+	return key, true
+}
+
+// Keys Gets keys still valid for verification, signing key first | 获取仍可用于验证的密钥，签名密钥在前
+func (ks *KeySet) Keys() []*Key {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	now := time.Now()
+	keys := make([]*Key, 0, len(ks.keys))
+	for _, key := range ks.keys {
+		if !key.expired(now) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if (keys[i].ID == ks.current) != (keys[j].ID == ks.current) {
+			return keys[i].ID == ks.current
+		}
+		return keys[i].ID < keys[j].ID
+	})
+	return keys
+}
+
+// Len Gets the number of keys still valid for verification | 获取仍可用于验证的密钥数量
+func (ks *KeySet) Len() int {
+	return len(ks.Keys())
+}
+
+// ============ JWKS | JWKS ============
+
+// JWK Public key in JSON Web Key format | JSON Web Key 格式的公钥
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS JSON Web Key Set document | JSON Web Key Set 文档
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS Builds the document of public keys still valid for verification | 构建仍可用于验证的公钥文档
+func (ks *KeySet) JWKS() *JWKS {
+	doc := &JWKS{Keys: []JWK{}}
+	for _, key := range ks.Keys() {
+		if jwk, ok := toJWK(key); ok {
+			doc.Keys = append(doc.Keys, jwk)
+		}
+	}
+	return doc
+}
+
+// ServeHTTP Serves the JWKS document, e.g. at /.well-known/jwks.json | 提供JWKS文档，例如挂载在 /.well-known/jwks.json
+func (ks *KeySet) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := json.Marshal(ks.JWKS())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set(headerType, JWKSContentType)
+	w.Header().Set(headerCache, "public, max-age="+strconv.Itoa(int(JWKSCacheMaxAge.Seconds())))
+	w.Write(body)
+}
+
+// ParseJWKS Builds a verification-only key set from a JWKS document | 从JWKS文档构建仅用于验证的密钥集
+func ParseJWKS(data []byte) (*KeySet, error) {
+	var doc JWKS
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse jwks: %w", err)
+	}
+
+	ks := NewKeySet()
+	for _, jwk := range doc.Keys {
+		public, err := fromJWK(jwk)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", jwk.Kid, err)
+		}
+		key, err := NewPublicKey(jwk.Kid, public)
+		if err != nil {
+			return nil, err
+		}
+		if err := ks.Add(key); err != nil {
+			return nil, err
+		}
+	}
+	return ks, nil
+}
+
+// toJWK Converts a public key to JWK, HMAC keys are never published | 将公钥转换为JWK，HMAC密钥不会发布
+func toJWK(key *Key) (JWK, bool) {
+	jwk := JWK{Kid: key.ID, Use: KeyUseSignature, Alg: key.Algorithm}
+	switch k := key.verifyKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = ktyRSA
+		jwk.N = encode(k.N.Bytes())
+		jwk.E = encode(big.NewInt(int64(k.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		jwk.Kty, jwk.Crv = ktyEC, curveP256
+		jwk.X = encode(k.X.FillBytes(make([]byte, size)))
+		jwk.Y = encode(k.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.Kty, jwk.Crv = ktyOKP, curveEd25519
+		jwk.X = encode(k)
+	default:
+		return JWK{}, false
+	}
+	return jwk, true
+}
+
+// fromJWK Converts a JWK to a public key | 将JWK转换为公钥
+func fromJWK(jwk JWK) (crypto.PublicKey, error) {
+	switch {
+	case jwk.Kty == ktyRSA:
+		n, errN := decode(jwk.N)
+		e, errE := decode(jwk.E)
+		if errN != nil || errE != nil || len(n) == 0 || len(e) == 0 {
+			return nil, fmt.Errorf("%w: malformed rsa key", ErrUnsupportedKey)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case jwk.Kty == ktyEC && jwk.Crv == curveP256:
+		x, errX := decode(jwk.X)
+		y, errY := decode(jwk.Y)
+		if errX != nil || errY != nil {
+			return nil, fmt.Errorf("%w: malformed ec key", ErrUnsupportedKey)
+		}
+		public := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !public.Curve.IsOnCurve(public.X, public.Y) {
+			return nil, fmt.Errorf("%w: point not on curve", ErrUnsupportedKey)
+		}
+		return public, nil
+	case jwk.Kty == ktyOKP && jwk.Crv == curveEd25519:
+		x, err := decode(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: malformed ed25519 key", ErrUnsupportedKey)
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("%w: kty %s crv %s", ErrUnsupportedKey, jwk.Kty, jwk.Crv)
+	}
+}
+
+// encode Base64url without padding | 无填充的Base64url编码
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decode Base64url without padding | 无填充的Base64url解码
+func decode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
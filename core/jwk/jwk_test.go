@@ -0,0 +1,89 @@
+package jwk
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestKeySetRotate(t *testing.T) {
+	ks := NewKeySet()
+	first, _ := GenerateKey("first", AlgES256)
+	if err := ks.SetSigningKey(first); err != nil {
+		t.Fatalf("SetSigningKey() error = %v", err)
+	}
+
+	second, _ := GenerateKey("second", AlgES256)
+	if err := ks.Rotate(second, time.Hour); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if key, _ := ks.SigningKey(); key.ID != "second" {
+		t.Errorf("SigningKey() = %s, want second", key.ID)
+	}
+	if _, ok := ks.Lookup("first"); !ok {
+		t.Error("previous key should verify during the grace period")
+	}
+	if err := ks.Rotate(second, time.Hour); err == nil {
+		t.Error("Rotate() should reject a duplicate key id")
+	}
+
+	third, _ := GenerateKey("third", AlgES256)
+	ks.Rotate(third, -1)
+	if _, ok := ks.Lookup("second"); ok {
+		t.Error("negative grace should remove the previous key")
+	}
+
+	ks.keys["first"].notAfter = time.Now().Add(-time.Second)
+	if _, ok := ks.Lookup("first"); ok {
+		t.Error("key past its grace period should not verify")
+	}
+	if keys := ks.Keys(); len(keys) != 1 || keys[0].ID != "third" {
+		t.Errorf("Keys() = %v", keys)
+	}
+}
+
+func TestJWKSRoundTrip(t *testing.T) {
+	ks := NewKeySet()
+	for _, alg := range []string{AlgRS256, AlgES256, AlgEdDSA} {
+		key, err := GenerateKey(alg, alg)
+		if err != nil {
+			t.Fatalf("GenerateKey(%s) error = %v", alg, err)
+		}
+		ks.Rotate(key, 0)
+	}
+	secret, _ := NewHMACKey("hmac", []byte("secret"))
+	ks.Add(secret)
+
+	rec := httptest.NewRecorder()
+	ks.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get(headerType) != JWKSContentType {
+		t.Fatalf("ServeHTTP() code = %d, content type = %q", rec.Code, rec.Header().Get(headerType))
+	}
+
+	var doc JWKS
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid jwks: %v", err)
+	}
+	if len(doc.Keys) != 3 {
+		t.Fatalf("jwks should publish 3 public keys, got %d", len(doc.Keys))
+	}
+
+	parsed, err := ParseJWKS(rec.Body.Bytes())
+	if err != nil {
+		t.Fatalf("ParseJWKS() error = %v", err)
+	}
+	for _, alg := range []string{AlgRS256, AlgES256, AlgEdDSA} {
+		key, ok := parsed.Lookup(alg)
+		if !ok || key.Algorithm != alg || key.CanSign() {
+			t.Errorf("parsed key %s = %+v", alg, key)
+		}
+	}
+	if _, ok := parsed.Lookup("hmac"); ok {
+		t.Error("hmac keys must never be published")
+	}
+	if _, err := parsed.SigningKey(); err == nil {
+		t.Error("a parsed jwks cannot sign")
+	}
+}
@@ -40,6 +40,12 @@ const (
 	// EventRoleCheck fired when a role check is performed | 角色检查事件
 	EventRoleCheck Event = "roleCheck"
 
+	// EventBindingMismatch fired when a bound token is used from another client | 绑定的Token在其他客户端上使用事件
+	EventBindingMismatch Event = "bindingMismatch"
+
+	// EventRefreshTokenReuse fired when a spent refresh token is presented and its family is revoked | 出示已消费的刷新令牌并撤销其家族事件
+	EventRefreshTokenReuse Event = "refreshTokenReuse"
+
 	// EventAll is a wildcard event that matches all events | 通配符事件（匹配所有事件）
 	EventAll Event = "*"
 )
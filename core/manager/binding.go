@@ -0,0 +1,213 @@
+package manager
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+
+	"suwei.sa_token/core/config"
+	"suwei.sa_token/core/listener"
+)
+
+// Token Binding
+// Token绑定
+//
+// With Config.TokenBinding set, a token is bound at login to a fingerprint of the client: its
+// IP subnet, a hash of its User-Agent and a device ID it sends in a header. The fingerprint is
+// kept in token metadata, or as the "bind" claim of JWTs. SaTokenContext compares it on every
+// login check; a stolen token used from another client fires EventBindingMismatch and, by
+// Policy, is rejected with ErrTokenBindingMismatch, allowed only during second-level auth, or
+// allowed. Only attributes bound at login are compared.
+// 设置Config.TokenBinding后，Token在登录时绑定到客户端指纹：IP子网、User-Agent哈希以及客户端通过请求头
+// 发送的设备ID。指纹保存在Token元数据中，JWT则保存在 "bind" 声明中。SaTokenContext 在每次登录检查时
+// 进行比较；从其他客户端使用被盗Token会触发 EventBindingMismatch，并根据Policy以 ErrTokenBindingMismatch
+// 拒绝、仅在二级认证期间允许或直接允许。只比较登录时绑定的属性。
+//
+// Usage | 用法:
+//   cfg.SetTokenBinding(&config.TokenBindingConfig{
+//       IPv4Prefix: 24, IPv6Prefix: 64, UserAgent: true,
+//       Policy:     config.BindingPolicySafe,
+//   })
+//   err := saCtx.CheckLogin()   // errors.Is(err, manager.ErrTokenBindingMismatch)
+
+// ClaimBinding JWT claim holding the client fingerprint | 保存客户端指纹的JWT声明
+const ClaimBinding = "bind"
+
+// Binding fields reported in mismatch events | 不匹配事件中报告的绑定字段
+const (
+	BindingFieldIP        = "ip"
+	BindingFieldUserAgent = "userAgent"
+	BindingFieldDeviceID  = "deviceId"
+)
+
+// Error variables | 错误变量
+var (
+	ErrTokenBindingMismatch = fmt.Errorf("token is bound to another client")
+	ErrInvalidClientIP      = fmt.Errorf("client ip cannot be parsed for token binding")
+)
+
+// ClientFingerprint Client attributes a token is bound to | Token绑定的客户端属性
+type ClientFingerprint struct {
+	IPSubnet      string `json:"ip,omitempty"`       // Client subnet in CIDR notation | CIDR格式的客户端子网
+	UserAgentHash string `json:"ua,omitempty"`       // SHA-256 of the User-Agent | User-Agent的SHA-256
+	DeviceID      string `json:"deviceId,omitempty"` // Client-provided device ID | 客户端提供的设备ID
+
+	invalidIP bool // IP binding is on but the client IP did not parse | 已开启IP绑定但客户端IP无法解析
+}
+
+// NewClientFingerprint Builds the fingerprint of a client, nil when binding is off |
+// 构建客户端指纹，未开启绑定时返回nil
+// clientIP may carry a port or be a forwarded list, the first address counts. When IP binding is
+// on and it does not parse, logging in fails with ErrInvalidClientIP and checks against a bound
+// subnet fail. | clientIP 可以带端口或为转发列表，以第一个地址为准。开启IP绑定但无法解析时，登录以
+// ErrInvalidClientIP 失败，与已绑定子网的比较也会失败。
+func (m *Manager) NewClientFingerprint(clientIP, userAgent, deviceID string) *ClientFingerprint {
+	binding := m.config.TokenBinding
+	if binding == nil {
+		return nil
+	}
+
+	fp := &ClientFingerprint{}
+	if binding.IPv4Prefix > 0 || binding.IPv6Prefix > 0 {
+		ip := parseClientIP(clientIP)
+		switch {
+		case ip == nil:
+			fp.invalidIP = true
+		case ip.To4() != nil:
+			fp.IPSubnet = subnet(ip.To4(), binding.IPv4Prefix, 8*net.IPv4len)
+		default:
+			fp.IPSubnet = subnet(ip, binding.IPv6Prefix, 8*net.IPv6len)
+		}
+	}
+	if binding.UserAgent {
+		sum := sha256.Sum256([]byte(userAgent))
+		fp.UserAgentHash = hex.EncodeToString(sum[:])
+	}
+	if binding.DeviceIDHeader != "" {
+		fp.DeviceID = deviceID
+	}
+	return fp
+}
+
+// parseClientIP Parses "ip", "ip:port", "[ipv6]:port" or the first hop of a forwarded list, nil if invalid |
+// 解析 "ip"、"ip:port"、"[ipv6]:port" 或转发列表的第一跳，无效时返回nil
+func parseClientIP(clientIP string) net.IP {
+	addr, _, _ := strings.Cut(clientIP, ",")
+	addr = strings.TrimSpace(addr)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return net.ParseIP(strings.Trim(addr, "[]"))
+}
+
+// subnet Formats the prefix of ip in CIDR notation, empty when prefix is 0, the full address when prefix is too long |
+// 以CIDR格式表示ip的前缀，prefix为0时为空，prefix过长时为完整地址
+func subnet(ip net.IP, prefix, bits int) string {
+	if prefix <= 0 {
+		return ""
+	}
+	mask := net.CIDRMask(prefix, bits)
+	if mask == nil {
+		mask = net.CIDRMask(bits, bits)
+	}
+	return (&net.IPNet{IP: ip.Mask(mask), Mask: mask}).String()
+}
+
+// mismatch Gets the bound fields that differ in other | 获取other中与绑定值不同的字段
+func (fp *ClientFingerprint) mismatch(other *ClientFingerprint) []string {
+	if other == nil {
+		other = &ClientFingerprint{}
+	}
+	var fields []string
+	if fp.IPSubnet != "" && fp.IPSubnet != other.IPSubnet {
+		fields = append(fields, BindingFieldIP)
+	}
+	if fp.UserAgentHash != "" && fp.UserAgentHash != other.UserAgentHash {
+		fields = append(fields, BindingFieldUserAgent)
+	}
+	if fp.DeviceID != "" && fp.DeviceID != other.DeviceID {
+		fields = append(fields, BindingFieldDeviceID)
+	}
+	return fields
+}
+
+// CheckBinding Compares the client with the fingerprint bound at login and applies the policy |
+// 将客户端与登录时绑定的指纹进行比较并执行策略
+func (m *Manager) CheckBinding(tokenValue string, client *ClientFingerprint) error {
+	binding := m.config.TokenBinding
+	if binding == nil {
+		return nil
+	}
+	bound := m.getBinding(tokenValue)
+	if bound == nil {
+		return nil
+	}
+	fields := bound.mismatch(client)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	info, _ := m.getTokenInfo(tokenValue)
+	if info == nil {
+		info = &TokenInfo{}
+	}
+	m.triggerBindingMismatch(info, tokenValue, fields)
+
+	switch binding.Policy {
+	case config.BindingPolicyEvent:
+		return nil
+	case config.BindingPolicySafe:
+		service := binding.SafeService
+		if service == "" {
+			service = config.DefaultBindingSafe
+		}
+		if m.IsSafe(tokenValue, service) {
+			return nil
+		}
+		return fmt.Errorf("%w: %w", ErrTokenBindingMismatch, ErrNotSafe)
+	default:
+		return ErrTokenBindingMismatch
+	}
+}
+
+// getBinding Gets the fingerprint bound at login, nil if none | 获取登录时绑定的指纹，未绑定时返回nil
+func (m *Manager) getBinding(tokenValue string) *ClientFingerprint {
+	if !m.isClaimsMode() {
+		if meta := m.getTokenMeta(tokenValue); meta != nil {
+			return meta.Binding
+		}
+		return nil
+	}
+
+	claims, err := m.generator.ParseJWT(tokenValue)
+	if err != nil || claims[ClaimBinding] == nil {
+		return nil
+	}
+	data, err := json.Marshal(claims[ClaimBinding])
+	if err != nil {
+		return nil
+	}
+	var fp ClientFingerprint
+	if err := json.Unmarshal(data, &fp); err != nil {
+		return nil
+	}
+	return &fp
+}
+
+// triggerBindingMismatch Fires EventBindingMismatch with the differing fields | 触发携带不匹配字段的 EventBindingMismatch
+func (m *Manager) triggerBindingMismatch(info *TokenInfo, tokenValue string, fields []string) {
+	event := listener.EventBindingMismatch
+	if !m.eventManager.HasListeners(event) && !m.eventManager.HasListeners(listener.EventAll) {
+		return
+	}
+	m.eventManager.Trigger(&listener.EventData{
+		Event:   event,
+		LoginID: info.LoginID,
+		Device:  info.Device,
+		Token:   m.TokenID(tokenValue),
+		Extra:   map[string]any{"fields": fields},
+	})
+}
@@ -0,0 +1,102 @@
+package manager
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"suwei.sa_token/core/config"
+	"suwei.sa_token/core/listener"
+)
+
+func TestTokenBinding(t *testing.T) {
+	binding := &config.TokenBindingConfig{IPv4Prefix: 24, UserAgent: true, DeviceIDHeader: "X-Device-Id"}
+	mgr := newTestManager(config.DefaultConfig().SetTokenBinding(binding))
+	var events []*listener.EventData
+	mgr.GetEventManager().RegisterFuncWithConfig(listener.EventBindingMismatch, func(data *listener.EventData) {
+		events = append(events, data)
+	}, listener.ListenerConfig{})
+
+	tokenValue, err := mgr.LoginWithOptions("1001", &LoginOptions{Fingerprint: mgr.NewClientFingerprint("10.0.0.7", "Firefox", "d1")})
+	if err != nil {
+		t.Fatalf("LoginWithOptions() error = %v", err)
+	}
+
+	// Same subnet, user agent and device pass | 相同子网、User-Agent和设备通过
+	if err := mgr.CheckBinding(tokenValue, mgr.NewClientFingerprint("10.0.0.99", "Firefox", "d1")); err != nil {
+		t.Errorf("CheckBinding() from the bound client error = %v", err)
+	}
+
+	// Reject: another subnet or user agent fails with a distinct error | 拒绝：其他子网或User-Agent以专用错误失败
+	stolen := mgr.NewClientFingerprint("192.168.1.5", "curl", "d1")
+	if err := mgr.CheckBinding(tokenValue, stolen); !errors.Is(err, ErrTokenBindingMismatch) {
+		t.Errorf("CheckBinding() from another client error = %v", err)
+	}
+	if len(events) == 0 || events[0].LoginID != "1001" {
+		t.Fatalf("mismatch events = %v", events)
+	}
+	if fields := events[0].Extra["fields"].([]string); len(fields) != 2 || fields[0] != BindingFieldIP || fields[1] != BindingFieldUserAgent {
+		t.Errorf("mismatch fields = %v", fields)
+	}
+	if !mgr.IsLogin(tokenValue) {
+		t.Error("a rejected client should not log the owner out")
+	}
+
+	// Safe: a mismatch needs second-level auth | 二级认证：不匹配时需要二级认证
+	binding.Policy = config.BindingPolicySafe
+	if err := mgr.CheckBinding(tokenValue, stolen); !errors.Is(err, ErrTokenBindingMismatch) || !errors.Is(err, ErrNotSafe) {
+		t.Errorf("safe policy CheckBinding() error = %v", err)
+	}
+	if err := mgr.OpenSafe(tokenValue, config.DefaultBindingSafe, time.Minute); err != nil {
+		t.Fatalf("OpenSafe() error = %v", err)
+	}
+	if err := mgr.CheckBinding(tokenValue, stolen); err != nil {
+		t.Errorf("safe policy after re-auth error = %v", err)
+	}
+
+	// Event: only reported | 事件：仅上报
+	binding.Policy = config.BindingPolicyEvent
+	events = nil
+	if err := mgr.CheckBinding(tokenValue, mgr.NewClientFingerprint("10.0.0.7", "Firefox", "d2")); err != nil || len(events) != 1 {
+		t.Errorf("event policy CheckBinding() error = %v, events = %d", err, len(events))
+	}
+
+	// Addresses with a port or a forwarded list use the client address | 带端口的地址或转发列表使用客户端地址
+	for _, ip := range []string{"10.0.0.7:51234", "10.0.0.8, 172.16.0.1", " 10.0.0.9 "} {
+		if fp := mgr.NewClientFingerprint(ip, "Firefox", "d1"); fp.IPSubnet != "10.0.0.0/24" {
+			t.Errorf("NewClientFingerprint(%q) subnet = %q", ip, fp.IPSubnet)
+		}
+	}
+	if fp := mgr.NewClientFingerprint("[2001:db8::1]:443", "", ""); fp.IPSubnet != "" || fp.invalidIP {
+		t.Errorf("ipv6 without an IPv6Prefix = %+v", fp)
+	}
+
+	// Fail closed: an unparseable address cannot log in or pass a bound check | 失败即拒绝：无法解析的地址不能登录或通过绑定检查
+	binding.Policy = config.BindingPolicyReject
+	unknown := mgr.NewClientFingerprint("unknown", "Firefox", "d1")
+	if _, err := mgr.LoginWithOptions("1001", &LoginOptions{Fingerprint: unknown}); !errors.Is(err, ErrInvalidClientIP) {
+		t.Errorf("LoginWithOptions() with an unparseable ip error = %v", err)
+	}
+	if err := mgr.CheckBinding(tokenValue, unknown); !errors.Is(err, ErrTokenBindingMismatch) {
+		t.Errorf("CheckBinding() with an unparseable ip error = %v", err)
+	}
+
+	// A prefix longer than the address binds the full address | 前缀长于地址时绑定完整地址
+	wide := newTestManager(config.DefaultConfig().SetTokenBinding(&config.TokenBindingConfig{IPv4Prefix: 40, IPv6Prefix: 200}))
+	if fp := wide.NewClientFingerprint("10.0.0.7", "", ""); fp.IPSubnet != "10.0.0.7/32" {
+		t.Errorf("IPv4 subnet with prefix 40 = %q", fp.IPSubnet)
+	}
+	if fp := wide.NewClientFingerprint("[2001:db8::1]:443", "", ""); fp.IPSubnet != "2001:db8::1/128" {
+		t.Errorf("IPv6 subnet with prefix 200 = %q", fp.IPSubnet)
+	}
+
+	// JWT: the fingerprint travels as a claim | JWT：指纹以声明形式携带
+	mgr = newTestManager(jwtConfig(config.JwtModeStateless).SetTokenBinding(&config.TokenBindingConfig{UserAgent: true}))
+	tokenValue, _ = mgr.LoginWithOptions("1002", &LoginOptions{Fingerprint: mgr.NewClientFingerprint("", "Firefox", "")})
+	if err := mgr.CheckBinding(tokenValue, mgr.NewClientFingerprint("", "Firefox", "")); err != nil {
+		t.Errorf("jwt CheckBinding() from the bound client error = %v", err)
+	}
+	if err := mgr.CheckBinding(tokenValue, mgr.NewClientFingerprint("", "curl", "")); !errors.Is(err, ErrTokenBindingMismatch) {
+		t.Errorf("jwt CheckBinding() from another client error = %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package manager
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+)
+
+// CSRF Synchronizer Tokens
+// CSRF同步令牌
+//
+// Browsers attach cookies to cross-site requests, so a token read from a cookie does not prove
+// the request came from our own pages. Every login gets a random CSRF token kept next to the
+// token with the same TTL (scoped to the Token-Session); in stateless JWT mode it is derived
+// from the token with JwtSecretKey, or TokenHashPepper when tokens are signed by a key set. Unsafe requests authenticated by cookie must echo
+// it in a header or form field, which a cross-site page cannot read.
+// 浏览器会在跨站请求中附带Cookie，因此从Cookie读取的Token无法证明请求来自我们自己的页面。每次登录都会
+// 获得一个与Token一起保存且TTL相同的随机CSRF令牌（作用域为Token-Session）；无状态JWT模式下改为使用
+// JwtSecretKey从Token派生，使用密钥集签名时改用TokenHashPepper。通过Cookie认证的非安全请求必须在请求头或表单字段中回传该令牌，而跨站页面无法读取它。
+//
+// Usage | 用法:
+//   csrfToken, _ := manager.GetCSRFToken(token)       // handed to the page | 交给页面
+//   err := manager.CheckCSRFToken(token, csrfToken)   // POST/PUT/DELETE... | 非安全请求时
+
+// CSRFKeyPrefix Storage key prefix of CSRF tokens | CSRF令牌的存储键前缀
+const CSRFKeyPrefix = "csrf:"
+
+// ErrInvalidCSRFToken CSRF token is missing or does not match the session | CSRF令牌缺失或与会话不匹配
+var ErrInvalidCSRFToken = fmt.Errorf("invalid or missing csrf token")
+
+// ErrCSRFSecretMissing Stateless mode has no symmetric secret to derive CSRF tokens from | 无状态模式下没有可派生CSRF令牌的对称密钥
+var ErrCSRFSecretMissing = fmt.Errorf("stateless csrf tokens need JwtSecretKey or TokenHashPepper")
+
+// GetCSRFToken Gets the CSRF token of a logged-in token, issuing one if missing | 获取已登录Token的CSRF令牌，不存在时签发
+func (m *Manager) GetCSRFToken(tokenValue string) (string, error) {
+	if err := m.CheckLogin(tokenValue); err != nil {
+		return "", err
+	}
+
+	if m.isStateless() {
+		return m.deriveCSRFToken(tokenValue)
+	}
+
+	key := m.getCSRFKey(tokenValue)
+	if value, err := m.storage.Get(key); err == nil && value != nil {
+		if csrfToken, ok := assertString(value); ok && csrfToken != "" {
+			return csrfToken, nil
+		}
+	}
+
+	csrfToken, err := m.nonceManager.Random()
+	if err != nil {
+		return "", err
+	}
+	if err := m.storage.Set(key, csrfToken, m.tokenExpiration(tokenValue)); err != nil {
+		return "", fmt.Errorf("failed to save csrf token: %w", err)
+	}
+	return csrfToken, nil
+}
+
+// CheckCSRFToken Checks a submitted CSRF token against the one of the token | 校验提交的CSRF令牌是否与Token的一致
+func (m *Manager) CheckCSRFToken(tokenValue, csrfToken string) error {
+	if tokenValue == "" || csrfToken == "" {
+		return ErrInvalidCSRFToken
+	}
+
+	expected, ok := m.expectedCSRFToken(tokenValue)
+	if !ok || subtle.ConstantTimeCompare([]byte(expected), []byte(csrfToken)) != 1 {
+		return ErrInvalidCSRFToken
+	}
+	return nil
+}
+
+// expectedCSRFToken Gets the CSRF token a request must carry | 获取请求必须携带的CSRF令牌
+func (m *Manager) expectedCSRFToken(tokenValue string) (string, bool) {
+	if m.isStateless() {
+		expected, err := m.deriveCSRFToken(tokenValue)
+		return expected, err == nil
+	}
+	value, err := m.storage.Get(m.getCSRFKey(tokenValue))
+	if err != nil || value == nil {
+		return "", false
+	}
+	expected, ok := assertString(value)
+	return expected, ok && expected != ""
+}
+
+// deriveCSRFToken Derives the CSRF token from the token when nothing is stored | 不使用存储时从Token派生CSRF令牌
+func (m *Manager) deriveCSRFToken(tokenValue string) (string, error) {
+	secret := m.config.JwtSecretKey
+	if secret == "" {
+		secret = m.config.TokenHashPepper
+	}
+	if secret == "" {
+		return "", ErrCSRFSecretMissing
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(CSRFKeyPrefix + tokenValue))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// getCSRFKey Gets storage key of CSRF token | 获取CSRF令牌的存储键
+func (m *Manager) getCSRFKey(tokenValue string) string {
+	return m.tokenIDKey(CSRFKeyPrefix, m.TokenID(tokenValue))
+}
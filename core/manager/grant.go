@@ -0,0 +1,228 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Time-limited Permission and Role Grants
+// 限时权限和角色授权
+//
+// Grants are kept in the account Session next to the static lists. Expired grants are
+// ignored by HasPermission/HasRole at check time, no cleanup job is needed. Revoking marks
+// a grant instead of deleting it, so expired and revoked grants stay listable for audits
+// until PurgeExpiredGrants is called.
+// 授权与静态列表一起保存在账号Session中。过期授权在检查时被忽略，无需清理任务。撤销只标记授权而不删除，
+// 因此过期和已撤销的授权在调用PurgeExpiredGrants之前仍可用于审计查询。
+//
+// Usage | 用法:
+//   manager.GrantPermission("1000", "billing:refund", 2*time.Hour)
+//   manager.HasPermission("1000", "billing:refund") // true for 2 hours | 2小时内为true
+//   grants, _ := manager.ListPermissionGrants("1000")
+
+// Session keys for grants | 授权的Session键
+const (
+	SessionKeyPermissionGrants = "permissionGrants"
+	SessionKeyRoleGrants       = "roleGrants"
+)
+
+// timeNow Current time of grant checks, replaced in tests | 授权检查使用的当前时间，测试中可替换
+var timeNow = time.Now
+
+// TimedGrant Time-limited permission or role grant | 限时权限或角色授权
+type TimedGrant struct {
+	Value      string `json:"value"`                // Permission or role | 权限或角色
+	GrantTime  int64  `json:"grantTime"`            // Grant timestamp | 授权时间戳
+	ExpireTime int64  `json:"expireTime"`           // Expiration timestamp | 过期时间戳
+	RevokeTime int64  `json:"revokeTime,omitempty"` // Revocation timestamp, 0 if not revoked | 撤销时间戳，未撤销为0
+}
+
+// IsExpired Checks if grant has expired | 检查授权是否已过期
+func (g *TimedGrant) IsExpired() bool {
+	return timeNow().Unix() >= g.ExpireTime
+}
+
+// IsRevoked Checks if grant was revoked before expiring | 检查授权是否在过期前被撤销
+func (g *TimedGrant) IsRevoked() bool {
+	return g.RevokeTime > 0
+}
+
+// IsActive Checks if grant is neither expired nor revoked | 检查授权是否既未过期也未撤销
+func (g *TimedGrant) IsActive() bool {
+	return !g.IsExpired() && !g.IsRevoked()
+}
+
+// RemainingTime Gets remaining time, 0 if inactive | 获取剩余时间，失效返回0
+func (g *TimedGrant) RemainingTime() time.Duration {
+	if !g.IsActive() {
+		return 0
+	}
+	return time.Unix(g.ExpireTime, 0).Sub(timeNow())
+}
+
+// ============ Permission Grants | 权限授权 ============
+
+// GrantPermission Grants a permission for duration | 限时授予权限
+func (m *Manager) GrantPermission(loginID string, perm string, duration time.Duration) error {
+	return m.addGrant(loginID, SessionKeyPermissionGrants, perm, duration)
+}
+
+// RevokePermissionGrant Revokes a time-limited permission | 撤销限时权限
+func (m *Manager) RevokePermissionGrant(loginID string, perm string) error {
+	return m.revokeGrant(loginID, SessionKeyPermissionGrants, perm)
+}
+
+// ListPermissionGrants Lists active, expired and revoked permission grants | 列出有效、已过期和已撤销的权限授权
+func (m *Manager) ListPermissionGrants(loginID string) ([]TimedGrant, error) {
+	return m.getGrants(loginID, SessionKeyPermissionGrants)
+}
+
+// ============ Role Grants | 角色授权 ============
+
+// GrantRole Grants a role for duration | 限时授予角色
+func (m *Manager) GrantRole(loginID string, role string, duration time.Duration) error {
+	return m.addGrant(loginID, SessionKeyRoleGrants, role, duration)
+}
+
+// RevokeRoleGrant Revokes a time-limited role | 撤销限时角色
+func (m *Manager) RevokeRoleGrant(loginID string, role string) error {
+	return m.revokeGrant(loginID, SessionKeyRoleGrants, role)
+}
+
+// ListRoleGrants Lists active, expired and revoked role grants | 列出有效、已过期和已撤销的角色授权
+func (m *Manager) ListRoleGrants(loginID string) ([]TimedGrant, error) {
+	return m.getGrants(loginID, SessionKeyRoleGrants)
+}
+
+// PurgeExpiredGrants Removes expired and revoked permission and role grants | 清除已过期和已撤销的权限和角色授权
+func (m *Manager) PurgeExpiredGrants(loginID string) error {
+	sess, err := m.GetSession(loginID)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range []string{SessionKeyPermissionGrants, SessionKeyRoleGrants} {
+		grants := decodeGrants(sess, key)
+		active := make([]TimedGrant, 0, len(grants))
+		for _, g := range grants {
+			if g.IsActive() {
+				active = append(active, g)
+			}
+		}
+		if len(active) == len(grants) {
+			continue
+		}
+		if err := sess.Set(key, active); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ============ Internal Methods | 内部方法 ============
+
+// addGrant Adds or replaces a grant | 添加或替换授权
+func (m *Manager) addGrant(loginID, key, value string, duration time.Duration) error {
+	if value == "" {
+		return fmt.Errorf("grant value cannot be empty")
+	}
+	if duration <= 0 {
+		return fmt.Errorf("grant duration must be positive, got: %v", duration)
+	}
+
+	sess, err := m.GetSession(loginID)
+	if err != nil {
+		return err
+	}
+
+	now := timeNow()
+	grant := TimedGrant{
+		Value:      value,
+		GrantTime:  now.Unix(),
+		ExpireTime: now.Add(duration).Unix(),
+	}
+
+	grants := decodeGrants(sess, key)
+	replaced := false
+	for i := range grants {
+		// Replace an active grant, keep expired ones for audit | 替换有效授权，保留过期授权用于审计
+		if grants[i].Value == value && grants[i].IsActive() {
+			grants[i] = grant
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		grants = append(grants, grant)
+	}
+
+	return sess.Set(key, grants)
+}
+
+// revokeGrant Marks the active grant for value revoked, expired ones are left as they are |
+// 将指定值的有效授权标记为已撤销，已过期的授权保持不变
+func (m *Manager) revokeGrant(loginID, key, value string) error {
+	sess, err := m.GetSession(loginID)
+	if err != nil {
+		return err
+	}
+
+	grants := decodeGrants(sess, key)
+	revoked := false
+	for i := range grants {
+		if grants[i].Value == value && grants[i].IsActive() {
+			grants[i].RevokeTime = timeNow().Unix()
+			revoked = true
+		}
+	}
+	if !revoked {
+		return nil
+	}
+	return sess.Set(key, grants)
+}
+
+// getGrants Gets all grants under key | 获取指定键下的所有授权
+func (m *Manager) getGrants(loginID, key string) ([]TimedGrant, error) {
+	sess, err := m.GetSession(loginID)
+	if err != nil {
+		return nil, err
+	}
+	return decodeGrants(sess, key), nil
+}
+
+// getActiveGrantValues Gets values of active grants | 获取有效授权的值
+func (m *Manager) getActiveGrantValues(loginID, key string) []string {
+	grants, err := m.getGrants(loginID, key)
+	if err != nil {
+		return nil
+	}
+	values := make([]string, 0, len(grants))
+	for _, g := range grants {
+		if g.IsActive() {
+			values = append(values, g.Value)
+		}
+	}
+	return values
+}
+
+// decodeGrants Decodes grants from session (typed after Set, generic after Load) | 从Session解码授权（Set后为具体类型，Load后为通用类型）
+func decodeGrants(sess interface{ Get(string) (any, bool) }, key string) []TimedGrant {
+	value, exists := sess.Get(key)
+	if !exists || value == nil {
+		return []TimedGrant{}
+	}
+	if grants, ok := value.([]TimedGrant); ok {
+		return append([]TimedGrant(nil), grants...)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return []TimedGrant{}
+	}
+	var grants []TimedGrant
+	if err := json.Unmarshal(data, &grants); err != nil {
+		return []TimedGrant{}
+	}
+	return grants
+}
@@ -0,0 +1,125 @@
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"suwei.sa_token/core/config"
+	"suwei.sa_token/core/token"
+)
+
+// advanceClock Moves the grant clock forward by d until the test ends | 在测试结束前将授权时钟前移d
+func advanceClock(t *testing.T, d time.Duration) {
+	t.Helper()
+	prev := timeNow
+	timeNow = func() time.Time { return prev().Add(d) }
+	t.Cleanup(func() { timeNow = prev })
+}
+
+func TestGrantExpiry(t *testing.T) {
+	mgr := newTestManager(config.DefaultConfig())
+	_ = mgr.SetPermissions("1001", []string{"user:read"})
+
+	if err := mgr.GrantPermission("1001", "billing:refund", time.Hour); err != nil {
+		t.Fatalf("GrantPermission() error = %v", err)
+	}
+	if err := mgr.GrantRole("1001", "auditor", 30*time.Minute); err != nil {
+		t.Fatalf("GrantRole() error = %v", err)
+	}
+	if !mgr.HasPermission("1001", "billing:refund") || !mgr.HasRole("1001", "auditor") {
+		t.Fatal("active grants should apply")
+	}
+
+	// The role expires first | 角色先过期
+	advanceClock(t, 45*time.Minute)
+	if mgr.HasRole("1001", "auditor") {
+		t.Error("expired role grant should not apply")
+	}
+	if !mgr.HasPermission("1001", "billing:refund") {
+		t.Error("permission grant should still apply")
+	}
+
+	advanceClock(t, time.Hour)
+	if mgr.HasPermission("1001", "billing:refund") || !mgr.HasPermission("1001", "user:read") {
+		t.Error("expired permission grant should not apply, static permissions should")
+	}
+
+	// Expired grants stay listable | 过期授权仍可查询
+	grants, _ := mgr.ListPermissionGrants("1001")
+	if len(grants) != 1 || !grants[0].IsExpired() || grants[0].RemainingTime() != 0 {
+		t.Errorf("ListPermissionGrants() = %+v", grants)
+	}
+	if roles, _ := mgr.ListRoleGrants("1001"); len(roles) != 1 || roles[0].IsActive() {
+		t.Errorf("ListRoleGrants() = %+v", roles)
+	}
+
+	// Re-granting keeps the expired record | 重新授权保留过期记录
+	_ = mgr.GrantPermission("1001", "billing:refund", time.Hour)
+	if grants, _ := mgr.ListPermissionGrants("1001"); len(grants) != 2 {
+		t.Errorf("ListPermissionGrants() after re-grant = %+v", grants)
+	}
+
+	if err := mgr.PurgeExpiredGrants("1001"); err != nil {
+		t.Fatalf("PurgeExpiredGrants() error = %v", err)
+	}
+	grants, _ = mgr.ListPermissionGrants("1001")
+	if len(grants) != 1 || !grants[0].IsActive() {
+		t.Errorf("ListPermissionGrants() after purge = %+v", grants)
+	}
+	if roles, _ := mgr.ListRoleGrants("1001"); len(roles) != 0 {
+		t.Errorf("ListRoleGrants() after purge = %+v", roles)
+	}
+}
+
+func TestGrantRevoke(t *testing.T) {
+	mgr := newTestManager(config.DefaultConfig())
+	_ = mgr.GrantPermission("1001", "billing:refund", time.Hour)
+	advanceClock(t, 2*time.Hour)
+	_ = mgr.GrantPermission("1001", "billing:refund", time.Hour)
+
+	if err := mgr.RevokePermissionGrant("1001", "billing:refund"); err != nil {
+		t.Fatalf("RevokePermissionGrant() error = %v", err)
+	}
+	if mgr.HasPermission("1001", "billing:refund") {
+		t.Error("revoked grant should not apply")
+	}
+
+	// The revoked grant is marked, the expired one left as it was | 撤销的授权被标记，过期授权保持不变
+	grants, _ := mgr.ListPermissionGrants("1001")
+	if len(grants) != 2 || grants[0].IsRevoked() || !grants[1].IsRevoked() || grants[1].IsExpired() {
+		t.Errorf("ListPermissionGrants() = %+v", grants)
+	}
+
+	_ = mgr.PurgeExpiredGrants("1001")
+	if grants, _ := mgr.ListPermissionGrants("1001"); len(grants) != 0 {
+		t.Errorf("ListPermissionGrants() after purge = %+v", grants)
+	}
+}
+
+func TestGrantJwtMixin(t *testing.T) {
+	mgr := newTestManager(jwtConfig(config.JwtModeMixin))
+	_ = mgr.SetPermissions("1001", []string{"user:read"})
+	_ = mgr.GrantPermission("1001", "billing:refund", time.Hour)
+	_ = mgr.GrantRole("1001", "auditor", time.Hour)
+	tokenValue, _ := mgr.Login("1001")
+
+	// Timed grants stay out of the claims | 限时授权不写入声明
+	claims, err := mgr.parseJWTClaims(tokenValue)
+	if err != nil {
+		t.Fatalf("parseJWTClaims() error = %v", err)
+	}
+	if perms := mgr.toStringSlice(claims[token.ClaimPermissions]); len(perms) != 1 || perms[0] != "user:read" {
+		t.Errorf("permission claims = %v", perms)
+	}
+
+	if !mgr.HasPermissionByToken(tokenValue, "billing:refund") || !mgr.HasRoleByToken(tokenValue, "auditor") {
+		t.Error("active grants should apply to the token")
+	}
+	advanceClock(t, 2*time.Hour)
+	if mgr.HasPermissionByToken(tokenValue, "billing:refund") || mgr.HasRoleByToken(tokenValue, "auditor") {
+		t.Error("expired grants should not apply to a token issued before")
+	}
+	if !mgr.HasPermissionByToken(tokenValue, "user:read") {
+		t.Error("static permission claims should still apply")
+	}
+}
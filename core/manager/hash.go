@@ -0,0 +1,92 @@
+package manager
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+
+	"suwei.sa_token/core/security"
+)
+
+// Hashed Token Storage
+// Token哈希存储
+//
+// With Config.TokenHashPepper set, storage never sees a token value: every key and record uses
+// the token ID, an HMAC-SHA256 of the token keyed with the pepper, so a Redis dump or KEYS output
+// cannot be replayed as a login. The raw value only exists on the client.
+// 设置Config.TokenHashPepper后，存储中不会出现Token值：所有键和记录都使用Token ID，即以该密钥计算的
+// Token的HMAC-SHA256，因此Redis转储或KEYS输出无法被重放用于登录。原始值只存在于客户端。
+//
+// Calls taking a token value hash it. Calls returning tokens read from storage (GetTokenValue,
+// GetTokenValueListByLoginID) and event data return token IDs. Kickout and Logout by login ID
+// work on IDs directly. Enabling or changing the pepper logs everybody out.
+// 接收Token值的调用会对其进行哈希。返回从存储读取的Token的调用（GetTokenValue、
+// GetTokenValueListByLoginID）以及事件数据返回的是Token ID。按登录ID踢下线和登出直接使用ID。
+// 启用或修改密钥会使所有用户退出登录。
+
+// TokenID Gets the form of a token kept in storage: its keyed hash, or the value itself when
+// hashing is off | 获取Token在存储中的形式：其带密钥的哈希，未开启哈希时为原值
+func (m *Manager) TokenID(tokenValue string) string {
+	return security.TokenID(m.config.TokenHashPepper, tokenValue)
+}
+
+// IsTokenHashed Checks if storage keeps token IDs instead of values | 检查存储是否保存Token ID而非Token值
+func (m *Manager) IsTokenHashed() bool {
+	return m.config.TokenHashPepper != ""
+}
+
+// tokenIDKey Builds the storage key of a token ID | 构建Token ID的存储键
+func (m *Manager) tokenIDKey(keyPrefix, tokenID string) string {
+	return m.prefix + keyPrefix + tokenID
+}
+
+// sealToken Encrypts a token for records that must give it back, e.g. tickets | 加密需要原样取回的Token，例如票据
+func (m *Manager) sealToken(tokenValue string) (string, error) {
+	if !m.IsTokenHashed() {
+		return tokenValue, nil
+	}
+	aead, err := m.tokenCipher()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(tokenValue), nil)), nil
+}
+
+// openToken Decrypts a token sealed by sealToken | 解密由sealToken加密的Token
+func (m *Manager) openToken(sealed string) (string, bool) {
+	if !m.IsTokenHashed() {
+		return sealed, true
+	}
+	aead, err := m.tokenCipher()
+	if err != nil {
+		return "", false
+	}
+	data, err := base64.RawURLEncoding.DecodeString(sealed)
+	if err != nil || len(data) < aead.NonceSize() {
+		return "", false
+	}
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return "", false
+	}
+	return string(plain), true
+}
+
+// tokenCipher Builds AES-256-GCM keyed by the pepper | 构建以密钥派生的AES-256-GCM
+func (m *Manager) tokenCipher() (cipher.AEAD, error) {
+	mac := hmac.New(sha256.New, []byte(m.config.TokenHashPepper))
+	mac.Write([]byte(TicketKeyPrefix))
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
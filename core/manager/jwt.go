@@ -0,0 +1,292 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"suwei.sa_token/core/config"
+	"suwei.sa_token/core/listener"
+	"suwei.sa_token/core/token"
+)
+
+// JWT Modes
+// JWT模式
+//
+// With TokenStyle=jwt, Config.JwtMode decides how much the manager relies on storage:
+// 当TokenStyle=jwt时，Config.JwtMode决定管理器对存储的依赖程度：
+//
+//   simple     The JWT is only the token value. Its signature is checked first, then storage
+//              stays authoritative, so everything works as with opaque tokens.
+//              JWT仅作为Token值。先校验签名，之后仍以存储为准，所有功能与普通Token一致。
+//   mixin      Login ID, device, login time, permissions and roles are claims. Storage only keeps
+//              revoked tokens and per-device kickout times, IsLogin never reads the Token-Session.
+//              登录ID、设备、登录时间、权限和角色保存在声明中。存储只保留已注销的Token和按设备记录的
+//              踢下线时间，IsLogin 不会读取Token-Session。
+//   stateless  Claims only, nothing about tokens is stored: a token is valid until it expires
+//              or its jti is revoked.
+//              只使用声明，不存储任何Token信息：Token在过期或其jti被撤销前始终有效。
+//
+// Claims are a snapshot taken at login, permission or role changes apply after the next login.
+// Time-limited grants are left out since they would outlive the grant; mixin mode reads the
+// active ones from storage at check time. The exp claim is signed, so mixin and stateless
+// tokens are never auto-renewed.
+// 声明是登录时的快照，权限或角色的变更在下次登录后生效。限时授权不写入声明，否则会比授权存活更久；
+// mixin模式在检查时从存储读取有效授权。exp声明已签名，因此mixin和stateless模式的Token不会自动续期。
+//
+// Unsupported operations return ErrJwtModeUnsupported | 不支持的操作返回 ErrJwtModeUnsupported:
+//   mixin      LoginByToken, GetTokenValue, GetTokenValueListByLoginID
+//   stateless  the mixin ones plus Logout, LogoutByToken, Kickout and OpenSafe; IsSafe is
+//              always false and disabled accounts can still log in. LogoutByToken works once
+//              Config.JwtRevocation is set, see revocation.go
+//              除mixin不支持的操作外，还有 Logout、LogoutByToken、Kickout 和 OpenSafe；IsSafe
+//              始终为false，被封禁的账号仍可登录。设置Config.JwtRevocation后支持 LogoutByToken，
+//              见 revocation.go
+
+// JWT mode key prefixes | JWT模式键前缀
+const (
+	JwtRevokedKeyPrefix = "jwt:revoked:" // Logged out tokens | 已登出的Token
+	JwtKickedKeyPrefix  = "jwt:kicked:"  // Kickout time per account and device | 按账号和设备记录的踢下线时间
+)
+
+// ErrJwtModeUnsupported Operation needs storage the current JWT mode does not keep | 操作依赖当前JWT模式不保存的存储数据
+var ErrJwtModeUnsupported = fmt.Errorf("operation not supported in this jwt mode")
+
+// JwtClaimsLoader Loads permissions and roles embedded in the JWT at login | 登录时加载写入JWT的权限和角色
+type JwtClaimsLoader func(loginID string) (permissions, roles []string, err error)
+
+// SetJwtClaimsLoader Sets the loader of permission and role claims; without it mixin mode reads the
+// Token-Session at login and stateless mode embeds none |
+// 设置权限和角色声明的加载器；未设置时mixin模式在登录时读取Token-Session，stateless模式不写入
+func (m *Manager) SetJwtClaimsLoader(loader JwtClaimsLoader) {
+	m.jwtClaimsLoader = loader
+}
+
+// jwtMode Gets the JWT mode, empty when tokens carry no claims (JWT, PASETO) | 获取JWT模式，Token不携带声明（JWT、PASETO）时为空
+func (m *Manager) jwtMode() config.JwtMode {
+	if !m.config.TokenStyle.IsClaims() {
+		return ""
+	}
+	return m.config.GetJwtMode()
+}
+
+// isClaimsMode Checks if login state lives in JWT claims | 检查登录状态是否保存在JWT声明中
+func (m *Manager) isClaimsMode() bool {
+	mode := m.jwtMode()
+	return mode == config.JwtModeMixin || mode == config.JwtModeStateless
+}
+
+// isStateless Checks if storage is not used for tokens | 检查Token是否不使用存储
+func (m *Manager) isStateless() bool {
+	return m.jwtMode() == config.JwtModeStateless
+}
+
+// unsupported Builds an ErrJwtModeUnsupported error for operation | 为操作构建 ErrJwtModeUnsupported 错误
+func (m *Manager) unsupported(operation string) error {
+	return fmt.Errorf("%w: %s in %s mode", ErrJwtModeUnsupported, operation, m.jwtMode())
+}
+
+// loginJWT Issues a JWT carrying the login state | 签发携带登录状态的JWT
+func (m *Manager) loginJWT(loginID string, options LoginOptions) (string, error) {
+	device := options.Device
+	if !m.isStateless() {
+		if m.IsDisable(loginID) {
+			return "", ErrAccountDisabled
+		}
+		if !m.config.IsConcurrent {
+			if err := m.kickoutJWT(loginID, device, listener.EventKickout); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	permissions, roles, err := m.loadJwtClaims(loginID)
+	if err != nil {
+		return "", fmt.Errorf("failed to load jwt claims: %w", err)
+	}
+	claims := jwtExtraClaims(options)
+	claims[token.ClaimPermissions] = permissions
+	claims[token.ClaimRoles] = roles
+	tokenValue, err := m.generator.GenerateJWTWithTimeout(loginID, device, options.Timeout, claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	m.triggerEvent(listener.EventLogin, loginID, device, m.TokenID(tokenValue))
+	return tokenValue, nil
+}
+
+// loadJwtClaims Loads permission and role claims | 加载权限和角色声明
+func (m *Manager) loadJwtClaims(loginID string) ([]string, []string, error) {
+	if m.jwtClaimsLoader != nil {
+		return m.jwtClaimsLoader(loginID)
+	}
+	if m.isStateless() {
+		return []string{}, []string{}, nil
+	}
+
+	// Static lists only, timed grants are merged at check time | 仅静态列表，限时授权在检查时合并
+	permissions, err := m.GetPermissions(loginID)
+	if err != nil {
+		return nil, nil, err
+	}
+	roles, err := m.GetRoles(loginID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return permissions, roles, nil
+}
+
+// claimValuesWithGrants Reads a list claim, in mixin mode plus the account's active grants under key |
+// 读取列表声明，mixin模式下追加账号在key下的有效授权
+func (m *Manager) claimValuesWithGrants(claims jwt.MapClaims, claim, key string) []string {
+	values := m.toStringSlice(claims[claim])
+	if m.jwtMode() != config.JwtModeMixin {
+		return values
+	}
+	loginID, _ := claims[token.ClaimLoginID].(string)
+	if granted := m.getActiveGrantValues(loginID, key); len(granted) > 0 {
+		values = append(values[:len(values):len(values)], granted...)
+	}
+	return values
+}
+
+// parseJWTClaims Verifies a JWT against the jti revocation list if enabled and, in mixin mode, the revoked and kickout lists |
+// 校验JWT，启用时检查jti撤销列表，mixin模式下同时检查注销和踢下线列表
+func (m *Manager) parseJWTClaims(tokenValue string) (jwt.MapClaims, error) {
+	if tokenValue == "" {
+		return nil, ErrNotLogin
+	}
+	claims, err := m.generator.ParseJWT(tokenValue)
+	if err != nil {
+		return nil, ErrNotLogin
+	}
+	if m.jwtMode() != config.JwtModeMixin {
+		return claims, nil
+	}
+
+	if m.storage.Exists(m.getJwtRevokedKey(tokenValue)) {
+		return nil, ErrNotLogin
+	}
+	loginID, _ := claims[token.ClaimLoginID].(string)
+	device, _ := claims[token.ClaimDevice].(string)
+	if kickedAt, ok := m.getJwtKickedAt(loginID, device); ok && claimInt64(claims, token.ClaimLoginTime) < kickedAt {
+		return nil, ErrNotLogin
+	}
+	return claims, nil
+}
+
+// jwtTokenInfo Builds token information from claims | 从声明构建Token信息
+func (m *Manager) jwtTokenInfo(tokenValue string) (*TokenInfo, error) {
+	claims, err := m.parseJWTClaims(tokenValue)
+	if err != nil {
+		return nil, err
+	}
+	loginID, _ := claims[token.ClaimLoginID].(string)
+	device, _ := claims[token.ClaimDevice].(string)
+	deviceID, _ := claims[token.ClaimDeviceID].(string)
+	return &TokenInfo{
+		LoginID:    loginID,
+		Device:     device,
+		DeviceID:   deviceID,
+		CreateTime: claimInt64(claims, "iat"),
+		Extra:      extraFromClaims(claims),
+	}, nil
+}
+
+// revokeJWT Adds a token to the revoked list until it expires, the jti revocation list when enabled |
+// 将Token加入注销列表直至其过期，启用时加入jti撤销列表
+func (m *Manager) revokeJWT(tokenValue string) error {
+	if m.isStateless() && m.revocations == nil {
+		return m.unsupported("LogoutByToken")
+	}
+	claims, err := m.generator.ParseJWT(tokenValue)
+	if err != nil {
+		return nil // Forged, expired or revoked tokens are unusable already | 伪造、已过期或已撤销的Token本就无法使用
+	}
+
+	var ttl time.Duration
+	var exp time.Time
+	if unix := claimInt64(claims, "exp"); unix > 0 {
+		exp = time.Unix(unix, 0)
+		if ttl = time.Until(exp); ttl <= 0 {
+			return nil
+		}
+	}
+	jti, _ := claims[token.ClaimJTI].(string)
+	switch {
+	case m.revocations != nil && jti != "":
+		if err := m.revocations.Revoke(jti, exp); err != nil {
+			return err
+		}
+	case m.isStateless():
+		return m.unsupported("LogoutByToken") // Issued before jti claims | 在jti声明之前签发
+	default:
+		if err := m.storage.Set(m.getJwtRevokedKey(tokenValue), DisableValue, ttl); err != nil {
+			return err
+		}
+	}
+	if !m.isStateless() {
+		m.storage.Delete(m.getCSRFKey(tokenValue))
+	}
+
+	loginID, _ := claims[token.ClaimLoginID].(string)
+	m.triggerEvent(listener.EventLogout, loginID, "", m.TokenID(tokenValue))
+	return nil
+}
+
+// kickoutJWT Invalidates tokens of an account and device issued before now | 使账号和设备在此之前签发的Token失效
+// The time never expires: a per-login timeout may keep a token alive longer than Config.Timeout, and
+// there is one record per account and device | 该时间永不过期：单次登录超时可能使Token存活超过Config.Timeout，且每个账号和设备只有一条记录
+func (m *Manager) kickoutJWT(loginID, device string, event listener.Event) error {
+	if m.isStateless() {
+		return m.unsupported(string(event))
+	}
+	kickedAt := strconv.FormatInt(time.Now().UnixMicro(), 10)
+	if err := m.storage.Set(m.getJwtKickedKey(loginID, device), kickedAt, 0); err != nil {
+		return err
+	}
+	m.triggerEvent(event, loginID, device, "")
+	return nil
+}
+
+// getJwtKickedAt Gets the kickout time of an account and device in microseconds | 获取账号和设备的踢下线时间（微秒）
+func (m *Manager) getJwtKickedAt(loginID, device string) (int64, bool) {
+	value, err := m.storage.Get(m.getJwtKickedKey(loginID, device))
+	if err != nil || value == nil {
+		return 0, false
+	}
+	str, ok := assertString(value)
+	if !ok {
+		return 0, false
+	}
+	kickedAt, err := strconv.ParseInt(str, 10, 64)
+	return kickedAt, err == nil
+}
+
+// getJwtRevokedKey Gets storage key of a revoked token | 获取已注销Token的存储键
+func (m *Manager) getJwtRevokedKey(tokenValue string) string {
+	return m.tokenIDKey(JwtRevokedKeyPrefix, m.TokenID(tokenValue))
+}
+
+// getJwtKickedKey Gets storage key of a kickout time | 获取踢下线时间的存储键
+func (m *Manager) getJwtKickedKey(loginID, device string) string {
+	return m.prefix + JwtKickedKeyPrefix + loginID + PermissionSeparator + device
+}
+
+// claimInt64 Reads a numeric claim | 读取数值声明
+func claimInt64(claims jwt.MapClaims, key string) int64 {
+	switch v := claims[key].(type) {
+	case float64:
+		return int64(v)
+	case int64:
+		return v
+	case json.Number:
+		n, _ := v.Int64()
+		return n
+	default:
+		return 0
+	}
+}
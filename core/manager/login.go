@@ -0,0 +1,372 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"suwei.sa_token/core/config"
+	"suwei.sa_token/core/listener"
+	"suwei.sa_token/core/session"
+	"suwei.sa_token/core/token"
+)
+
+// Login Options
+// 登录选项
+//
+// LoginWithOptions tunes a single login: a shorter timeout for kiosk devices, a device ID, extra
+// data such as tenant ID or scopes, a pre-chosen token value. Options are kept in token metadata
+// next to the token with the same TTL; JWTs carry extra data and device ID as claims as well.
+// GetTokenInfo reads everything back, zero fields fall back to Config.
+// LoginWithOptions 调整单次登录：为自助终端设置更短的超时时间、设备ID、租户ID或权限范围等额外数据、
+// 预先指定的Token值。选项保存在与Token TTL相同的Token元数据中；JWT同时以声明的形式携带额外数据和设备ID。
+// GetTokenInfo 可读回全部信息，零值字段使用Config中的配置。
+//
+// Usage | 用法:
+//   token, _ := manager.LoginWithOptions("1000", &LoginOptions{
+//       Device:  "kiosk",
+//       Timeout: 600,
+//       Extra:   map[string]any{"tenantId": "t1", "scopes": []string{"read"}},
+//   })
+//   info, _ := manager.GetTokenInfo(token)   // info.Extra["tenantId"] == "t1"
+
+// Token metadata key prefixes | Token元数据键前缀
+const (
+	TokenMetaKeyPrefix = "token-meta:" // Per-login options and extra data | 单次登录选项和额外数据
+	ActiveKeyPrefix    = "active:"     // Last active time, expires after ActiveTimeout | 最后活跃时间，ActiveTimeout后过期
+)
+
+// LoginOptions Per-login options, zero values fall back to Config | 单次登录选项，零值使用Config中的配置
+type LoginOptions struct {
+	Device        string             // Device type, DefaultDevice if empty | 设备类型，为空时使用DefaultDevice
+	DeviceID      string             // Identifier of the physical device | 物理设备标识
+	Timeout       int64              // Token timeout in seconds, -1 never expires | Token超时时间（秒），-1为永不过期
+	ActiveTimeout int64              // Inactivity timeout in seconds, -1 no limit | 活跃超时时间（秒），-1为不限制
+	Extra         map[string]any     // JWT claims for jwt tokens, token metadata otherwise | JWT Token写入声明，否则写入Token元数据
+	Token         string             // Pre-chosen token value, generated if empty, see SignToken | 预先指定的Token值，为空时自动生成，参见SignToken
+	RememberMe    *bool              // Persistent ("remember me") or session cookie, nil uses Config.IsRememberMe | 使用持久（"记住我"）Cookie或会话Cookie，为nil时使用Config.IsRememberMe
+	Fingerprint   *ClientFingerprint // Client the token is bound to, set by SaTokenContext with TokenBinding | Token绑定的客户端，开启TokenBinding时由SaTokenContext设置
+}
+
+// IsRememberMe Checks whether a login with these options gets a persistent cookie, nil options use the config |
+// 检查使用这些选项的登录是否获得持久Cookie，选项为nil时使用配置
+func (o *LoginOptions) IsRememberMe(cfg *config.Config) bool {
+	if o != nil && o.RememberMe != nil {
+		return *o.RememberMe
+	}
+	return cfg.IsRememberMe
+}
+
+// tokenMeta Token metadata kept next to the token | 与Token一起保存的元数据
+type tokenMeta struct {
+	Device        string             `json:"device"`
+	DeviceID      string             `json:"deviceId,omitempty"`
+	CreateTime    int64              `json:"createTime"`
+	Timeout       int64              `json:"timeout"`
+	ActiveTimeout int64              `json:"activeTimeout,omitempty"`
+	Extra         map[string]any     `json:"extra,omitempty"`
+	Binding       *ClientFingerprint `json:"binding,omitempty"`
+}
+
+// reservedClaims Claims set by the framework, never returned as extra data | 框架设置的声明，不作为额外数据返回
+var reservedClaims = map[string]bool{
+	token.ClaimLoginID:     true,
+	token.ClaimDevice:      true,
+	token.ClaimDeviceID:    true,
+	token.ClaimLoginTime:   true,
+	token.ClaimPermissions: true,
+	token.ClaimRoles:       true,
+	token.ClaimJTI:         true,
+	ClaimBinding:           true,
+	"iat":                  true,
+	"exp":                  true,
+}
+
+// LoginWithOptions Performs user login with per-login options | 使用单次登录选项登录
+func (m *Manager) LoginWithOptions(loginID string, opts *LoginOptions) (string, error) {
+	options, err := m.resolveLoginOptions(opts)
+	if err != nil {
+		return "", err
+	}
+
+	// Login state lives in claims | 登录状态保存在声明中
+	if m.isClaimsMode() {
+		if options.Token != "" {
+			return "", m.unsupported("LoginOptions.Token")
+		}
+		if opts != nil && opts.ActiveTimeout > 0 {
+			return "", m.unsupported("LoginOptions.ActiveTimeout")
+		}
+		tokenValue, err := m.loginJWT(loginID, options)
+		if err != nil {
+			return "", err
+		}
+		if options.IsRememberMe(m.config) && m.config.IsReadCookie {
+			if err := m.SetRememberMe(tokenValue, true); err != nil {
+				return "", err
+			}
+		}
+		return tokenValue, nil
+	}
+
+	// Check if account is disabled | 检查是否被封禁
+	if m.IsDisable(loginID) {
+		return "", ErrAccountDisabled
+	}
+
+	// Kick out old session if concurrent login is not allowed | 如果不允许并发登录，先踢掉旧的
+	if !m.config.IsConcurrent {
+		m.kickout(loginID, options.Device)
+	}
+
+	// Generate token | 生成Token
+	tokenValue, err := m.generateToken(loginID, options)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	expiration := timeoutDuration(options.Timeout)
+
+	// Save token-loginID mapping (符合 Java sa-token 设计) | 保存 Token-LoginID 映射
+	tokenKey := m.getTokenKey(tokenValue)
+	if err := m.storage.Set(tokenKey, loginID, expiration); err != nil {
+		return "", fmt.Errorf("failed to save token: %w", err)
+	}
+
+	// Save account-token mapping | 保存账号-Token映射
+	accountKey := m.getAccountKey(loginID, options.Device)
+	if err := m.storage.Set(accountKey, m.TokenID(tokenValue), expiration); err != nil {
+		return "", fmt.Errorf("failed to save account mapping: %w", err)
+	}
+
+	now := time.Now()
+	if err := m.saveTokenMeta(tokenValue, &tokenMeta{
+		Device:        options.Device,
+		DeviceID:      options.DeviceID,
+		CreateTime:    now.Unix(),
+		Timeout:       options.Timeout,
+		ActiveTimeout: options.ActiveTimeout,
+		Extra:         options.Extra,
+		Binding:       options.Fingerprint,
+	}, expiration); err != nil {
+		return "", err
+	}
+	if options.ActiveTimeout > 0 {
+		if err := m.touchActive(tokenValue, options.ActiveTimeout); err != nil {
+			return "", err
+		}
+	}
+	if options.IsRememberMe(m.config) && m.config.IsReadCookie {
+		if err := m.SetRememberMe(tokenValue, true); err != nil {
+			return "", err
+		}
+	}
+
+	// Create session | 创建Session
+	sess := session.NewSession(loginID, m.storage, m.prefix)
+	sess.Set(SessionKeyLoginID, loginID)
+	sess.Set(SessionKeyDevice, options.Device)
+	sess.Set(SessionKeyLoginTime, now.Unix())
+
+	m.triggerEvent(listener.EventLogin, loginID, options.Device, m.TokenID(tokenValue))
+	return tokenValue, nil
+}
+
+// GetTokenTimeout Gets the timeout a token was issued with in seconds, -1 if it never expires |
+// 获取Token签发时的超时时间（秒），永不过期时为-1
+func (m *Manager) GetTokenTimeout(tokenValue string) int64 {
+	if m.isClaimsMode() {
+		claims, err := m.generator.ParseJWT(tokenValue)
+		if err != nil {
+			return m.config.Timeout
+		}
+		exp := claimInt64(claims, "exp")
+		if exp <= 0 {
+			return config.NoLimit
+		}
+		return exp - claimInt64(claims, "iat")
+	}
+	if meta := m.getTokenMeta(tokenValue); meta != nil {
+		return meta.Timeout
+	}
+	return m.config.Timeout
+}
+
+// resolveLoginOptions Fills zero options from Config | 使用Config填充零值选项
+func (m *Manager) resolveLoginOptions(opts *LoginOptions) (LoginOptions, error) {
+	var options LoginOptions
+	if opts != nil {
+		options = *opts
+	}
+	if options.Timeout < config.NoLimit {
+		return options, fmt.Errorf("Timeout must be >= -1, got: %d", options.Timeout)
+	}
+	if options.ActiveTimeout < config.NoLimit {
+		return options, fmt.Errorf("ActiveTimeout must be >= -1, got: %d", options.ActiveTimeout)
+	}
+	if options.Fingerprint != nil && options.Fingerprint.invalidIP {
+		return options, ErrInvalidClientIP
+	}
+
+	if options.Device == "" {
+		options.Device = DefaultDevice
+	}
+	if options.Timeout == 0 {
+		options.Timeout = m.config.Timeout
+	}
+	if options.ActiveTimeout == 0 {
+		options.ActiveTimeout = m.config.ActiveTimeout
+	}
+	return options, nil
+}
+
+// generateToken Generates the token value, JWTs carry extra data as claims | 生成Token值，JWT以声明携带额外数据
+func (m *Manager) generateToken(loginID string, options LoginOptions) (string, error) {
+	if options.Token != "" {
+		if !m.generator.VerifySignature(options.Token) {
+			return "", token.ErrUnsignedToken
+		}
+		return options.Token, nil
+	}
+	if m.config.TokenStyle.IsClaims() {
+		return m.generator.GenerateJWTWithTimeout(loginID, options.Device, options.Timeout, jwtExtraClaims(options))
+	}
+	return m.generator.Generate(loginID, options.Device)
+}
+
+// jwtExtraClaims Builds extra JWT claims from login options | 根据登录选项构建额外的JWT声明
+func jwtExtraClaims(options LoginOptions) map[string]any {
+	claims := make(map[string]any, len(options.Extra)+1)
+	for key, value := range options.Extra {
+		claims[key] = value
+	}
+	if options.DeviceID != "" {
+		claims[token.ClaimDeviceID] = options.DeviceID
+	}
+	if options.Fingerprint != nil {
+		claims[ClaimBinding] = options.Fingerprint
+	}
+	return claims
+}
+
+// extraFromClaims Gets custom claims, leaving out the framework ones | 获取自定义声明，排除框架声明
+func extraFromClaims(claims jwt.MapClaims) map[string]any {
+	var extra map[string]any
+	for key, value := range claims {
+		if reservedClaims[key] {
+			continue
+		}
+		if extra == nil {
+			extra = make(map[string]any)
+		}
+		extra[key] = value
+	}
+	return extra
+}
+
+// saveTokenMeta Saves token metadata | 保存Token元数据
+func (m *Manager) saveTokenMeta(tokenValue string, meta *tokenMeta, expiration time.Duration) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("failed to encode token metadata: %w", err)
+	}
+	if err := m.storage.Set(m.getTokenMetaKey(tokenValue), string(data), expiration); err != nil {
+		return fmt.Errorf("failed to save token metadata: %w", err)
+	}
+	return nil
+}
+
+// getTokenMeta Gets token metadata, nil for tokens issued without it | 获取Token元数据，没有元数据的Token返回nil
+func (m *Manager) getTokenMeta(tokenValue string) *tokenMeta {
+	value, err := m.storage.Get(m.getTokenMetaKey(tokenValue))
+	if err != nil || value == nil {
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return nil
+	}
+	var meta tokenMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil
+	}
+	return &meta
+}
+
+// checkActive Checks the inactivity timeout and records the activity | 检查活跃超时并记录本次活跃
+func (m *Manager) checkActive(tokenValue string, meta *tokenMeta) bool {
+	if meta == nil || meta.ActiveTimeout <= 0 {
+		return true
+	}
+	if !m.storage.Exists(m.getActiveKey(tokenValue)) {
+		return false // Frozen after inactivity | 超过活跃时间后冻结
+	}
+	return m.touchActive(tokenValue, meta.ActiveTimeout) == nil
+}
+
+// touchActive Records the last active time | 记录最后活跃时间
+func (m *Manager) touchActive(tokenValue string, activeTimeout int64) error {
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	return m.storage.Set(m.getActiveKey(tokenValue), now, timeoutDuration(activeTimeout))
+}
+
+// getActiveTime Gets the last active time in seconds, 0 if not tracked | 获取最后活跃时间（秒），未记录时为0
+func (m *Manager) getActiveTime(tokenValue string) int64 {
+	value, err := m.storage.Get(m.getActiveKey(tokenValue))
+	if err != nil || value == nil {
+		return 0
+	}
+	str, ok := assertString(value)
+	if !ok {
+		return 0
+	}
+	activeTime, _ := strconv.ParseInt(str, 10, 64)
+	return activeTime
+}
+
+// tokenExpiration Gets the storage TTL of a token's data | 获取Token相关数据的存储TTL
+func (m *Manager) tokenExpiration(tokenValue string) time.Duration {
+	return timeoutDuration(m.GetTokenTimeout(tokenValue))
+}
+
+// tokenDataKeys Gets storage keys removed with a token | 获取随Token一起删除的存储键
+func (m *Manager) tokenDataKeys(tokenValue string) []string {
+	return m.tokenDataKeysByID(m.TokenID(tokenValue))
+}
+
+// tokenDataKeysByID Gets storage keys removed with a token ID read from storage | 获取随从存储读取的Token ID一起删除的存储键
+func (m *Manager) tokenDataKeysByID(tokenID string) []string {
+	return []string{
+		m.tokenIDKey(TokenKeyPrefix, tokenID),
+		m.tokenIDKey(TokenMetaKeyPrefix, tokenID),
+		m.tokenIDKey(ActiveKeyPrefix, tokenID),
+		m.tokenIDKey(RememberKeyPrefix, tokenID),
+		m.tokenIDKey(CSRFKeyPrefix, tokenID),
+	}
+}
+
+// getTokenMetaKey Gets storage key of token metadata | 获取Token元数据的存储键
+func (m *Manager) getTokenMetaKey(tokenValue string) string {
+	return m.tokenIDKey(TokenMetaKeyPrefix, m.TokenID(tokenValue))
+}
+
+// getActiveKey Gets storage key of last active time | 获取最后活跃时间的存储键
+func (m *Manager) getActiveKey(tokenValue string) string {
+	return m.tokenIDKey(ActiveKeyPrefix, m.TokenID(tokenValue))
+}
+
+// timeoutDuration Converts a timeout in seconds to a storage TTL, 0 means no expiration | 将超时秒数转换为存储TTL，0表示不过期
+func timeoutDuration(seconds int64) time.Duration {
+	if seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return 0
+}
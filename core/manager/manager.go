@@ -2,12 +2,16 @@ package manager
 
 import (
 	"fmt"
-	"strings"
+	"sync"
 	"time"
 
+	"suwei.sa_token/core/acl"
 	"suwei.sa_token/core/adapter"
 	"suwei.sa_token/core/config"
+	"suwei.sa_token/core/listener"
 	"suwei.sa_token/core/oauth2"
+	"suwei.sa_token/core/permission"
+	"suwei.sa_token/core/policy"
 	"suwei.sa_token/core/security"
 	"suwei.sa_token/core/session"
 	"suwei.sa_token/core/token"
@@ -24,6 +28,7 @@ const (
 	TokenKeyPrefix   = "token:"
 	AccountKeyPrefix = "account:"
 	DisableKeyPrefix = "disable:"
+	SafeKeyPrefix    = "safe:"
 
 	// Session keys | Session键
 	SessionKeyLoginID     = "loginId"
@@ -33,8 +38,8 @@ const (
 	SessionKeyRoles       = "roles"
 
 	// Wildcard for permissions | 权限通配符
-	PermissionWildcard  = "*"
-	PermissionSeparator = ":"
+	PermissionWildcard  = permission.Wildcard
+	PermissionSeparator = permission.Separator
 )
 
 // Error variables | 错误变量
@@ -43,15 +48,21 @@ var (
 	ErrNotLogin         = fmt.Errorf("not login")
 	ErrTokenNotFound    = fmt.Errorf("token not found")
 	ErrInvalidTokenData = fmt.Errorf("invalid token data")
+	ErrAccessDenied     = fmt.Errorf("access denied")
+	ErrPermissionDenied = fmt.Errorf("permission denied")
+	ErrRoleDenied       = fmt.Errorf("role denied")
+	ErrNotSafe          = fmt.Errorf("second-level authentication required")
 )
 
 // TokenInfo Token information | Token信息
 type TokenInfo struct {
-	LoginID    string `json:"loginId"`
-	Device     string `json:"device"`
-	CreateTime int64  `json:"createTime"`
-	ActiveTime int64  `json:"activeTime"` // Last active time | 最后活跃时间
-	Tag        string `json:"tag,omitempty"`
+	LoginID    string         `json:"loginId"`
+	Device     string         `json:"device"`
+	DeviceID   string         `json:"deviceId,omitempty"`
+	CreateTime int64          `json:"createTime"`
+	ActiveTime int64          `json:"activeTime"` // Last active time | 最后活跃时间
+	Tag        string         `json:"tag,omitempty"`
+	Extra      map[string]any `json:"extra,omitempty"` // Extra data given at login | 登录时传入的额外数据
 }
 
 // Manager Authentication manager | 认证管理器
@@ -62,7 +73,17 @@ type Manager struct {
 	prefix         string
 	nonceManager   *security.NonceManager
 	refreshManager *security.RefreshTokenManager
+	revocations    *security.RevocationList
 	oauth2Server   *oauth2.OAuth2Server
+	permCache      *permission.Cache
+	policyEngine   *policy.Engine
+	aclStore       *acl.Store
+	eventManager   *listener.Manager
+
+	matcherMu     sync.RWMutex
+	loginMatchers map[string]*loginMatcher
+
+	jwtClaimsLoader JwtClaimsLoader
 }
 
 // NewManager Creates a new manager | 创建管理器
@@ -77,7 +98,7 @@ func NewManager(storage adapter.Storage, cfg *config.Config) *Manager {
 		prefix = DefaultPrefix
 	}
 
-	return &Manager{
+	m := &Manager{
 		storage:        storage,
 		config:         cfg,
 		generator:      token.NewGenerator(cfg),
@@ -85,7 +106,19 @@ func NewManager(storage adapter.Storage, cfg *config.Config) *Manager {
 		nonceManager:   security.NewNonceManager(storage, prefix, DefaultNonceTTL),
 		refreshManager: security.NewRefreshTokenManager(storage, prefix, cfg),
 		oauth2Server:   oauth2.NewOAuth2Server(storage, prefix),
+		permCache:      permission.NewCache(permission.DefaultCacheSize, cfg.PermissionIgnoreCase),
+		policyEngine:   policy.NewEngine(),
+		aclStore:       acl.NewStore(storage, prefix),
+		eventManager:   listener.NewManager(),
+		loginMatchers:  make(map[string]*loginMatcher),
 	}
+	if r := cfg.JwtRevocation; r != nil && cfg.TokenStyle.IsClaims() {
+		m.revocations = security.NewRevocationList(storage, prefix, r.GetSyncInterval(), r.GetCapacity())
+		m.generator.SetRevocationChecker(m.revocations)
+		// Load the filter up front, a failure is retried in the background | 预先加载过滤器，失败时在后台重试
+		m.revocations.Sync()
+	}
+	return m
 }
 
 // ============ Helper Methods | 辅助方法 ============
@@ -106,6 +139,19 @@ func (m *Manager) getExpiration() time.Duration {
 	return 0
 }
 
+// triggerEvent dispatches an auth event to registered listeners | 向已注册的监听器分发认证事件
+func (m *Manager) triggerEvent(event listener.Event, loginID, device, tokenValue string) {
+	if !m.eventManager.HasListeners(event) && !m.eventManager.HasListeners(listener.EventAll) {
+		return
+	}
+	m.eventManager.Trigger(&listener.EventData{
+		Event:   event,
+		LoginID: loginID,
+		Device:  device,
+		Token:   tokenValue,
+	})
+}
+
 // assertString safely converts interface to string | 安全地将interface转换为string
 func assertString(v any) (string, bool) {
 	s, ok := v.(string)
@@ -116,49 +162,17 @@ func assertString(v any) (string, bool) {
 
 // Login Performs user login and returns token | 登录，返回Token
 func (m *Manager) Login(loginID string, device ...string) (string, error) {
-	deviceType := getDevice(device)
-
-	// Check if account is disabled | 检查是否被封禁
-	if m.IsDisable(loginID) {
-		return "", ErrAccountDisabled
-	}
-
-	// Kick out old session if concurrent login is not allowed | 如果不允许并发登录，先踢掉旧的
-	if !m.config.IsConcurrent {
-		m.kickout(loginID, deviceType)
-	}
-
-	// Generate token | 生成Token
-	tokenValue, err := m.generator.Generate(loginID, deviceType)
-	if err != nil {
-		return "", fmt.Errorf("failed to generate token: %w", err)
-	}
-
-	expiration := m.getExpiration()
-
-	// Save token-loginID mapping (符合 Java sa-token 设计) | 保存 Token-LoginID 映射
-	tokenKey := m.getTokenKey(tokenValue)
-	if err := m.storage.Set(tokenKey, loginID, expiration); err != nil {
-		return "", fmt.Errorf("failed to save token: %w", err)
-	}
-
-	// Save account-token mapping | 保存账号-Token映射
-	accountKey := m.getAccountKey(loginID, deviceType)
-	if err := m.storage.Set(accountKey, tokenValue, expiration); err != nil {
-		return "", fmt.Errorf("failed to save account mapping: %w", err)
-	}
-
-	// Create session | 创建Session
-	sess := session.NewSession(loginID, m.storage, m.prefix)
-	sess.Set(SessionKeyLoginID, loginID)
-	sess.Set(SessionKeyDevice, deviceType)
-	sess.Set(SessionKeyLoginTime, time.Now().Unix())
-
-	return tokenValue, nil
+	return m.LoginWithOptions(loginID, &LoginOptions{Device: getDevice(device)})
 }
 
 // LoginByToken Login with specified token (for seamless token refresh) | 使用指定Token登录（用于token无感刷新）
 func (m *Manager) LoginByToken(loginID string, tokenValue string, device ...string) error {
+	if m.isClaimsMode() {
+		return m.unsupported("LoginByToken")
+	}
+	if !m.generator.VerifySignature(tokenValue) {
+		return token.ErrUnsignedToken
+	}
 	deviceType := getDevice(device)
 	expiration := m.getExpiration()
 
@@ -169,12 +183,20 @@ func (m *Manager) LoginByToken(loginID string, tokenValue string, device ...stri
 	}
 
 	accountKey := m.getAccountKey(loginID, deviceType)
-	return m.storage.Set(accountKey, tokenValue, expiration)
+	return m.storage.Set(accountKey, m.TokenID(tokenValue), expiration)
+}
+
+// SignToken Tags a pre-chosen token value when TokenSignKeySet is set, see LoginByToken | 设置TokenSignKeySet时为预先指定的Token值添加标签，参见LoginByToken
+func (m *Manager) SignToken(tokenValue string) (string, error) {
+	return m.generator.Sign(tokenValue)
 }
 
 // Logout Performs user logout | 登出
 func (m *Manager) Logout(loginID string, device ...string) error {
 	deviceType := getDevice(device)
+	if m.isClaimsMode() {
+		return m.kickoutJWT(loginID, deviceType, listener.EventLogout)
+	}
 	accountKey := m.getAccountKey(loginID, deviceType)
 
 	tokenValue, err := m.storage.Get(accountKey)
@@ -188,12 +210,12 @@ func (m *Manager) Logout(loginID string, device ...string) error {
 		return nil
 	}
 
-	tokenKey := m.getTokenKey(tokenStr)
-	m.storage.Delete(tokenKey)
+	m.storage.Delete(m.tokenDataKeysByID(tokenStr)...)
 
 	// Delete account mapping | 删除账号映射
 	m.storage.Delete(accountKey)
 
+	m.triggerEvent(listener.EventLogout, loginID, deviceType, tokenStr)
 	return nil
 }
 
@@ -202,12 +224,23 @@ func (m *Manager) LogoutByToken(tokenValue string) error {
 	if tokenValue == "" {
 		return nil
 	}
-	tokenKey := m.getTokenKey(tokenValue)
-	return m.storage.Delete(tokenKey)
+	if m.isClaimsMode() {
+		return m.revokeJWT(tokenValue)
+	}
+	loginID, _ := m.getLoginIDByToken(tokenValue)
+	if err := m.storage.Delete(m.tokenDataKeys(tokenValue)...); err != nil {
+		return err
+	}
+
+	m.triggerEvent(listener.EventLogout, loginID, "", m.TokenID(tokenValue))
+	return nil
 }
 
 // kickout Kick user offline (private) | 踢人下线（私有）
 func (m *Manager) kickout(loginID string, device string) error {
+	if m.isClaimsMode() {
+		return m.kickoutJWT(loginID, device, listener.EventKickout)
+	}
 	accountKey := m.getAccountKey(loginID, device)
 	tokenValue, err := m.storage.Get(accountKey)
 	if err != nil || tokenValue == nil {
@@ -219,8 +252,12 @@ func (m *Manager) kickout(loginID string, device string) error {
 		return nil
 	}
 
-	tokenKey := m.getTokenKey(tokenStr)
-	return m.storage.Delete(tokenKey)
+	if err := m.storage.Delete(m.tokenDataKeysByID(tokenStr)...); err != nil {
+		return err
+	}
+
+	m.triggerEvent(listener.EventKickout, loginID, device, tokenStr)
+	return nil
 }
 
 // Kickout Kick user offline (public method) | 踢人下线（公开方法）
@@ -237,25 +274,57 @@ func (m *Manager) IsLogin(tokenValue string) bool {
 		return false
 	}
 
+	switch m.jwtMode() {
+	case config.JwtModeMixin, config.JwtModeStateless:
+		_, err := m.parseJWTClaims(tokenValue)
+		return err == nil
+	case config.JwtModeSimple:
+		// Reject forged or expired tokens before hitting storage | 在访问存储前拒绝伪造或过期的Token
+		if m.generator.ValidateJWT(tokenValue) != nil {
+			return false
+		}
+	}
+	// Reject opaque tokens with a bad tag before hitting storage | 在访问存储前拒绝标签无效的不透明Token
+	if !m.generator.VerifySignature(tokenValue) {
+		return false
+	}
+
 	tokenKey := m.getTokenKey(tokenValue)
 	if !m.storage.Exists(tokenKey) {
 		return false
 	}
 
+	// Per-login timeouts live in token metadata | 单次登录的超时设置保存在Token元数据中
+	meta := m.getTokenMeta(tokenValue)
+	if !m.checkActive(tokenValue, meta) {
+		return false
+	}
+
 	// Async auto-renew for better performance | 异步自动续期（提高性能）
-	// Note: ActiveTimeout feature removed to comply with Java sa-token design
-	if m.config.AutoRenew && m.config.Timeout > 0 {
-		go m.renewToken(tokenKey)
+	timeout := m.config.Timeout
+	if meta != nil {
+		timeout = meta.Timeout
+	}
+	if m.config.AutoRenew && timeout > 0 {
+		go m.renewToken(tokenValue, timeoutDuration(timeout))
 	}
 
 	return true
 }
 
 // renewToken Renews token expiration asynchronously | 异步续期Token
-func (m *Manager) renewToken(tokenKey string) {
-	expiration := m.getExpiration()
+func (m *Manager) renewToken(tokenValue string, expiration time.Duration) {
 	// Extend token storage expiration | 延长Token存储的过期时间
-	m.storage.Expire(tokenKey, expiration)
+	m.storage.Expire(m.getTokenKey(tokenValue), expiration)
+	m.storage.Expire(m.getTokenMetaKey(tokenValue), expiration)
+	// Keep the remember-me marker alive with the token | 记住我标记与Token同步续期
+	if m.IsRememberMe(tokenValue) {
+		m.storage.Expire(m.getRememberKey(tokenValue), expiration)
+	}
+	// Keep the CSRF token alive with the token | CSRF令牌与Token同步续期
+	if m.storage.Exists(m.getCSRFKey(tokenValue)) {
+		m.storage.Expire(m.getCSRFKey(tokenValue), expiration)
+	}
 }
 
 // CheckLogin Checks login status (throws error if not logged in) | 检查登录（未登录抛出错误）
@@ -268,6 +337,14 @@ func (m *Manager) CheckLogin(tokenValue string) error {
 
 // GetLoginID Gets login ID from token | 根据Token获取登录ID
 func (m *Manager) GetLoginID(tokenValue string) (string, error) {
+	if m.isClaimsMode() {
+		info, err := m.jwtTokenInfo(tokenValue)
+		if err != nil {
+			return "", ErrNotLogin
+		}
+		return info.LoginID, nil
+	}
+
 	if !m.IsLogin(tokenValue) {
 		return "", ErrNotLogin
 	}
@@ -289,8 +366,11 @@ func (m *Manager) GetLoginIDNotCheck(tokenValue string) (string, error) {
 	return info.LoginID, nil
 }
 
-// GetTokenValue Gets token by login ID | 根据登录ID获取Token
+// GetTokenValue Gets token by login ID, the token ID when token hashing is on | 根据登录ID获取Token，开启Token哈希时为Token ID
 func (m *Manager) GetTokenValue(loginID string, device ...string) (string, error) {
+	if m.isClaimsMode() {
+		return "", m.unsupported("GetTokenValue")
+	}
 	deviceType := getDevice(device)
 	accountKey := m.getAccountKey(loginID, deviceType)
 
@@ -317,13 +397,23 @@ func (m *Manager) GetTokenInfo(tokenValue string) (*TokenInfo, error) {
 // Disable Disables an account | 封禁账号
 func (m *Manager) Disable(loginID string, duration time.Duration) error {
 	key := m.getDisableKey(loginID)
-	return m.storage.Set(key, DisableValue, duration)
+	if err := m.storage.Set(key, DisableValue, duration); err != nil {
+		return err
+	}
+
+	m.triggerEvent(listener.EventDisable, loginID, "", "")
+	return nil
 }
 
 // Untie Re-enables a disabled account | 解封账号
 func (m *Manager) Untie(loginID string) error {
 	key := m.getDisableKey(loginID)
-	return m.storage.Delete(key)
+	if err := m.storage.Delete(key); err != nil {
+		return err
+	}
+
+	m.triggerEvent(listener.EventUntie, loginID, "", "")
+	return nil
 }
 
 // IsDisable Checks if account is disabled | 检查账号是否被封禁
@@ -403,71 +493,78 @@ func (m *Manager) GetPermissions(loginID string) ([]string, error) {
 }
 
 // HasPermission 检查是否有指定权限
-func (m *Manager) HasPermission(loginID string, permission string) bool {
-	perms, err := m.GetPermissions(loginID)
+func (m *Manager) HasPermission(loginID string, perm string) bool {
+	matcher, err := m.permissionMatcher(loginID)
 	if err != nil {
 		return false
 	}
-
-	for _, p := range perms {
-		if m.matchPermission(p, permission) {
-			return true
-		}
-	}
-
-	return false
+	return matcher.Match(perm)
 }
 
 // HasPermissionsAnd 检查是否拥有所有权限（AND）
 func (m *Manager) HasPermissionsAnd(loginID string, permissions []string) bool {
-	for _, perm := range permissions {
-		if !m.HasPermission(loginID, perm) {
-			return false
-		}
+	matcher, err := m.permissionMatcher(loginID)
+	if err != nil {
+		return false
 	}
-	return true
+	return matcher.MatchAll(permissions)
 }
 
 // HasPermissionsOr 检查是否拥有任一权限（OR）
 func (m *Manager) HasPermissionsOr(loginID string, permissions []string) bool {
-	for _, perm := range permissions {
-		if m.HasPermission(loginID, perm) {
-			return true
-		}
+	matcher, err := m.permissionMatcher(loginID)
+	if err != nil {
+		return false
 	}
-	return false
+	return matcher.MatchAny(permissions)
 }
 
-// matchPermission Matches permission with wildcards support | 权限匹配（支持通配符）
-func (m *Manager) matchPermission(pattern, permission string) bool {
-	// Exact match or wildcard | 精确匹配或通配符
-	if pattern == PermissionWildcard || pattern == permission {
-		return true
+// GetPermissionsByToken Gets permissions of a token, read from claims in JWT mixin and stateless modes |
+// 获取Token的权限，JWT mixin和stateless模式下从声明读取
+func (m *Manager) GetPermissionsByToken(tokenValue string) ([]string, error) {
+	if m.isClaimsMode() {
+		claims, err := m.parseJWTClaims(tokenValue)
+		if err != nil {
+			return nil, err
+		}
+		return m.claimValuesWithGrants(claims, token.ClaimPermissions, SessionKeyPermissionGrants), nil
 	}
 
-	// Pattern like "user:*" matches "user:add", "user:delete", etc. | 支持通配符，例如 user:* 匹配 user:add, user:delete等
-	wildcardSuffix := PermissionSeparator + PermissionWildcard
-	if strings.HasSuffix(pattern, wildcardSuffix) {
-		prefix := strings.TrimSuffix(pattern, PermissionWildcard)
-		return strings.HasPrefix(permission, prefix)
+	loginID, err := m.GetLoginID(tokenValue)
+	if err != nil {
+		return nil, err
 	}
+	return m.getEffectivePermissions(loginID)
+}
 
-	// Pattern like "user:*:view" | 支持 user:*:view 这样的模式
-	if strings.Contains(pattern, PermissionWildcard) {
-		parts := strings.Split(pattern, PermissionSeparator)
-		permParts := strings.Split(permission, PermissionSeparator)
-		if len(parts) != len(permParts) {
-			return false
-		}
-		for i, part := range parts {
-			if part != PermissionWildcard && part != permParts[i] {
-				return false
-			}
-		}
-		return true
+// HasPermissionByToken Checks if the token has all permissions (AND) | 检查Token是否拥有所有权限（AND）
+func (m *Manager) HasPermissionByToken(tokenValue string, permissions ...string) bool {
+	matcher, err := m.tokenPermissionMatcher(tokenValue)
+	if err != nil {
+		return false
 	}
+	return matcher.MatchAll(permissions)
+}
 
-	return false
+// HasPermissionOrByToken Checks if the token has any permission (OR) | 检查Token是否拥有任一权限（OR）
+func (m *Manager) HasPermissionOrByToken(tokenValue string, permissions ...string) bool {
+	matcher, err := m.tokenPermissionMatcher(tokenValue)
+	if err != nil {
+		return false
+	}
+	return matcher.MatchAny(permissions)
+}
+
+// getEffectivePermissions Gets static permissions plus active time-limited grants | 获取静态权限及有效的限时授权
+func (m *Manager) getEffectivePermissions(loginID string) ([]string, error) {
+	perms, _, err := m.effectivePermissions(loginID)
+	return perms, err
+}
+
+// MatchPermission Matches permission against a single pattern | 使用单个模式匹配权限
+// Supports "*", "**", trailing ":*" and "{a,b}" alternatives | 支持 "*"、"**"、结尾 ":*" 以及 "{a,b}" 可选值
+func (m *Manager) MatchPermission(pattern, perm string) bool {
+	return m.permCache.Get([]string{pattern}).Match(perm)
 }
 
 // ============ Role Validation | 角色验证 ============
@@ -498,7 +595,7 @@ func (m *Manager) GetRoles(loginID string) ([]string, error) {
 
 // HasRole 检查是否有指定角色
 func (m *Manager) HasRole(loginID string, role string) bool {
-	roles, err := m.GetRoles(loginID)
+	roles, err := m.getEffectiveRoles(loginID)
 	if err != nil {
 		return false
 	}
@@ -511,6 +608,18 @@ func (m *Manager) HasRole(loginID string, role string) bool {
 	return false
 }
 
+// getEffectiveRoles Gets static roles plus active time-limited grants | 获取静态角色及有效的限时授权
+func (m *Manager) getEffectiveRoles(loginID string) ([]string, error) {
+	roles, err := m.GetRoles(loginID)
+	if err != nil {
+		return nil, err
+	}
+	if granted := m.getActiveGrantValues(loginID, SessionKeyRoleGrants); len(granted) > 0 {
+		roles = append(append([]string(nil), roles...), granted...)
+	}
+	return roles, nil
+}
+
 // HasRolesAnd 检查是否拥有所有角色（AND）
 func (m *Manager) HasRolesAnd(loginID string, roles []string) bool {
 	for _, role := range roles {
@@ -531,6 +640,170 @@ func (m *Manager) HasRolesOr(loginID string, roles []string) bool {
 	return false
 }
 
+// GetRolesByToken Gets roles of a token, read from claims in JWT mixin and stateless modes |
+// 获取Token的角色，JWT mixin和stateless模式下从声明读取
+func (m *Manager) GetRolesByToken(tokenValue string) ([]string, error) {
+	if m.isClaimsMode() {
+		claims, err := m.parseJWTClaims(tokenValue)
+		if err != nil {
+			return nil, err
+		}
+		return m.claimValuesWithGrants(claims, token.ClaimRoles, SessionKeyRoleGrants), nil
+	}
+
+	loginID, err := m.GetLoginID(tokenValue)
+	if err != nil {
+		return nil, err
+	}
+	return m.getEffectiveRoles(loginID)
+}
+
+// HasRoleByToken Checks if the token has all roles (AND) | 检查Token是否拥有所有角色（AND）
+func (m *Manager) HasRoleByToken(tokenValue string, roles ...string) bool {
+	owned, err := m.GetRolesByToken(tokenValue)
+	if err != nil {
+		return false
+	}
+	for _, role := range roles {
+		if !containsString(owned, role) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasRoleOrByToken Checks if the token has any role (OR) | 检查Token是否拥有任一角色（OR）
+func (m *Manager) HasRoleOrByToken(tokenValue string, roles ...string) bool {
+	owned, err := m.GetRolesByToken(tokenValue)
+	if err != nil {
+		return false
+	}
+	for _, role := range roles {
+		if containsString(owned, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// ============ Policy Authorization | 策略授权 ============
+
+// RegisterPolicy Registers an ABAC policy | 注册ABAC策略
+func (m *Manager) RegisterPolicy(name string, p policy.Policy) error {
+	return m.policyEngine.Register(name, p)
+}
+
+// UnregisterPolicy Removes an ABAC policy | 移除ABAC策略
+func (m *Manager) UnregisterPolicy(name string) bool {
+	return m.policyEngine.Unregister(name)
+}
+
+// GetPolicyEngine Gets policy engine | 获取策略引擎
+func (m *Manager) GetPolicyEngine() *policy.Engine {
+	return m.policyEngine
+}
+
+// BuildSubject Builds policy subject from roles and session attributes | 根据角色和Session属性构建策略主体
+func (m *Manager) BuildSubject(loginID string) (*policy.Subject, error) {
+	roles, err := m.getEffectiveRoles(loginID)
+	if err != nil {
+		return nil, err
+	}
+
+	sess, err := m.GetSession(loginID)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := make(map[string]any, sess.Size())
+	for _, key := range sess.Keys() {
+		if value, exists := sess.Get(key); exists {
+			attrs[key] = value
+		}
+	}
+
+	return &policy.Subject{
+		LoginID:    loginID,
+		Roles:      roles,
+		Attributes: attrs,
+	}, nil
+}
+
+// Authorize Evaluates policies for action on resource | 评估对资源执行操作的策略
+func (m *Manager) Authorize(loginID string, action string, resource *policy.Resource) (policy.Decision, error) {
+	subject, err := m.BuildSubject(loginID)
+	if err != nil {
+		return policy.Deny, err
+	}
+
+	return m.policyEngine.Evaluate(&policy.Request{
+		Subject:  subject,
+		Action:   action,
+		Resource: resource,
+	}), nil
+}
+
+// CheckAuthorize Checks policies, only an explicit Allow passes | 检查策略，只有明确允许才通过
+func (m *Manager) CheckAuthorize(loginID string, action string, resource *policy.Resource) error {
+	decision, err := m.Authorize(loginID, action, resource)
+	if err != nil {
+		return err
+	}
+	if decision != policy.Allow {
+		return ErrAccessDenied
+	}
+	return nil
+}
+
+// ============ Resource ACL | 资源访问控制 ============
+
+// GrantAccess Grants actions on a resource to a subject (acl.UserSubject / acl.RoleSubject / "*") | 将资源操作授权给主体
+func (m *Manager) GrantAccess(subject, resourceType, resourceID string, actions ...string) error {
+	return m.aclStore.Grant(subject, resourceType, resourceID, actions...)
+}
+
+// RevokeAccess Revokes actions from a subject, no actions means all | 撤销主体的操作授权，不传操作表示全部
+func (m *Manager) RevokeAccess(subject, resourceType, resourceID string, actions ...string) error {
+	return m.aclStore.Revoke(subject, resourceType, resourceID, actions...)
+}
+
+// ListResourceGrants Lists grants on a resource | 列出资源上的授权
+func (m *Manager) ListResourceGrants(resourceType, resourceID string) ([]*acl.Grant, error) {
+	return m.aclStore.ListByResource(resourceType, resourceID)
+}
+
+// ListSubjectGrants Lists grants of a subject | 列出主体的授权
+func (m *Manager) ListSubjectGrants(subject string) ([]*acl.Grant, error) {
+	return m.aclStore.ListBySubject(subject)
+}
+
+// HasAccess Checks ACL for loginID, its roles and everyone | 检查登录ID、其角色及所有人的ACL
+func (m *Manager) HasAccess(loginID, resourceType, resourceID, action string) bool {
+	subjects := []string{acl.UserSubject(loginID)}
+	if roles, err := m.getEffectiveRoles(loginID); err == nil {
+		for _, role := range roles {
+			subjects = append(subjects, acl.RoleSubject(role))
+		}
+	}
+	return m.aclStore.Check(subjects, resourceType, resourceID, action)
+}
+
+// CheckAccess Checks ACL (returns error if denied) | 检查ACL（无权限返回错误）
+func (m *Manager) CheckAccess(loginID, resourceType, resourceID, action string) error {
+	if resourceType == "" || resourceID == "" {
+		return acl.ErrInvalidResource
+	}
+	if !m.HasAccess(loginID, resourceType, resourceID, action) {
+		return ErrAccessDenied
+	}
+	return nil
+}
+
+// GetACLStore Gets ACL store | 获取ACL存储
+func (m *Manager) GetACLStore() *acl.Store {
+	return m.aclStore
+}
+
 // ============ Token Tags | Token标签 ============
 
 // SetTokenTag Sets token tag | 设置Token标签
@@ -548,8 +821,12 @@ func (m *Manager) GetTokenTag(tokenValue string) (string, error) {
 
 // ============ Session Query | 会话查询 ============
 
-// GetTokenValueListByLoginID Gets all tokens for specified account | 获取指定账号的所有Token
+// GetTokenValueListByLoginID Gets all tokens for specified account, token IDs when token hashing is on |
+// 获取指定账号的所有Token，开启Token哈希时为Token ID
 func (m *Manager) GetTokenValueListByLoginID(loginID string) ([]string, error) {
+	if m.isClaimsMode() {
+		return nil, m.unsupported("GetTokenValueListByLoginID")
+	}
 	pattern := m.prefix + AccountKeyPrefix + loginID + ":*"
 	keys, err := m.storage.Keys(pattern)
 	if err != nil {
@@ -582,7 +859,7 @@ func (m *Manager) GetSessionCountByLoginID(loginID string) (int, error) {
 
 // getTokenKey Gets token storage key | 获取Token存储键
 func (m *Manager) getTokenKey(tokenValue string) string {
-	return m.prefix + TokenKeyPrefix + tokenValue
+	return m.tokenIDKey(TokenKeyPrefix, m.TokenID(tokenValue))
 }
 
 // getAccountKey Gets account storage key | 获取账号存储键
@@ -592,6 +869,9 @@ func (m *Manager) getAccountKey(loginID, device string) string {
 
 // getLoginIDByToken Gets loginID by token (符合 Java sa-token 设计) | 通过 Token 获取 loginID
 func (m *Manager) getLoginIDByToken(tokenValue string) (string, error) {
+	if !m.generator.VerifySignature(tokenValue) {
+		return "", ErrTokenNotFound
+	}
 	tokenKey := m.getTokenKey(tokenValue)
 	data, err := m.storage.Get(tokenKey)
 	if err != nil || data == nil {
@@ -608,15 +888,29 @@ func (m *Manager) getLoginIDByToken(tokenValue string) (string, error) {
 
 // getTokenInfo Gets token information (为了向后兼容) | 获取Token信息（向后兼容）
 func (m *Manager) getTokenInfo(tokenValue string) (*TokenInfo, error) {
+	if m.isClaimsMode() {
+		return m.jwtTokenInfo(tokenValue)
+	}
 	loginID, err := m.getLoginIDByToken(tokenValue)
 	if err != nil {
 		return nil, err
 	}
 
-	// 构造简化的 TokenInfo，只包含必要信息
+	meta := m.getTokenMeta(tokenValue)
+	if meta == nil {
+		// 没有元数据的Token只包含必要信息
+		return &TokenInfo{
+			LoginID: loginID,
+			Device:  DefaultDevice,
+		}, nil
+	}
 	return &TokenInfo{
-		LoginID: loginID,
-		Device:  DefaultDevice, // 从 token 无法获取设备信息
+		LoginID:    loginID,
+		Device:     meta.Device,
+		DeviceID:   meta.DeviceID,
+		CreateTime: meta.CreateTime,
+		ActiveTime: m.getActiveTime(tokenValue),
+		Extra:      meta.Extra,
 	}, nil
 }
 
@@ -638,6 +932,16 @@ func (m *Manager) toStringSlice(v any) []string {
 	}
 }
 
+// containsString Checks if slice contains item | 检查切片是否包含元素
+func containsString(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
+
 // ============ Public Getters | 公共获取器 ============
 
 // GetConfig Gets configuration | 获取配置
@@ -650,6 +954,19 @@ func (m *Manager) GetStorage() adapter.Storage {
 	return m.storage
 }
 
+// GetEventManager Gets the event manager fed by login, logout, kickout, disable and untie |
+// 获取事件管理器，登录、登出、踢下线、封禁和解封时触发事件
+func (m *Manager) GetEventManager() *listener.Manager {
+	return m.eventManager
+}
+
+// SetEventManager Replaces the event manager, e.g. to share one across managers | 替换事件管理器，例如在多个管理器间共享
+func (m *Manager) SetEventManager(eventManager *listener.Manager) {
+	if eventManager != nil {
+		m.eventManager = eventManager
+	}
+}
+
 // ============ Security Features | 安全特性 ============
 
 // GenerateNonce Generates a one-time nonce | 生成一次性随机数
@@ -662,21 +979,6 @@ func (m *Manager) VerifyNonce(nonce string) bool {
 	return m.nonceManager.Verify(nonce)
 }
 
-// LoginWithRefreshToken Logs in with refresh token | 使用刷新令牌登录
-func (m *Manager) LoginWithRefreshToken(loginID, device string) (*security.RefreshTokenInfo, error) {
-	return m.refreshManager.GenerateTokenPair(loginID, device)
-}
-
-// RefreshAccessToken Refreshes access token | 刷新访问令牌
-func (m *Manager) RefreshAccessToken(refreshToken string) (*security.RefreshTokenInfo, error) {
-	return m.refreshManager.RefreshAccessToken(refreshToken)
-}
-
-// RevokeRefreshToken Revokes refresh token | 撤销刷新令牌
-func (m *Manager) RevokeRefreshToken(refreshToken string) error {
-	return m.refreshManager.RevokeRefreshToken(refreshToken)
-}
-
 // GetOAuth2Server Gets OAuth2 server instance | 获取OAuth2服务器实例
 func (m *Manager) GetOAuth2Server() *oauth2.OAuth2Server {
 	return m.oauth2Server
@@ -0,0 +1,301 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"suwei.sa_token/core/config"
+	"suwei.sa_token/core/jwk"
+	"suwei.sa_token/core/token"
+)
+
+// mapStorage minimal in-memory adapter.Storage for tests, safe for the async auto-renew
+type mapStorage struct {
+	mu    sync.Mutex
+	m     map[string]any
+	ttls  map[string]time.Duration // Expiration of the last Set of each key | 每个键最近一次Set的过期时间
+	reads int
+}
+
+func newMapStorage() *mapStorage {
+	return &mapStorage{m: map[string]any{}, ttls: map[string]time.Duration{}}
+}
+
+func (s *mapStorage) Set(key string, value any, expiration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[key] = value
+	s.ttls[key] = expiration
+	return nil
+}
+func (s *mapStorage) Get(key string) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reads++
+	return s.m[key], nil
+}
+func (s *mapStorage) Exists(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reads++
+	_, ok := s.m[key]
+	return ok
+}
+func (s *mapStorage) CompareAndSwap(key string, oldValue, newValue string, _ time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if current, _ := s.m[key].(string); current != oldValue {
+		return false, nil
+	}
+	s.m[key] = newValue
+	return true, nil
+}
+func (s *mapStorage) Delete(keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, k := range keys {
+		delete(s.m, k)
+	}
+	return nil
+}
+func (s *mapStorage) Keys(pattern string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for k := range s.m {
+		if strings.HasPrefix(k, strings.TrimSuffix(pattern, "*")) {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+func (s *mapStorage) Expire(string, time.Duration) error { return nil }
+func (s *mapStorage) TTL(string) (time.Duration, error)  { return -1, nil }
+func (s *mapStorage) Clear() error                       { return nil }
+func (s *mapStorage) Ping() error                        { return nil }
+
+func newTestManager(cfg *config.Config) *Manager {
+	return NewManager(newMapStorage(), cfg)
+}
+
+func jwtConfig(mode config.JwtMode) *config.Config {
+	return config.DefaultConfig().SetTokenStyle(config.TokenStyleJWT).SetJwtSecretKey("secret").SetJwtMode(mode)
+}
+
+func TestJwtModes(t *testing.T) {
+	newJwtManager := func(mode config.JwtMode) (*Manager, *mapStorage) {
+		storage := newMapStorage()
+		mgr := NewManager(storage, jwtConfig(mode))
+		mgr.SetJwtClaimsLoader(func(string) ([]string, []string, error) {
+			return []string{"user:*"}, []string{"admin"}, nil
+		})
+		return mgr, storage
+	}
+
+	// Mixin: claims carry permissions, storage only keeps logout and kickout lists |
+	// Mixin：声明携带权限，存储只保留登出和踢下线列表
+	mgr, storage := newJwtManager(config.JwtModeMixin)
+	tokenValue, err := mgr.Login("1001")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if !mgr.IsLogin(tokenValue) || !mgr.HasPermissionByToken(tokenValue, "user:add") || !mgr.HasRoleByToken(tokenValue, "admin") || len(storage.m) != 0 {
+		t.Errorf("mixin login: storage = %v", storage.m)
+	}
+	if _, err := mgr.GetTokenValue("1001"); !errors.Is(err, ErrJwtModeUnsupported) {
+		t.Errorf("GetTokenValue() error = %v", err)
+	}
+	if err := mgr.LogoutByToken(tokenValue); err != nil || mgr.IsLogin(tokenValue) {
+		t.Errorf("revoked token still valid, error = %v", err)
+	}
+	tokenValue, _ = mgr.Login("1001")
+	if err := mgr.Kickout("1001"); err != nil || mgr.IsLogin(tokenValue) {
+		t.Errorf("kicked token still valid, error = %v", err)
+	}
+	if fresh, _ := mgr.Login("1001"); !mgr.IsLogin(fresh) {
+		t.Error("token issued after kickout should be valid")
+	}
+	// The kickout time outlives tokens with a longer per-login timeout | 踢下线时间比单次登录超时更长的Token存活更久
+	if ttl, ok := storage.ttls[mgr.getJwtKickedKey("1001", "default")]; !ok || ttl != 0 {
+		t.Errorf("kickout time expiration = %v, want none", ttl)
+	}
+
+	// Stateless: nothing is stored and revocation is unsupported | Stateless：不存储任何数据且不支持注销
+	mgr, storage = newJwtManager(config.JwtModeStateless)
+	tokenValue, _ = mgr.Login("1002")
+	if id, err := mgr.GetLoginID(tokenValue); id != "1002" || err != nil || len(storage.m) != 0 {
+		t.Errorf("stateless GetLoginID() = %q, %v, storage = %v", id, err, storage.m)
+	}
+	if err := mgr.Kickout("1002"); !errors.Is(err, ErrJwtModeUnsupported) {
+		t.Errorf("Kickout() error = %v", err)
+	}
+	if err := mgr.LogoutByToken(tokenValue); !errors.Is(err, ErrJwtModeUnsupported) {
+		t.Errorf("LogoutByToken() error = %v", err)
+	}
+	if mgr.IsLogin(tokenValue + "x") {
+		t.Error("forged token should be rejected")
+	}
+
+	// PASETO plugs into the same modes | PASETO使用相同的模式
+	key, _ := jwk.GenerateKey("k1", jwk.AlgEdDSA)
+	ks := jwk.NewKeySet()
+	ks.SetSigningKey(key)
+	storage = newMapStorage()
+	mgr = NewManager(storage, config.DefaultConfig().
+		SetTokenStyle(config.TokenStylePasetoV4Public).
+		SetJwtKeySet(ks).
+		SetJwtMode(config.JwtModeMixin))
+	tokenValue, _ = mgr.Login("1003")
+	if !strings.HasPrefix(tokenValue, "v4.public.") || !mgr.IsLogin(tokenValue) || len(storage.m) != 0 {
+		t.Fatalf("paseto login = %q, storage = %v", tokenValue, storage.m)
+	}
+	if err := mgr.LogoutByToken(tokenValue); err != nil || mgr.IsLogin(tokenValue) {
+		t.Errorf("revoked paseto token still valid, error = %v", err)
+	}
+}
+
+func TestLoginWithOptions(t *testing.T) {
+	storage := newMapStorage()
+	mgr := NewManager(storage, config.DefaultConfig())
+
+	// Kiosk login: short timeout, device ID and extra data | 自助终端登录：短超时、设备ID和额外数据
+	tokenValue, err := mgr.LoginWithOptions("1001", &LoginOptions{
+		Device:   "kiosk",
+		DeviceID: "kiosk-7",
+		Timeout:  600,
+		Extra:    map[string]any{"tenantId": "t1"},
+	})
+	if err != nil {
+		t.Fatalf("LoginWithOptions() error = %v", err)
+	}
+	if mgr.GetTokenTimeout(tokenValue) != 600 {
+		t.Errorf("token timeout = %d, want 600", mgr.GetTokenTimeout(tokenValue))
+	}
+	info, err := mgr.GetTokenInfo(tokenValue)
+	if err != nil || info.Device != "kiosk" || info.DeviceID != "kiosk-7" || info.CreateTime == 0 || info.Extra["tenantId"] != "t1" {
+		t.Fatalf("GetTokenInfo() = %+v, %v", info, err)
+	}
+	if got, _ := mgr.GetTokenValue("1001", "kiosk"); got == "" {
+		t.Error("account mapping should use the option device")
+	}
+
+	// Active timeout freezes idle tokens, logout removes the metadata |
+	// 活跃超时冻结空闲Token，登出删除元数据
+	tokenValue, _ = mgr.LoginWithOptions("1002", &LoginOptions{Token: "preset-token", ActiveTimeout: 60})
+	if tokenValue != "preset-token" || !mgr.IsLogin(tokenValue) {
+		t.Fatalf("preset token = %q not logged in", tokenValue)
+	}
+	delete(storage.m, "satoken:"+ActiveKeyPrefix+tokenValue)
+	if mgr.IsLogin(tokenValue) {
+		t.Error("token past its active timeout should be frozen")
+	}
+	mgr.LogoutByToken(tokenValue)
+	if _, ok := storage.m["satoken:"+TokenMetaKeyPrefix+tokenValue]; ok {
+		t.Error("logout should remove token metadata")
+	}
+
+	// JWT: extra data travels as claims | JWT：额外数据以声明形式携带
+	mgr = newTestManager(jwtConfig(config.JwtModeStateless))
+	tokenValue, _ = mgr.LoginWithOptions("1003", &LoginOptions{Timeout: 60, DeviceID: "d1", Extra: map[string]any{"scope": "read"}})
+	info, err = mgr.GetTokenInfo(tokenValue)
+	if err != nil || info.Extra["scope"] != "read" || info.DeviceID != "d1" || len(info.Extra) != 1 || mgr.GetTokenTimeout(tokenValue) != 60 {
+		t.Errorf("jwt GetTokenInfo() = %+v, %v", info, err)
+	}
+	if _, err := mgr.LoginWithOptions("1003", &LoginOptions{Token: "preset"}); !errors.Is(err, ErrJwtModeUnsupported) {
+		t.Errorf("preset token in stateless mode error = %v", err)
+	}
+}
+
+func TestTokenHashPepper(t *testing.T) {
+	storage := newMapStorage()
+	mgr := NewManager(storage, config.DefaultConfig().SetTokenHashPepper("0123456789abcdef-pepper"))
+
+	tokenValue, err := mgr.Login("1001")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if err := mgr.OpenSafe(tokenValue, "pay", time.Minute); err != nil {
+		t.Fatalf("OpenSafe() error = %v", err)
+	}
+	ticket, err := mgr.IssueTicket(tokenValue, time.Minute)
+	if err != nil {
+		t.Fatalf("IssueTicket() error = %v", err)
+	}
+
+	// Neither keys nor values hold the raw token | 键和值中都不包含原始Token
+	for key, value := range storage.m {
+		if strings.Contains(key, tokenValue) || strings.Contains(fmt.Sprint(value), tokenValue) {
+			t.Errorf("storage entry %q = %v leaks the token", key, value)
+		}
+	}
+
+	if !mgr.IsLogin(tokenValue) || !mgr.IsSafe(tokenValue, "pay") {
+		t.Fatal("hashed token should stay logged in and safe")
+	}
+	if loginID, err := mgr.GetLoginID(tokenValue); err != nil || loginID != "1001" {
+		t.Errorf("GetLoginID() = %q, %v", loginID, err)
+	}
+	if got, err := mgr.ConsumeTicket(ticket); err != nil || got != tokenValue {
+		t.Errorf("ConsumeTicket() = %q, %v, want the raw token", got, err)
+	}
+	if tokens, _ := mgr.GetTokenValueListByLoginID("1001"); len(tokens) != 1 || tokens[0] != mgr.TokenID(tokenValue) {
+		t.Errorf("GetTokenValueListByLoginID() = %v, want token IDs", tokens)
+	}
+
+	// Kickout by login ID works on token IDs | 按登录ID踢下线使用Token ID
+	if err := mgr.Kickout("1001"); err != nil {
+		t.Fatalf("Kickout() error = %v", err)
+	}
+	if mgr.IsLogin(tokenValue) {
+		t.Error("kicked out token should not be logged in")
+	}
+	for key := range storage.m {
+		if strings.Contains(key, mgr.TokenID(tokenValue)) && !strings.Contains(key, SafeKeyPrefix) {
+			t.Errorf("kickout left %q behind", key)
+		}
+	}
+
+	tokenValue, _ = mgr.Login("1001")
+	if err := mgr.Logout("1001"); err != nil || mgr.IsLogin(tokenValue) {
+		t.Errorf("Logout() error = %v, still logged in = %v", err, mgr.IsLogin(tokenValue))
+	}
+}
+
+func TestSignedTokenRejectsForgery(t *testing.T) {
+	key, _ := jwk.NewHMACKey("k1", []byte("tag-secret"))
+	ks := jwk.NewKeySet()
+	ks.SetSigningKey(key)
+	storage := newMapStorage()
+	mgr := NewManager(storage, config.DefaultConfig().SetTokenSignKeySet(ks))
+
+	tokenValue, err := mgr.Login("1001")
+	if err != nil || !mgr.IsLogin(tokenValue) {
+		t.Fatalf("Login() = %q, %v, want a logged-in token", tokenValue, err)
+	}
+
+	// Forged tokens never reach storage | 伪造的Token不会访问存储
+	storage.reads = 0
+	for _, forged := range []string{"garbage", tokenValue + "x", strings.Replace(tokenValue, ".", "x.", 1)} {
+		if mgr.IsLogin(forged) {
+			t.Errorf("IsLogin(%q) = true", forged)
+		}
+		if _, err := mgr.GetLoginID(forged); err == nil {
+			t.Errorf("GetLoginID(%q) should fail", forged)
+		}
+	}
+	if storage.reads != 0 {
+		t.Errorf("forged tokens caused %d storage reads", storage.reads)
+	}
+
+	if _, err := mgr.LoginWithOptions("1002", &LoginOptions{Token: "preset"}); !errors.Is(err, token.ErrUnsignedToken) {
+		t.Errorf("unsigned preset token error = %v", err)
+	}
+	signed, _ := mgr.SignToken("preset")
+	if _, err := mgr.LoginWithOptions("1002", &LoginOptions{Token: signed}); err != nil || !mgr.IsLogin(signed) {
+		t.Errorf("signed preset token error = %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package manager
+
+import (
+	"suwei.sa_token/core/permission"
+	"suwei.sa_token/core/session"
+)
+
+// Permission Matchers per Login
+// 按登录ID缓存的权限匹配器
+//
+// HasPermission compiles the effective permissions of a login ID once and reuses the matcher
+// while the session version is unchanged and no grant in it has expired. A check then reads the
+// short version key from storage, which keeps other instances' changes visible, but neither reads
+// the session nor hashes the permission set. Stateless tokens key their matcher on the token itself.
+// HasPermission 将登录ID的有效权限编译一次，只要Session版本未变化且其中的授权未过期就复用该匹配器。
+// 检查时仅从存储读取很短的版本键，因此其他实例的修改立即可见，但无需读取Session或哈希权限集合。
+// 无状态Token以Token本身作为匹配器的键。
+
+// loginMatcher Matcher built from one version of a session | 由Session某一版本构建的匹配器
+type loginMatcher struct {
+	version string              // Session version it was built from | 构建时的Session版本
+	matcher *permission.Matcher // Compiled effective permissions | 编译后的有效权限
+	expires int64               // Earliest grant expiry in Unix seconds, 0 when none | 最早的授权过期时间（Unix秒），无授权时为0
+}
+
+// permissionMatcher Gets the matcher of a login ID's effective permissions | 获取登录ID有效权限的匹配器
+func (m *Manager) permissionMatcher(loginID string) (*permission.Matcher, error) {
+	version := m.sessionVersion(loginID)
+	if version != "" {
+		m.matcherMu.RLock()
+		cached := m.loginMatchers[loginID]
+		m.matcherMu.RUnlock()
+		if cached != nil && cached.version == version && (cached.expires == 0 || timeNow().Unix() < cached.expires) {
+			return cached.matcher, nil
+		}
+	}
+
+	perms, expires, err := m.effectivePermissions(loginID)
+	if err != nil {
+		return nil, err
+	}
+	matcher := m.permCache.Get(perms)
+	if version != "" {
+		m.matcherMu.Lock()
+		// Reset when full like the set cache | 满时重置，与集合缓存一致
+		if len(m.loginMatchers) >= permission.DefaultCacheSize {
+			m.loginMatchers = make(map[string]*loginMatcher)
+		}
+		m.loginMatchers[loginID] = &loginMatcher{version: version, matcher: matcher, expires: expires}
+		m.matcherMu.Unlock()
+	}
+	return matcher, nil
+}
+
+// sessionVersion Gets the version of a stored session, its data when saved before versions existed, empty if none |
+// 获取存储中Session的版本，版本出现前保存的Session返回其数据，不存在时为空
+func (m *Manager) sessionVersion(loginID string) string {
+	if data, _ := m.storage.Get(session.VersionKey(m.prefix, loginID)); data != nil {
+		if version, ok := data.(string); ok && version != "" {
+			return version
+		}
+	}
+	data, _ := m.storage.Get(m.prefix + session.SessionKeyPrefix + loginID)
+	raw, _ := data.(string)
+	return raw
+}
+
+// tokenPermissionMatcher Gets the matcher of a token's permissions, from claims in JWT mixin and stateless modes |
+// 获取Token权限的匹配器，JWT mixin和stateless模式下来自声明
+func (m *Manager) tokenPermissionMatcher(tokenValue string) (*permission.Matcher, error) {
+	if m.isClaimsMode() {
+		perms, err := m.GetPermissionsByToken(tokenValue)
+		if err != nil {
+			return nil, err
+		}
+		if m.isStateless() {
+			// The signed token fixes its permissions, mixin adds grants from the session | 签名Token决定其权限，mixin还会加入Session中的授权
+			return m.permCache.GetKeyed(tokenValue, perms), nil
+		}
+		return m.permCache.Get(perms), nil
+	}
+
+	loginID, err := m.GetLoginID(tokenValue)
+	if err != nil {
+		return nil, err
+	}
+	return m.permissionMatcher(loginID)
+}
+
+// effectivePermissions Gets static permissions plus active grants from one session read, and when the earliest grant expires |
+// 通过一次Session读取获取静态权限及有效授权，以及最早的授权过期时间
+func (m *Manager) effectivePermissions(loginID string) ([]string, int64, error) {
+	sess, err := m.GetSession(loginID)
+	if err != nil {
+		return nil, 0, err
+	}
+	perms := []string{}
+	if value, exists := sess.Get(SessionKeyPermissions); exists {
+		perms = m.toStringSlice(value)
+	}
+
+	var expires int64
+	for _, g := range decodeGrants(sess, SessionKeyPermissionGrants) {
+		if !g.IsActive() {
+			continue
+		}
+		perms = append(perms[:len(perms):len(perms)], g.Value)
+		if expires == 0 || g.ExpireTime < expires {
+			expires = g.ExpireTime
+		}
+	}
+	return perms, expires, nil
+}
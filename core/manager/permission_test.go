@@ -0,0 +1,69 @@
+package manager
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"suwei.sa_token/core/config"
+	"suwei.sa_token/core/session"
+)
+
+func TestPermissionMatcherCache(t *testing.T) {
+	storage := newMapStorage()
+	mgr := NewManager(storage, config.DefaultConfig())
+	other := NewManager(storage, config.DefaultConfig())
+
+	mgr.SetPermissions("1001", []string{"user:read"})
+	if !mgr.HasPermission("1001", "user:read") || mgr.HasPermission("1001", "user:write") {
+		t.Fatal("HasPermission() should follow the stored permissions")
+	}
+
+	// Changes on another instance apply at once | 其他实例上的修改立即生效
+	other.SetPermissions("1001", []string{"user:*"})
+	if !mgr.HasPermission("1001", "user:write") {
+		t.Error("HasPermission() should see permissions set on another instance")
+	}
+
+	// Grants invalidate the cached matcher too | 授权同样使缓存的匹配器失效
+	mgr.GrantPermission("1001", "order:read", time.Hour)
+	if !mgr.HasPermissionsAnd("1001", []string{"user:read", "order:read"}) {
+		t.Error("HasPermissionsAnd() should include the grant")
+	}
+	mgr.RevokePermissionGrant("1001", "order:read")
+	if mgr.HasPermissionsOr("1001", []string{"order:read", "order:write"}) {
+		t.Error("HasPermissionsOr() should drop the revoked grant")
+	}
+
+	// A hit reads only the session version | 命中时只读取Session版本
+	mgr.HasPermission("1001", "user:read")
+	reads := storage.reads
+	if !mgr.HasPermission("1001", "user:read") || storage.reads-reads != 1 {
+		t.Errorf("cached check read storage %d times, want 1", storage.reads-reads)
+	}
+
+	// Sessions saved before versions existed are keyed on their data | 版本出现前保存的Session以其数据为键
+	storage.Delete(session.VersionKey(mgr.prefix, "1001"))
+	if !mgr.HasPermission("1001", "user:read") || !mgr.HasPermission("1001", "user:write") {
+		t.Error("HasPermission() should work for sessions without a version")
+	}
+
+	if !mgr.MatchPermission("user:{read,write}", "user:write") || mgr.MatchPermission("user:read", "user:write") {
+		t.Error("MatchPermission() mismatch")
+	}
+}
+
+func BenchmarkHasPermission1000(b *testing.B) {
+	mgr := newTestManager(config.DefaultConfig())
+	perms := make([]string, 1000)
+	for i := range perms {
+		perms[i] = fmt.Sprintf("module%d:action%d", i, i)
+	}
+	mgr.SetPermissions("1001", perms)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		mgr.HasPermission("1001", "module999:action999")
+	}
+}
@@ -0,0 +1,258 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"suwei.sa_token/core/listener"
+	"suwei.sa_token/core/security"
+	"suwei.sa_token/core/token"
+)
+
+// Refresh Tokens
+// 刷新令牌
+//
+// LoginWithRefreshToken logs the access token in like Login and pairs it with a refresh token
+// that lives for Config.RefreshTokenTimeout. RefreshAccessToken spends the refresh token: it logs
+// a new access token in for the same account and device, retires the previous one and returns a
+// new refresh token of the same family. The family keeps the login options, so every new access
+// token gets the timeout, extra data, device ID and client binding of the first one. A refresh
+// claims the family with a compare-and-swap in storage before logging in, and hands it back if
+// the login fails. Concurrent refreshes with one token, on any instance, leave one winner at
+// most; the others are reuse and revoke the family. This needs a storage implementing
+// adapter.AtomicStorage, otherwise only refreshes within one process are exclusive.
+// LoginWithRefreshToken 像 Login 一样登录访问令牌，并与有效期为 Config.RefreshTokenTimeout 的刷新令牌配对。
+// RefreshAccessToken 会消费刷新令牌：为同一账号和设备登录新的访问令牌，使之前的访问令牌失效，并返回同一家族的
+// 新刷新令牌。家族保留登录选项，因此每个新访问令牌都沿用首次登录的超时时间、额外数据、设备ID和客户端绑定。
+// 刷新在登录之前先通过存储中的比较并交换占有家族，登录失败时归还。在任意实例上使用同一令牌的并发刷新最多只有
+// 一个成功，其余视为重用并撤销家族。这需要存储实现 adapter.AtomicStorage，否则只有同一进程内的刷新是互斥的。
+//
+// Presenting a spent refresh token revokes its family, retires the family's access token, fires
+// EventRefreshTokenReuse and returns security.ErrRefreshTokenReused. Revoking refresh tokens
+// retires their access tokens too. Refresh records keep the token ID of the access token, and with
+// Config.TokenHashPepper set are keyed by the token ID of the refresh token.
+// 出示已消费的刷新令牌会撤销其家族、使家族的访问令牌失效、触发 EventRefreshTokenReuse 并返回
+// security.ErrRefreshTokenReused。撤销刷新令牌同样会使其访问令牌失效。刷新记录保存访问令牌的Token ID，
+// 设置 Config.TokenHashPepper 后以刷新令牌的Token ID为键。
+//
+// Retired JWT and PASETO access tokens are revoked until their own exp, kept in the refresh record.
+// Stateless mode needs Config.JwtRevocation for that, without it they stay valid until they expire.
+// 失效的JWT和PASETO访问令牌被撤销直至其自身的exp（保存在刷新记录中）。stateless模式需要设置
+// Config.JwtRevocation，否则失效的访问令牌在过期前仍然有效。
+
+// refreshOptions Login options a refresh family reissues its access tokens with | 刷新家族重新签发访问令牌时使用的登录选项
+type refreshOptions struct {
+	DeviceID      string             `json:"deviceId,omitempty"`
+	Timeout       int64              `json:"timeout,omitempty"`
+	ActiveTimeout int64              `json:"activeTimeout,omitempty"`
+	Extra         map[string]any     `json:"extra,omitempty"`
+	RememberMe    *bool              `json:"rememberMe,omitempty"`
+	Fingerprint   *ClientFingerprint `json:"fingerprint,omitempty"`
+}
+
+// LoginWithRefreshToken Logs in and returns the access token with a refresh token | 登录并返回访问令牌和刷新令牌
+func (m *Manager) LoginWithRefreshToken(loginID, device string) (*security.RefreshTokenInfo, error) {
+	return m.LoginWithRefreshTokenOptions(loginID, &LoginOptions{Device: device})
+}
+
+// LoginWithRefreshTokenOptions Logs in with per-login options and returns the access token with a refresh token |
+// 使用单次登录选项登录并返回访问令牌和刷新令牌
+func (m *Manager) LoginWithRefreshTokenOptions(loginID string, opts *LoginOptions) (*security.RefreshTokenInfo, error) {
+	options, err := m.resolveLoginOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	// Keep zero values so refreshes follow Config | 保留零值，使刷新遵循Config
+	var raw LoginOptions
+	if opts != nil {
+		raw = *opts
+	}
+	data, err := json.Marshal(&refreshOptions{
+		DeviceID:      raw.DeviceID,
+		Timeout:       raw.Timeout,
+		ActiveTimeout: raw.ActiveTimeout,
+		Extra:         raw.Extra,
+		RememberMe:    raw.RememberMe,
+		Fingerprint:   raw.Fingerprint,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal refresh options: %w", err)
+	}
+
+	tokenValue, err := m.LoginWithOptions(loginID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	access := &security.RefreshTokenInfo{LoginID: loginID, Device: options.Device, Options: data}
+	m.describeAccessToken(tokenValue, access)
+	info, err := m.refreshManager.IssueFor(access)
+	if err != nil {
+		m.LogoutByToken(tokenValue)
+		return nil, err
+	}
+	info.AccessToken = tokenValue
+	return info, nil
+}
+
+// RefreshAccessToken Spends a refresh token for a new access token and refresh token, the previous
+// access token is logged out | 消费刷新令牌换取新的访问令牌和刷新令牌，之前的访问令牌被登出
+func (m *Manager) RefreshAccessToken(refreshToken string) (*security.RefreshTokenInfo, error) {
+	var tokenValue string
+	info, err := m.refreshManager.Rotate(refreshToken, func(old, next *security.RefreshTokenInfo) error {
+		opts, err := m.refreshLoginOptions(old)
+		if err != nil {
+			return err
+		}
+		if tokenValue, err = m.LoginWithOptions(old.LoginID, opts); err != nil {
+			return err
+		}
+		if err := m.retireAccessToken(old); err != nil {
+			m.LogoutByToken(tokenValue)
+			return err
+		}
+		m.describeAccessToken(tokenValue, next)
+		return nil
+	}, m.onRefreshTokenReuse)
+	if err != nil {
+		if tokenValue != "" {
+			m.LogoutByToken(tokenValue)
+		}
+		return nil, err
+	}
+	info.AccessToken = tokenValue
+	return info, nil
+}
+
+// RevokeRefreshToken Revokes a refresh token with its family and logs out its access token |
+// 撤销刷新令牌及其家族，并登出其访问令牌
+func (m *Manager) RevokeRefreshToken(refreshToken string) error {
+	latest, err := m.refreshManager.RevokeFamily(refreshToken)
+	if err != nil || latest == nil {
+		return err
+	}
+	return m.retireAccessToken(latest)
+}
+
+// GetRefreshTokenInfo Gets a refresh token record, its AccessToken is a token ID | 获取刷新令牌记录，其AccessToken为Token ID
+func (m *Manager) GetRefreshTokenInfo(refreshToken string) (*security.RefreshTokenInfo, error) {
+	return m.refreshManager.GetRefreshTokenInfo(refreshToken)
+}
+
+// GetRefreshTokenListByLoginID Gets the current refresh token of every login of an account, optionally of one device |
+// 获取账号每次登录的当前刷新令牌，可只获取某一设备的
+func (m *Manager) GetRefreshTokenListByLoginID(loginID string, device ...string) ([]*security.RefreshTokenInfo, error) {
+	return m.refreshManager.ListRefreshTokens(loginID, deviceFilter(device))
+}
+
+// RevokeRefreshTokensByLoginID Revokes every refresh token of an account, optionally of one device, and logs out their access tokens |
+// 撤销账号的所有刷新令牌（可只撤销某一设备的），并登出其访问令牌
+func (m *Manager) RevokeRefreshTokensByLoginID(loginID string, device ...string) error {
+	revoked, err := m.refreshManager.RevokeRefreshTokens(loginID, deviceFilter(device))
+	if err != nil {
+		return err
+	}
+	for _, info := range revoked {
+		if err := m.retireAccessToken(info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshLoginOptions Gets the login options kept with a refresh family | 获取刷新家族保存的登录选项
+func (m *Manager) refreshLoginOptions(info *security.RefreshTokenInfo) (*LoginOptions, error) {
+	var stored refreshOptions
+	if len(info.Options) > 0 {
+		if err := json.Unmarshal(info.Options, &stored); err != nil {
+			return nil, fmt.Errorf("%w: %v", security.ErrInvalidRefreshData, err)
+		}
+	}
+	return &LoginOptions{
+		Device:        info.Device,
+		DeviceID:      stored.DeviceID,
+		Timeout:       stored.Timeout,
+		ActiveTimeout: stored.ActiveTimeout,
+		Extra:         stored.Extra,
+		RememberMe:    stored.RememberMe,
+		Fingerprint:   stored.Fingerprint,
+	}, nil
+}
+
+// onRefreshTokenReuse Retires the access token of a revoked family and reports the reuse |
+// 使被撤销家族的访问令牌失效并上报重用
+func (m *Manager) onRefreshTokenReuse(latest *security.RefreshTokenInfo) {
+	m.retireAccessToken(latest)
+
+	event := listener.EventRefreshTokenReuse
+	if !m.eventManager.HasListeners(event) && !m.eventManager.HasListeners(listener.EventAll) {
+		return
+	}
+	m.eventManager.Trigger(&listener.EventData{
+		Event:   event,
+		LoginID: latest.LoginID,
+		Device:  latest.Device,
+		Token:   latest.AccessToken,
+		Extra:   map[string]any{"familyId": latest.FamilyID},
+	})
+}
+
+// describeAccessToken Fills the access token fields of a refresh record, with exp and jti of claims tokens |
+// 填写刷新记录的访问令牌字段，声明类Token还包括exp和jti
+func (m *Manager) describeAccessToken(tokenValue string, record *security.RefreshTokenInfo) {
+	record.AccessToken = m.TokenID(tokenValue)
+	if !m.isClaimsMode() {
+		return
+	}
+	claims, err := m.generator.ParseJWT(tokenValue)
+	if err != nil {
+		return
+	}
+	record.AccessExpireTime = claimInt64(claims, "exp")
+	record.AccessJTI, _ = claims[token.ClaimJTI].(string)
+}
+
+// retireAccessToken Invalidates the access token of a refresh record until it expires, without logout events |
+// 使刷新记录的访问令牌失效直至其过期，不触发登出事件
+func (m *Manager) retireAccessToken(info *security.RefreshTokenInfo) error {
+	if info.AccessToken == "" {
+		return nil
+	}
+	if !m.isClaimsMode() {
+		return m.storage.Delete(m.tokenDataKeysByID(info.AccessToken)...)
+	}
+
+	var ttl time.Duration
+	var exp time.Time
+	if info.AccessExpireTime > 0 {
+		exp = time.Unix(info.AccessExpireTime, 0)
+		if ttl = time.Until(exp); ttl <= 0 {
+			return nil // Expired already | 已过期
+		}
+	}
+	switch {
+	case m.revocations != nil && info.AccessJTI != "":
+		if err := m.revocations.Revoke(info.AccessJTI, exp); err != nil {
+			return err
+		}
+	case m.isStateless():
+		return nil // No revocation list, expires on its own | 无撤销列表，自行过期
+	default:
+		if err := m.storage.Set(m.tokenIDKey(JwtRevokedKeyPrefix, info.AccessToken), DisableValue, ttl); err != nil {
+			return err
+		}
+	}
+	if m.isStateless() {
+		return nil
+	}
+	return m.storage.Delete(m.tokenIDKey(CSRFKeyPrefix, info.AccessToken))
+}
+
+// deviceFilter Gets the optional device of a query, empty for all devices | 获取查询的可选设备，为空表示所有设备
+func deviceFilter(device []string) string {
+	if len(device) > 0 {
+		return device[0]
+	}
+	return ""
+}
@@ -0,0 +1,266 @@
+package manager
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"suwei.sa_token/core/config"
+	"suwei.sa_token/core/listener"
+	"suwei.sa_token/core/security"
+)
+
+func TestRefreshTokenLogin(t *testing.T) {
+	mgr := newTestManager(config.DefaultConfig().SetRefreshTokenTimeout(3600))
+
+	info, err := mgr.LoginWithRefreshToken("1001", "app")
+	if err != nil {
+		t.Fatalf("LoginWithRefreshToken() error = %v", err)
+	}
+	if !mgr.IsLogin(info.AccessToken) {
+		t.Fatal("access token of a refresh login should be logged in")
+	}
+	if got, _ := mgr.GetTokenValue("1001", "app"); got != info.AccessToken {
+		t.Errorf("GetTokenValue() = %q, want the access token", got)
+	}
+	if ttl := info.ExpireTime - info.CreateTime; ttl != 3600 {
+		t.Errorf("refresh token lifetime = %d, want 3600", ttl)
+	}
+
+	// Refresh swaps both tokens | 刷新替换两个令牌
+	next, err := mgr.RefreshAccessToken(info.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshAccessToken() error = %v", err)
+	}
+	if mgr.IsLogin(info.AccessToken) || !mgr.IsLogin(next.AccessToken) {
+		t.Errorf("after refresh old logged in = %v, new logged in = %v", mgr.IsLogin(info.AccessToken), mgr.IsLogin(next.AccessToken))
+	}
+	if next.RefreshToken == info.RefreshToken || next.FamilyID != info.FamilyID {
+		t.Errorf("refresh should rotate the refresh token within its family, got %+v", next)
+	}
+
+	// A failed refresh leaves the current access token in place | 刷新失败时保留当前访问令牌
+	mgr.Disable("1001", time.Minute)
+	if _, err := mgr.RefreshAccessToken(next.RefreshToken); !errors.Is(err, ErrAccountDisabled) {
+		t.Errorf("refresh of a disabled account error = %v", err)
+	}
+	if record, _ := mgr.GetRefreshTokenInfo(next.RefreshToken); record.AccessToken != mgr.TokenID(next.AccessToken) {
+		t.Errorf("failed refresh changed the record to %q", record.AccessToken)
+	}
+	mgr.Untie("1001")
+
+	// Concurrent refreshes on two instances: any loser is reuse and revokes the family, so no access token survives |
+	// 两个实例上的并发刷新：失败者视为重用并撤销家族，因此没有访问令牌存活
+	instances := []*Manager{mgr, NewManager(mgr.storage, mgr.config)}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	tokens := make([]string, 8)
+	refreshed, reused := 0, 0
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rotated, err := instances[i%2].RefreshAccessToken(next.RefreshToken)
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				tokens[i] = rotated.AccessToken
+				refreshed++
+			} else if errors.Is(err, security.ErrRefreshTokenReused) {
+				reused++
+			}
+		}(i)
+	}
+	wg.Wait()
+	valid := 0
+	for _, tokenValue := range append(tokens, next.AccessToken) {
+		if mgr.IsLogin(tokenValue) {
+			valid++
+		}
+	}
+	if refreshed > 1 || reused == 0 || valid != 0 {
+		t.Errorf("concurrent refreshes: %d succeeded, %d reuses, %d access tokens valid", refreshed, reused, valid)
+	}
+
+	// JWT mixin: the previous access token is revoked | JWT混合模式：之前的访问令牌被撤销
+	mgr = newTestManager(jwtConfig(config.JwtModeMixin))
+	info, _ = mgr.LoginWithRefreshToken("1002", "")
+	next, err = mgr.RefreshAccessToken(info.RefreshToken)
+	if err != nil || mgr.IsLogin(info.AccessToken) || !mgr.IsLogin(next.AccessToken) {
+		t.Errorf("jwt refresh error = %v, old logged in = %v", err, mgr.IsLogin(info.AccessToken))
+	}
+}
+
+func TestRefreshTokenRetiresClaimsTokens(t *testing.T) {
+	// Mixin: the revoked marker lives as long as the token, not Config.Timeout | Mixin：撤销标记与Token同寿命，而非Config.Timeout
+	storage := newMapStorage()
+	cfg := jwtConfig(config.JwtModeMixin)
+	mgr := NewManager(storage, cfg)
+	info, err := mgr.LoginWithRefreshTokenOptions("1001", &LoginOptions{Timeout: 2 * cfg.Timeout})
+	if err != nil {
+		t.Fatalf("LoginWithRefreshTokenOptions() error = %v", err)
+	}
+	if _, err := mgr.RefreshAccessToken(info.RefreshToken); err != nil {
+		t.Fatalf("RefreshAccessToken() error = %v", err)
+	}
+	if ttl := storage.ttls[mgr.getJwtRevokedKey(info.AccessToken)]; ttl <= time.Duration(cfg.Timeout)*time.Second {
+		t.Errorf("retired token marker expiration = %v, want the token's own lifetime", ttl)
+	}
+
+	// Stateless with a revocation list: reuse revokes the stolen access token | 带撤销列表的Stateless：重用会撤销被盗的访问令牌
+	mgr = newTestManager(jwtConfig(config.JwtModeStateless).SetJwtRevocation(&config.JwtRevocationConfig{}))
+	info, _ = mgr.LoginWithRefreshToken("1002", "")
+	next, err := mgr.RefreshAccessToken(info.RefreshToken)
+	if err != nil || mgr.IsLogin(info.AccessToken) {
+		t.Fatalf("stateless refresh error = %v, old logged in = %v", err, mgr.IsLogin(info.AccessToken))
+	}
+	if _, err := mgr.RefreshAccessToken(info.RefreshToken); !errors.Is(err, security.ErrRefreshTokenReused) {
+		t.Fatalf("reuse error = %v", err)
+	}
+	if mgr.IsLogin(next.AccessToken) {
+		t.Error("reuse should revoke the family's stateless access token")
+	}
+}
+
+func TestRefreshTokenKeepsLoginOptions(t *testing.T) {
+	binding := &config.TokenBindingConfig{IPv4Prefix: 24}
+	mgr := newTestManager(config.DefaultConfig().SetTokenBinding(binding).SetIsReadCookie(true))
+	client := mgr.NewClientFingerprint("10.0.0.7:51234", "", "")
+	sessionCookie := false
+
+	info, err := mgr.LoginWithRefreshTokenOptions("1001", &LoginOptions{
+		Device:      "app",
+		DeviceID:    "phone-1",
+		Timeout:     600,
+		Extra:       map[string]any{"tenantId": "t1"},
+		RememberMe:  &sessionCookie,
+		Fingerprint: client,
+	})
+	if err != nil {
+		t.Fatalf("LoginWithRefreshTokenOptions() error = %v", err)
+	}
+	next, err := mgr.RefreshAccessToken(info.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshAccessToken() error = %v", err)
+	}
+	next, err = mgr.RefreshAccessToken(next.RefreshToken)
+	if err != nil {
+		t.Fatalf("second RefreshAccessToken() error = %v", err)
+	}
+
+	tokenInfo, err := mgr.GetTokenInfo(next.AccessToken)
+	if err != nil || tokenInfo.Device != "app" || tokenInfo.DeviceID != "phone-1" || tokenInfo.Extra["tenantId"] != "t1" {
+		t.Errorf("refreshed token info = %+v, error = %v", tokenInfo, err)
+	}
+	if timeout := mgr.GetTokenTimeout(next.AccessToken); timeout <= 0 || timeout > 600 {
+		t.Errorf("refreshed token timeout = %d, want at most 600", timeout)
+	}
+	if err := mgr.CheckBinding(next.AccessToken, mgr.NewClientFingerprint("192.168.1.5", "", "")); !errors.Is(err, ErrTokenBindingMismatch) {
+		t.Errorf("refreshed token should stay bound, CheckBinding() error = %v", err)
+	}
+	if mgr.IsRememberMe(next.AccessToken) {
+		t.Error("refreshed token should keep its session cookie")
+	}
+
+	// Without a choice the refreshed token follows Config.IsRememberMe | 未指定时刷新后的Token遵循Config.IsRememberMe
+	info, _ = mgr.LoginWithRefreshTokenOptions("1002", &LoginOptions{Fingerprint: client})
+	if next, err = mgr.RefreshAccessToken(info.RefreshToken); err != nil || !mgr.IsRememberMe(next.AccessToken) {
+		t.Errorf("refreshed token remember-me = %v, error = %v", mgr.IsRememberMe(next.AccessToken), err)
+	}
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	mgr := newTestManager(config.DefaultConfig())
+	var events []*listener.EventData
+	mgr.GetEventManager().RegisterFuncWithConfig(listener.EventRefreshTokenReuse, func(data *listener.EventData) {
+		events = append(events, data)
+	}, listener.ListenerConfig{})
+
+	first, _ := mgr.LoginWithRefreshToken("1001", "app")
+	second, err := mgr.RefreshAccessToken(first.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshAccessToken() error = %v", err)
+	}
+
+	// A stolen spent token revokes the family | 被盗的已消费令牌撤销整个家族
+	if _, err := mgr.RefreshAccessToken(first.RefreshToken); !errors.Is(err, security.ErrRefreshTokenReused) {
+		t.Fatalf("reuse error = %v", err)
+	}
+	if _, err := mgr.RefreshAccessToken(second.RefreshToken); !errors.Is(err, security.ErrInvalidRefreshToken) {
+		t.Errorf("latest token of a revoked family error = %v", err)
+	}
+	if mgr.IsLogin(second.AccessToken) {
+		t.Error("access token of a revoked family should be logged out")
+	}
+	if len(events) != 1 || events[0].LoginID != "1001" || events[0].Device != "app" || events[0].Extra["familyId"] != first.FamilyID {
+		t.Errorf("reuse events = %+v", events)
+	}
+
+	// List and revoke per account and device | 按账号和设备列出和撤销
+	web, _ := mgr.LoginWithRefreshToken("1001", "web")
+	app, _ := mgr.LoginWithRefreshToken("1001", "app")
+	app, _ = mgr.RefreshAccessToken(app.RefreshToken)
+	mgr.LoginWithRefreshToken("1002", "app")
+	if list, _ := mgr.GetRefreshTokenListByLoginID("1001"); len(list) != 2 {
+		t.Errorf("GetRefreshTokenListByLoginID() = %d tokens, want 2", len(list))
+	}
+	list, _ := mgr.GetRefreshTokenListByLoginID("1001", "app")
+	if len(list) != 1 || list[0].RefreshToken != app.RefreshToken || list[0].AccessToken != mgr.TokenID(app.AccessToken) {
+		t.Errorf("GetRefreshTokenListByLoginID(app) = %+v, want the latest app token", list)
+	}
+
+	if err := mgr.RevokeRefreshTokensByLoginID("1001", "app"); err != nil {
+		t.Fatalf("RevokeRefreshTokensByLoginID() error = %v", err)
+	}
+	if mgr.IsLogin(app.AccessToken) || !mgr.IsLogin(web.AccessToken) {
+		t.Error("revoking app refresh tokens should log out app only")
+	}
+	if err := mgr.RevokeRefreshToken(web.RefreshToken); err != nil || mgr.IsLogin(web.AccessToken) {
+		t.Errorf("RevokeRefreshToken() error = %v, still logged in = %v", err, mgr.IsLogin(web.AccessToken))
+	}
+	if list, _ := mgr.GetRefreshTokenListByLoginID("1001"); len(list) != 0 {
+		t.Errorf("%d refresh tokens left after revocation", len(list))
+	}
+	if list, _ := mgr.GetRefreshTokenListByLoginID("1002"); len(list) != 1 {
+		t.Error("revocation should not touch other accounts")
+	}
+}
+
+func TestRefreshTokenHashed(t *testing.T) {
+	storage := newMapStorage()
+	mgr := NewManager(storage, config.DefaultConfig().SetTokenHashPepper("pepper"))
+
+	info, err := mgr.LoginWithRefreshToken("1001", "app")
+	if err != nil {
+		t.Fatalf("LoginWithRefreshToken() error = %v", err)
+	}
+	next, err := mgr.RefreshAccessToken(info.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshAccessToken() error = %v", err)
+	}
+
+	// Storage only sees token IDs | 存储中只有Token ID
+	for key, value := range storage.m {
+		text, _ := value.(string)
+		for _, refreshToken := range []string{info.RefreshToken, next.RefreshToken} {
+			if strings.Contains(key, refreshToken) || strings.Contains(text, refreshToken) {
+				t.Errorf("raw refresh token stored in %q = %v", key, value)
+			}
+		}
+	}
+	if record, err := mgr.GetRefreshTokenInfo(next.RefreshToken); err != nil || record.RefreshToken != next.RefreshToken {
+		t.Errorf("GetRefreshTokenInfo() = %+v, error = %v", record, err)
+	}
+	if list, _ := mgr.GetRefreshTokenListByLoginID("1001"); len(list) != 1 || list[0].RefreshToken != mgr.TokenID(next.RefreshToken) {
+		t.Errorf("GetRefreshTokenListByLoginID() = %+v, want the token ID", list)
+	}
+
+	if _, err := mgr.RefreshAccessToken(info.RefreshToken); !errors.Is(err, security.ErrRefreshTokenReused) {
+		t.Errorf("reuse of a hashed token error = %v", err)
+	}
+	if mgr.IsLogin(next.AccessToken) {
+		t.Error("reuse should log out the family's access token")
+	}
+}
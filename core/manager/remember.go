@@ -0,0 +1,41 @@
+package manager
+
+// Remember-me
+// 记住我
+//
+// A login either gets a persistent cookie that survives browser restarts ("remember me") or a
+// session cookie. The choice is kept next to the token with the same TTL, so auto-renew can
+// extend persistent cookies and leave session cookies alone. A missing marker means session.
+// 登录可以获得在浏览器重启后仍然有效的持久Cookie（"记住我"），也可以获得会话Cookie。该选择与Token
+// 一起保存且TTL相同，自动续期时据此延长持久Cookie，而不影响会话Cookie。标记不存在即视为会话Cookie。
+
+// RememberKeyPrefix Storage key prefix of remember-me markers | 记住我标记的存储键前缀
+const RememberKeyPrefix = "remember:"
+
+// rememberValue Marker value | 标记值
+const rememberValue = "1"
+
+// SetRememberMe Records whether a token uses a persistent cookie | 记录Token是否使用持久Cookie
+func (m *Manager) SetRememberMe(tokenValue string, remember bool) error {
+	if m.isStateless() {
+		return nil // Stateless tokens are never renewed | 无状态Token不会续期
+	}
+	key := m.getRememberKey(tokenValue)
+	if !remember {
+		return m.storage.Delete(key)
+	}
+	return m.storage.Set(key, rememberValue, m.tokenExpiration(tokenValue))
+}
+
+// IsRememberMe Checks whether a token uses a persistent cookie | 检查Token是否使用持久Cookie
+func (m *Manager) IsRememberMe(tokenValue string) bool {
+	if tokenValue == "" {
+		return false
+	}
+	return m.storage.Exists(m.getRememberKey(tokenValue))
+}
+
+// getRememberKey Gets storage key of remember-me marker | 获取记住我标记的存储键
+func (m *Manager) getRememberKey(tokenValue string) string {
+	return m.tokenIDKey(RememberKeyPrefix, m.TokenID(tokenValue))
+}
@@ -0,0 +1,55 @@
+package manager
+
+import (
+	"fmt"
+	"time"
+)
+
+// JWT Revocation
+// JWT撤销
+//
+// Every JWT and PASETO token carries a jti claim. With Config.JwtRevocation set, the token
+// generator rejects tokens whose jti is on a revocation list kept in storage until the token's
+// exp. Each instance mirrors the list in a local Bloom filter, so validation stays local and
+// only filter hits read storage. This gives stateless mode an emergency stop: LogoutByToken
+// revokes the token, RevokeJTI revokes one known only by its jti. Mixin mode revokes through
+// the list too. Revocations made on other instances apply here within SyncInterval. NewManager
+// loads the filter; if storage is unreachable then, checks read storage until a background retry
+// succeeds.
+// 每个JWT和PASETO Token都携带jti声明。设置Config.JwtRevocation后，Token生成器会拒绝jti在撤销列表中的
+// Token，该列表保存在存储中直至Token的exp。每个实例在本地布隆过滤器中镜像该列表，因此校验在本地完成，
+// 只有过滤器命中时才读取存储。这为stateless模式提供了紧急止损手段：LogoutByToken 撤销Token，RevokeJTI
+// 撤销只知道jti的Token。mixin模式同样通过该列表撤销。其他实例上的撤销在SyncInterval内在本实例生效。
+// NewManager 会加载过滤器；若此时存储不可用，则在后台重试成功前每次检查都读取存储。
+//
+// Usage | 用法:
+//   cfg.SetJwtMode(config.JwtModeStateless).SetJwtRevocation(&config.JwtRevocationConfig{SyncInterval: 10})
+//   mgr.LogoutByToken(tokenValue)
+//   mgr.RevokeJTI(jti, exp)
+//   mgr.IsLogin(tokenValue)  // false
+
+// ErrRevocationDisabled JwtRevocation is not configured | 未配置JwtRevocation
+var ErrRevocationDisabled = fmt.Errorf("jwt revocation list is not enabled")
+
+// RevokeJTI Revokes the token with a jti until exp, zero exp keeps it forever |
+// 撤销携带该jti的Token直至exp，exp为零值时永久保留
+func (m *Manager) RevokeJTI(jti string, exp time.Time) error {
+	if m.revocations == nil {
+		return ErrRevocationDisabled
+	}
+	return m.revocations.Revoke(jti, exp)
+}
+
+// IsJTIRevoked Checks if the token with a jti is revoked | 检查携带该jti的Token是否已撤销
+func (m *Manager) IsJTIRevoked(jti string) bool {
+	return m.revocations != nil && m.revocations.IsRevoked(jti)
+}
+
+// SyncRevocations Reloads the local revocation filter from storage now instead of after SyncInterval |
+// 立即从存储重新加载本地撤销过滤器，而非等待SyncInterval
+func (m *Manager) SyncRevocations() error {
+	if m.revocations == nil {
+		return ErrRevocationDisabled
+	}
+	return m.revocations.Sync()
+}
@@ -0,0 +1,138 @@
+package manager
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"suwei.sa_token/core/config"
+	"suwei.sa_token/core/security"
+	"suwei.sa_token/core/token"
+)
+
+func TestJwtRevocation(t *testing.T) {
+	// Two instances sharing storage, each with its own local filter | 共享存储的两个实例，各自拥有本地过滤器
+	storage := newMapStorage()
+	cfg := jwtConfig(config.JwtModeStateless).SetJwtRevocation(&config.JwtRevocationConfig{SyncInterval: 3600})
+	mgrA := NewManager(storage, cfg)
+	mgrB := NewManager(storage, cfg)
+
+	tok, _ := mgrA.Login("1001")
+	other, _ := mgrA.Login("1002")
+	if !mgrA.IsLogin(tok) || !mgrB.IsLogin(tok) {
+		t.Fatal("fresh token should be valid on both instances")
+	}
+
+	// Tokens that are not revoked are checked without storage | 未撤销的Token无需访问存储即可校验
+	reads := storage.reads
+	if !mgrB.IsLogin(other) || storage.reads != reads {
+		t.Errorf("hot path read storage %d times", storage.reads-reads)
+	}
+
+	// Stateless logout revokes the jti until exp | Stateless登出撤销jti直至exp
+	if err := mgrA.LogoutByToken(tok); err != nil || mgrA.IsLogin(tok) {
+		t.Errorf("revoked token still valid on the revoking instance, error = %v", err)
+	}
+	if _, err := mgrA.GetLoginID(tok); !errors.Is(err, ErrNotLogin) {
+		t.Errorf("GetLoginID() error = %v", err)
+	}
+	if !mgrB.IsLogin(tok) {
+		t.Error("other instance should only see the revocation after a sync")
+	}
+	if err := mgrB.SyncRevocations(); err != nil || mgrB.IsLogin(tok) {
+		t.Errorf("revoked token still valid after sync, error = %v", err)
+	}
+	if !mgrB.IsLogin(other) {
+		t.Error("revocation should not touch other tokens")
+	}
+
+	// Emergency revocation by jti alone | 仅凭jti紧急撤销
+	claims, _ := token.NewGenerator(cfg).ParseJWT(other)
+	jti, _ := claims[token.ClaimJTI].(string)
+	if err := mgrB.RevokeJTI(jti, time.Now().Add(time.Hour)); err != nil || mgrB.IsLogin(other) || !mgrB.IsJTIRevoked(jti) {
+		t.Errorf("RevokeJTI() error = %v", err)
+	}
+	if err := mgrB.RevokeJTI("", time.Time{}); !errors.Is(err, security.ErrEmptyJTI) {
+		t.Errorf("RevokeJTI(\"\") error = %v", err)
+	}
+	for key := range storage.m {
+		if !strings.HasPrefix(key, "satoken:"+security.RevocationKeySuffix) {
+			t.Errorf("unexpected key %q", key)
+		}
+	}
+
+	// Without the list stateless logout stays unsupported | 未启用列表时stateless登出仍不受支持
+	plain := newTestManager(jwtConfig(config.JwtModeStateless))
+	tok, _ = plain.Login("1001")
+	if err := plain.LogoutByToken(tok); !errors.Is(err, ErrJwtModeUnsupported) {
+		t.Errorf("LogoutByToken() error = %v", err)
+	}
+	if err := plain.RevokeJTI(jti, time.Time{}); !errors.Is(err, ErrRevocationDisabled) {
+		t.Errorf("RevokeJTI() error = %v", err)
+	}
+
+	// Mixin mode revokes through the same list | Mixin模式通过同一列表撤销
+	storage = newMapStorage()
+	mgr := NewManager(storage, jwtConfig(config.JwtModeMixin).SetJwtRevocation(&config.JwtRevocationConfig{}))
+	tok, _ = mgr.Login("1003")
+	if err := mgr.LogoutByToken(tok); err != nil || mgr.IsLogin(tok) {
+		t.Errorf("mixin revoked token still valid, error = %v", err)
+	}
+	if keys, _ := storage.Keys("satoken:" + JwtRevokedKeyPrefix + "*"); len(keys) != 0 {
+		t.Errorf("mixin revocation should use the jti list, got %v", keys)
+	}
+
+	// Revocation needs a jti claim | 撤销需要jti声明
+	if err := config.DefaultConfig().SetJwtRevocation(&config.JwtRevocationConfig{}).Validate(); err == nil {
+		t.Error("JwtRevocation should require a claims token style")
+	}
+}
+
+// scanCountingStorage Storage counting key scans, which fail with err if set | 统计键扫描次数的存储，设置err时扫描失败
+type scanCountingStorage struct {
+	*mapStorage
+	scans atomic.Int32
+	err   error
+}
+
+func (s *scanCountingStorage) Keys(pattern string) ([]string, error) {
+	s.scans.Add(1)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.mapStorage.Keys(pattern)
+}
+
+func TestJwtRevocationSync(t *testing.T) {
+	cfg := jwtConfig(config.JwtModeStateless).SetJwtRevocation(&config.JwtRevocationConfig{SyncInterval: 3600})
+
+	// The filter is loaded with the manager, checks never scan storage | 过滤器随管理器加载，检查从不扫描存储
+	storage := &scanCountingStorage{mapStorage: newMapStorage()}
+	mgr := NewManager(storage, cfg)
+	if scans := storage.scans.Load(); scans != 1 {
+		t.Errorf("NewManager() scanned storage %d times", scans)
+	}
+	tok, _ := mgr.Login("1001")
+	if !mgr.IsLogin(tok) || storage.scans.Load() != 1 {
+		t.Errorf("checks scanned storage %d times", storage.scans.Load()-1)
+	}
+
+	// A failed sync is not retried on every check, which fall back to storage |
+	// 同步失败后不会在每次检查时重试，检查回退到存储
+	failing := &scanCountingStorage{mapStorage: newMapStorage(), err: errors.New("scan unavailable")}
+	mgr = NewManager(failing, cfg)
+	tok, _ = mgr.Login("1001")
+	for i := 0; i < 100; i++ {
+		if !mgr.IsLogin(tok) {
+			t.Fatal("token should be valid while the filter is not loaded")
+		}
+	}
+	if scans := failing.scans.Load(); scans > 2 {
+		t.Errorf("failed sync scanned storage %d times", scans)
+	}
+	if err := mgr.LogoutByToken(tok); err != nil || mgr.IsLogin(tok) {
+		t.Errorf("revoked token still valid without a filter, error = %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package manager
+
+import (
+	"fmt"
+	"time"
+)
+
+// Second-level Authentication
+// 二级认证
+//
+// Sensitive operations may require the user to re-confirm identity (password, SMS code...)
+// even though the token is logged in. OpenSafe marks the token as safe for one service for
+// a limited time; CheckSafe fails with ErrNotSafe once it expires.
+// 敏感操作即使已登录也可能要求用户再次确认身份（密码、短信验证码等）。OpenSafe 在限定时间内
+// 将Token标记为某个服务的安全状态；过期后 CheckSafe 返回 ErrNotSafe。
+//
+// Usage | 用法:
+//   manager.OpenSafe(token, "", 5*time.Minute)  // after password re-check | 密码复核之后
+//   manager.CheckSafe(token, "")                // nil within 5 minutes | 5分钟内返回nil
+
+// DefaultSafeService Default second-level auth service | 默认的二级认证服务
+const DefaultSafeService = "important"
+
+// OpenSafe Opens second-level auth for token and service | 为Token和服务开启二级认证
+func (m *Manager) OpenSafe(tokenValue, service string, duration time.Duration) error {
+	if duration <= 0 {
+		return fmt.Errorf("safe duration must be positive, got: %v", duration)
+	}
+	if m.isStateless() {
+		return m.unsupported("OpenSafe")
+	}
+	if err := m.CheckLogin(tokenValue); err != nil {
+		return err
+	}
+	return m.storage.Set(m.getSafeKey(tokenValue, service), DisableValue, duration)
+}
+
+// IsSafe Checks if second-level auth is open | 检查是否处于二级认证状态
+func (m *Manager) IsSafe(tokenValue, service string) bool {
+	if tokenValue == "" || m.isStateless() {
+		return false
+	}
+	return m.storage.Exists(m.getSafeKey(tokenValue, service))
+}
+
+// CheckSafe Checks second-level auth, returns ErrNotSafe if not open | 检查二级认证，未开启返回ErrNotSafe
+func (m *Manager) CheckSafe(tokenValue, service string) error {
+	if !m.IsSafe(tokenValue, service) {
+		return ErrNotSafe
+	}
+	return nil
+}
+
+// GetSafeTime Gets remaining second-level auth time in seconds | 获取二级认证剩余时间（秒）
+func (m *Manager) GetSafeTime(tokenValue, service string) (int64, error) {
+	ttl, err := m.storage.TTL(m.getSafeKey(tokenValue, service))
+	if err != nil {
+		return -2, err
+	}
+	return int64(ttl.Seconds()), nil
+}
+
+// CloseSafe Closes second-level auth | 关闭二级认证
+func (m *Manager) CloseSafe(tokenValue, service string) error {
+	return m.storage.Delete(m.getSafeKey(tokenValue, service))
+}
+
+// getSafeKey Gets second-level auth storage key | 获取二级认证存储键
+func (m *Manager) getSafeKey(tokenValue, service string) string {
+	if service == "" {
+		service = DefaultSafeService
+	}
+	return m.prefix + SafeKeyPrefix + service + ":" + m.TokenID(tokenValue)
+}
@@ -0,0 +1,81 @@
+package manager
+
+import (
+	"fmt"
+	"time"
+)
+
+// One-time Tickets
+// 一次性票据
+//
+// Browsers cannot set headers on WebSocket upgrades or EventSource requests, and putting the
+// token itself in the URL leaks it to logs. A logged-in client exchanges its token for a short
+// lived ticket backed by a nonce, then passes the ticket in the URL; the ticket works only once.
+// 浏览器无法在WebSocket升级请求或EventSource请求上设置请求头，而把Token放在URL中会泄露到日志。
+// 已登录的客户端用Token换取一个由nonce支撑的短期票据，并在URL中传递票据；票据只能使用一次。
+//
+// Usage | 用法:
+//   ticket, _ := manager.IssueTicket(token, 0)  // GET /ws/ticket, authenticated | 已认证接口
+//   token, err := manager.ConsumeTicket(ticket) // ws://host/ws?ticket=... | 握手时
+
+// Ticket constants | 票据常量
+const (
+	TicketKeyPrefix  = "ticket:"
+	DefaultTicketTTL = 30 * time.Second
+)
+
+// ErrInvalidTicket Ticket is unknown, expired or already used | 票据不存在、已过期或已使用
+var ErrInvalidTicket = fmt.Errorf("invalid or expired ticket")
+
+// IssueTicket Issues a one-time ticket for a logged-in token, ttl<=0 uses DefaultTicketTTL |
+// 为已登录Token签发一次性票据，ttl<=0时使用DefaultTicketTTL
+func (m *Manager) IssueTicket(tokenValue string, ttl time.Duration) (string, error) {
+	if err := m.CheckLogin(tokenValue); err != nil {
+		return "", err
+	}
+	if ttl <= 0 {
+		ttl = DefaultTicketTTL
+	}
+
+	ticket, err := m.nonceManager.Generate()
+	if err != nil {
+		return "", err
+	}
+	sealed, err := m.sealToken(tokenValue)
+	if err != nil {
+		return "", err
+	}
+	if err := m.storage.Set(m.getTicketKey(ticket), sealed, ttl); err != nil {
+		return "", fmt.Errorf("failed to save ticket: %w", err)
+	}
+	return ticket, nil
+}
+
+// ConsumeTicket Consumes a ticket and returns its token | 消费票据并返回对应的Token
+func (m *Manager) ConsumeTicket(ticket string) (string, error) {
+	if ticket == "" || !m.nonceManager.Verify(ticket) {
+		return "", ErrInvalidTicket
+	}
+
+	key := m.getTicketKey(ticket)
+	value, err := m.storage.Get(key)
+	m.storage.Delete(key)
+	if err != nil || value == nil {
+		return "", ErrInvalidTicket
+	}
+
+	sealed, ok := assertString(value)
+	if !ok {
+		return "", ErrInvalidTicket
+	}
+	tokenValue, ok := m.openToken(sealed)
+	if !ok {
+		return "", ErrInvalidTicket
+	}
+	return tokenValue, nil
+}
+
+// getTicketKey Gets storage key for ticket | 获取票据存储键
+func (m *Manager) getTicketKey(ticket string) string {
+	return m.prefix + TicketKeyPrefix + ticket
+}
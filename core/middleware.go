@@ -0,0 +1,289 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"suwei.sa_token/core/acl"
+	"suwei.sa_token/core/annotation"
+	"suwei.sa_token/core/manager"
+)
+
+// Middleware Engine
+// 中间件引擎
+//
+// The checks behind AuthMiddleware, PermissionRequired, RoleRequired, AuthorizeRequired,
+// ACLRequired and RouterMiddleware run here on adapter.RequestContext, and every error is
+// localized by the MessageCatalog and turned into a response by one ErrorRenderer. Integrations
+// only read framework specifics (route params, context storage) and write the rendered response.
+// AuthMiddleware、PermissionRequired、RoleRequired、AuthorizeRequired、ACLRequired 和
+// RouterMiddleware 背后的检查都在这里基于 adapter.RequestContext 执行，所有错误都经 MessageCatalog
+// 本地化后由同一个 ErrorRenderer 转换为响应。各框架集成只负责读取框架相关信息（路由参数、上下文存储）并写出渲染结果。
+//
+// Usage | 用法:
+//   core.SetErrorRenderer(core.ProblemErrorRenderer{TypeBaseURI: "https://errors.example.com"})
+//   plugin := sagin.NewPlugin(manager)  // all middlewares now answer with problem+json | 所有中间件返回 problem+json
+
+// Content types | 内容类型
+const (
+	ContentTypeJSON    = "application/json; charset=utf-8"
+	ContentTypeProblem = "application/problem+json"
+)
+
+// ErrorResponse Rendered error response | 渲染后的错误响应
+type ErrorResponse struct {
+	Status      int    // HTTP status | HTTP状态码
+	ContentType string // Content-Type header | Content-Type 响应头
+	Body        []byte // Response body | 响应体
+}
+
+// ErrorRenderer Renders Sa-Token errors into responses | 将Sa-Token错误渲染为响应
+type ErrorRenderer interface {
+	Render(err *SaTokenError) *ErrorResponse
+}
+
+// ErrorRendererFunc Function adapter for ErrorRenderer | ErrorRenderer 的函数适配器
+type ErrorRendererFunc func(err *SaTokenError) *ErrorResponse
+
+// Render calls f | 调用f
+func (f ErrorRendererFunc) Render(err *SaTokenError) *ErrorResponse {
+	return f(err)
+}
+
+// JSONErrorRenderer Default envelope {"code", "message", "error"} | 默认响应格式 {"code", "message", "error"}
+type JSONErrorRenderer struct{}
+
+// Render renders the default JSON envelope | 渲染默认JSON格式
+func (JSONErrorRenderer) Render(err *SaTokenError) *ErrorResponse {
+	return NewJSONErrorResponse(HTTPStatusFromCode(err.Code), map[string]any{
+		"code":    err.Code,
+		"message": err.Message,
+		"error":   err.Error(),
+	})
+}
+
+// ProblemErrorRenderer RFC 7807 application/problem+json renderer | RFC 7807 application/problem+json 渲染器
+// Type is TypeBaseURI + "/" + code, or "about:blank" when TypeBaseURI is empty; the Sa-Token
+// code is kept as the "code" extension member.
+// type 为 TypeBaseURI + "/" + 错误码，TypeBaseURI 为空时为 "about:blank"；Sa-Token错误码保留在扩展字段 "code" 中。
+type ProblemErrorRenderer struct {
+	TypeBaseURI string
+}
+
+// Render renders a problem details object | 渲染问题详情对象
+func (r ProblemErrorRenderer) Render(err *SaTokenError) *ErrorResponse {
+	status := HTTPStatusFromCode(err.Code)
+	problemType := "about:blank"
+	if r.TypeBaseURI != "" {
+		problemType = strings.TrimSuffix(r.TypeBaseURI, "/") + "/" + strconv.Itoa(err.Code)
+	}
+
+	resp := NewJSONErrorResponse(status, map[string]any{
+		"type":   problemType,
+		"title":  http.StatusText(status),
+		"status": status,
+		"detail": err.Message,
+		"code":   err.Code,
+	})
+	resp.ContentType = ContentTypeProblem
+	return resp
+}
+
+// NewJSONErrorResponse Builds a JSON error response, for custom renderers | 构建JSON错误响应，供自定义渲染器使用
+func NewJSONErrorResponse(status int, body any) *ErrorResponse {
+	data, err := json.Marshal(body)
+	if err != nil {
+		status, data = http.StatusInternalServerError, []byte(`{"message":"failed to render error"}`)
+	}
+	return &ErrorResponse{
+		Status:      status,
+		ContentType: ContentTypeJSON,
+		Body:        data,
+	}
+}
+
+// HTTPStatusFromCode Converts Sa-Token error code to HTTP status | 将Sa-Token错误码转换为HTTP状态码
+func HTTPStatusFromCode(code int) int {
+	switch code {
+	case CodeNotLogin, CodeTokenInvalid, CodeTokenExpired, CodeKickedOut, CodeActiveTimeout, CodeTokenBindingMismatch:
+		return http.StatusUnauthorized
+	case CodePermissionDenied, CodeAccountDisabled, CodeNotSafe, CodeCSRFInvalid:
+		return http.StatusForbidden
+	case CodeBadRequest, CodeInvalidParameter:
+		return http.StatusBadRequest
+	case CodeNotFound:
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Global renderer | 全局渲染器
+var (
+	errorRendererMu sync.RWMutex
+	errorRenderer   ErrorRenderer = JSONErrorRenderer{}
+)
+
+// SetErrorRenderer Sets the global error renderer, nil restores the default | 设置全局错误渲染器，nil恢复默认
+func SetErrorRenderer(renderer ErrorRenderer) {
+	if renderer == nil {
+		renderer = JSONErrorRenderer{}
+	}
+	errorRendererMu.Lock()
+	defer errorRendererMu.Unlock()
+	errorRenderer = renderer
+}
+
+// GetErrorRenderer Gets the global error renderer | 获取全局错误渲染器
+func GetErrorRenderer() ErrorRenderer {
+	errorRendererMu.RLock()
+	defer errorRendererMu.RUnlock()
+	return errorRenderer
+}
+
+// RenderError Localizes and renders any error with the global renderer, ctx may be nil |
+// 使用全局渲染器本地化并渲染任意错误，ctx可为nil
+func RenderError(ctx RequestContext, err error) *ErrorResponse {
+	return GetErrorRenderer().Render(LocalizeError(ctx, err))
+}
+
+// MiddlewareEngine Framework-agnostic middleware checks | 与框架无关的中间件检查
+type MiddlewareEngine struct {
+	manager  *Manager
+	renderer ErrorRenderer
+}
+
+// NewMiddlewareEngine Creates a middleware engine | 创建中间件引擎
+func NewMiddlewareEngine(mgr *Manager) *MiddlewareEngine {
+	return &MiddlewareEngine{manager: mgr}
+}
+
+// GetManager Gets the manager | 获取管理器
+func (e *MiddlewareEngine) GetManager() *Manager {
+	return e.manager
+}
+
+// SetErrorRenderer Overrides the global renderer for this engine | 为此引擎覆盖全局渲染器
+func (e *MiddlewareEngine) SetErrorRenderer(renderer ErrorRenderer) *MiddlewareEngine {
+	e.renderer = renderer
+	return e
+}
+
+// Render Localizes and renders an error with the engine renderer or the global one |
+// 使用引擎渲染器或全局渲染器本地化并渲染错误
+func (e *MiddlewareEngine) Render(ctx RequestContext, err error) *ErrorResponse {
+	if e.renderer != nil {
+		return e.renderer.Render(LocalizeError(ctx, err))
+	}
+	return RenderError(ctx, err)
+}
+
+// CheckLogin Checks login | 检查登录
+func (e *MiddlewareEngine) CheckLogin(ctx RequestContext) (*SaTokenContext, error) {
+	saCtx := NewContext(ctx, e.manager)
+	if err := saCtx.CheckLogin(); err != nil {
+		return nil, ToSaTokenError(err)
+	}
+	return saCtx, nil
+}
+
+// CheckPermission Checks login and permission | 检查登录和权限
+func (e *MiddlewareEngine) CheckPermission(ctx RequestContext, permission string) (*SaTokenContext, error) {
+	saCtx, err := e.CheckLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !saCtx.HasPermission(permission) {
+		return nil, NewPermissionDeniedError(permission)
+	}
+	return saCtx, nil
+}
+
+// CheckRole Checks login and role | 检查登录和角色
+func (e *MiddlewareEngine) CheckRole(ctx RequestContext, role string) (*SaTokenContext, error) {
+	saCtx, err := e.CheckLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !saCtx.HasRole(role) {
+		return nil, NewRoleDeniedError(role)
+	}
+	return saCtx, nil
+}
+
+// Authorize Checks login and policies, resolve builds the resource after login succeeds |
+// 检查登录和策略，登录成功后由 resolve 构建资源
+func (e *MiddlewareEngine) Authorize(ctx RequestContext, action string, resolve func() (*PolicyResource, error)) (*SaTokenContext, error) {
+	saCtx, err := e.CheckLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resource, err := resolve()
+	if err != nil {
+		return nil, NewError(CodeBadRequest, "invalid resource", err)
+	}
+	if err := saCtx.Authorize(action, resource); err != nil {
+		// Only a policy denial is a 403, storage or login errors keep their own code |
+		// 仅策略拒绝返回403，存储或登录错误保留各自的错误码
+		if errors.Is(err, manager.ErrAccessDenied) {
+			return nil, NewAccessDeniedError(action, resource)
+		}
+		return nil, ToSaTokenError(err)
+	}
+	return saCtx, nil
+}
+
+// CheckAccess Checks login and resource ACL | 检查登录和资源ACL
+func (e *MiddlewareEngine) CheckAccess(ctx RequestContext, resourceType, resourceID, action string) (*SaTokenContext, error) {
+	saCtx, err := e.CheckLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := saCtx.CheckAccess(resourceType, resourceID, action); err != nil {
+		switch {
+		case errors.Is(err, manager.ErrAccessDenied):
+			return nil, NewResourceAccessDeniedError(resourceType, resourceID, action)
+		case errors.Is(err, acl.ErrInvalidResource):
+			// A missing route parameter must not fall back to "*" grants | 缺失的路由参数不能回退到"*"授权
+			return nil, NewError(CodeBadRequest, "invalid resource", err)
+		default:
+			return nil, ToSaTokenError(err)
+		}
+	}
+	return saCtx, nil
+}
+
+// CheckCSRF Checks the CSRF token of unsafe cookie requests, login is left to other checks |
+// 检查通过Cookie认证的非安全请求的CSRF令牌，登录由其他检查负责
+func (e *MiddlewareEngine) CheckCSRF(ctx RequestContext) (*SaTokenContext, error) {
+	saCtx := NewContext(ctx, e.manager)
+	if err := saCtx.CheckCSRF(); err != nil {
+		return nil, ToSaTokenError(err)
+	}
+	return saCtx, nil
+}
+
+// CheckAnnotations Runs annotation checks, the context is nil when annotations are ignored |
+// 执行注解检查，注解被忽略时返回的上下文为nil
+func (e *MiddlewareEngine) CheckAnnotations(ctx RequestContext, annotations ...*Annotation) (*SaTokenContext, error) {
+	if annotation.IsIgnored(annotations...) {
+		return nil, nil
+	}
+	saCtx := NewContext(ctx, e.manager)
+	if err := CheckAnnotations(saCtx, annotations...); err != nil {
+		return nil, ToSaTokenError(err)
+	}
+	return saCtx, nil
+}
+
+// CheckRouter Evaluates global route rules | 执行全局路由规则
+func (e *MiddlewareEngine) CheckRouter(ctx RequestContext, r *Router) error {
+	if err := r.Handle(ctx); err != nil {
+		return ToSaTokenError(err)
+	}
+	return nil
+}
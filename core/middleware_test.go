@@ -0,0 +1,136 @@
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mapStorage minimal in-memory adapter.Storage for tests, safe for the async auto-renew
+type mapStorage struct {
+	mu sync.Mutex
+	m  map[string]any
+}
+
+func (s *mapStorage) Set(key string, value any, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[key] = value
+	return nil
+}
+func (s *mapStorage) Get(key string) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m[key], nil
+}
+func (s *mapStorage) Exists(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.m[key]
+	return ok
+}
+func (s *mapStorage) Delete(keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, k := range keys {
+		delete(s.m, k)
+	}
+	return nil
+}
+func (s *mapStorage) Keys(pattern string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for k := range s.m {
+		if strings.HasPrefix(k, strings.TrimSuffix(pattern, "*")) {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+func (s *mapStorage) Expire(string, time.Duration) error { return nil }
+func (s *mapStorage) TTL(string) (time.Duration, error)  { return -1, nil }
+func (s *mapStorage) Clear() error                       { return nil }
+func (s *mapStorage) Ping() error                        { return nil }
+
+// fakeContext Request carrying a token header | 携带Token请求头的请求
+type fakeContext struct {
+	RequestContext
+	header map[string]string
+}
+
+func (f *fakeContext) GetHeader(key string) string { return f.header[key] }
+func (f *fakeContext) GetQuery(string) string      { return "" }
+func (f *fakeContext) GetCookie(string) string     { return "" }
+
+func TestMiddlewareEngineChecks(t *testing.T) {
+	mgr := NewManager(&mapStorage{m: map[string]any{}}, DefaultConfig())
+	token, _ := mgr.Login("1001")
+	_ = mgr.SetPermissions("1001", []string{"user:read"})
+	engine := NewMiddlewareEngine(mgr)
+	ctx := &fakeContext{header: map[string]string{mgr.GetConfig().TokenName: token}}
+
+	if _, err := engine.CheckLogin(&fakeContext{}); ToSaTokenError(err).Code != CodeNotLogin {
+		t.Errorf("no token: error = %v, want CodeNotLogin", err)
+	}
+	if saCtx, err := engine.CheckPermission(ctx, "user:read"); err != nil || saCtx == nil {
+		t.Errorf("granted permission: error = %v", err)
+	}
+	if _, err := engine.CheckRole(ctx, "admin"); ToSaTokenError(err).Code != CodePermissionDenied {
+		t.Errorf("missing role: error = %v, want CodePermissionDenied", err)
+	}
+	if saCtx, err := engine.CheckAnnotations(ctx, &Annotation{Ignore: true}); err != nil || saCtx != nil {
+		t.Errorf("ignored annotations: context = %v, error = %v", saCtx, err)
+	}
+
+	// Authorize: a policy denial is a 403, other errors keep their code | 策略拒绝为403，其他错误保留错误码
+	resource := func() (*PolicyResource, error) { return &PolicyResource{Type: "doc", ID: "1"}, nil }
+	if _, err := engine.Authorize(ctx, "read", resource); ToSaTokenError(err).Code != CodePermissionDenied {
+		t.Errorf("no policy: error = %v, want CodePermissionDenied", err)
+	}
+	_ = mgr.GrantAccess(ACLUserSubject("1001"), "doc", "*", "read")
+	if _, err := engine.CheckAccess(ctx, "doc", "", "read"); ToSaTokenError(err).Code != CodeBadRequest {
+		t.Errorf("empty resource id: error = %v, want CodeBadRequest", err)
+	}
+	if _, err := engine.CheckAccess(ctx, "doc", "1", "read"); err != nil {
+		t.Errorf("granted resource: error = %v", err)
+	}
+	logout := func() (*PolicyResource, error) {
+		_ = mgr.Logout("1001")
+		return resource()
+	}
+	if _, err := engine.Authorize(ctx, "read", logout); ToSaTokenError(err).Code != CodeNotLogin {
+		t.Errorf("logged out during check: error = %v, want CodeNotLogin", err)
+	}
+}
+
+func TestErrorRenderers(t *testing.T) {
+	err := NewPermissionDeniedError("user:write")
+
+	resp := JSONErrorRenderer{}.Render(err)
+	if resp.Status != http.StatusForbidden || resp.ContentType != ContentTypeJSON {
+		t.Errorf("json: status = %d, content type = %q", resp.Status, resp.ContentType)
+	}
+
+	resp = ProblemErrorRenderer{TypeBaseURI: "https://errors.example.com/"}.Render(err)
+	var problem map[string]any
+	if e := json.Unmarshal(resp.Body, &problem); e != nil {
+		t.Fatalf("problem: invalid body %s", resp.Body)
+	}
+	wantType := "https://errors.example.com/" + strconv.Itoa(CodePermissionDenied)
+	if resp.ContentType != ContentTypeProblem || problem["type"] != wantType || problem["status"] != float64(http.StatusForbidden) {
+		t.Errorf("problem: content type = %q, body = %s", resp.ContentType, resp.Body)
+	}
+
+	// Engine renderer overrides the global one | 引擎渲染器覆盖全局渲染器
+	SetErrorRenderer(ProblemErrorRenderer{})
+	defer SetErrorRenderer(nil)
+	engine := NewMiddlewareEngine(nil).SetErrorRenderer(JSONErrorRenderer{})
+	if RenderError(nil, err).ContentType != ContentTypeProblem || engine.Render(nil, err).ContentType != ContentTypeJSON {
+		t.Error("engine renderer should override the global renderer")
+	}
+}
@@ -0,0 +1,356 @@
+package permission
+
+import (
+	"hash/maphash"
+	"strings"
+	"sync"
+)
+
+// Compiled Permission Matcher
+// 编译型权限匹配器
+//
+// Pattern syntax | 模式语法:
+//   *              matches everything | 匹配所有权限
+//   user:add       exact match | 精确匹配
+//   user:*:view    "*" matches exactly one segment | "*" 匹配一个段
+//   user:*         trailing "*" matches one or more segments | 结尾的 "*" 匹配一个或多个段
+//   user:**        "**" matches zero or more segments | "**" 匹配零个或多个段
+//   user:{add,edit} alternatives inside one segment | 段内可选值
+//
+// Usage | 用法:
+//   m := permission.Compile([]string{"user:{add,edit}", "order:**"}, false)
+//   m.Match("user:edit")       // true
+//   m.Match("order:1:refund")  // true
+
+// Constants for permission patterns | 权限模式常量
+const (
+	Wildcard      = "*"  // Single segment wildcard | 单段通配符
+	MultiWildcard = "**" // Multi segment wildcard | 多段通配符
+	Separator     = ":"  // Segment separator | 段分隔符
+
+	DefaultCacheSize = 1024 // Default compiled matcher cache size | 默认编译缓存大小
+)
+
+// node Trie node for one pattern segment | 模式段的前缀树节点
+type node struct {
+	children map[string]*node // Literal segments | 字面量段
+	single   *node            // "*" in the middle of a pattern | 模式中间的 "*"
+	multi    *node            // "**" | "**"
+	end      bool             // A pattern ends here | 模式在此结束
+	tail     bool             // A pattern ends with "*" here (one or more segments) | 模式以 "*" 结尾（一个或多个段）
+}
+
+func newNode() *node {
+	return &node{}
+}
+
+// child gets or creates literal child node | 获取或创建字面量子节点
+func (n *node) child(seg string) *node {
+	if n.children == nil {
+		n.children = make(map[string]*node)
+	}
+	c, ok := n.children[seg]
+	if !ok {
+		c = newNode()
+		n.children[seg] = c
+	}
+	return c
+}
+
+// Matcher Compiled permission set | 编译后的权限集合
+type Matcher struct {
+	root       *node
+	matchAll   bool // Set contains "*" | 集合中包含 "*"
+	ignoreCase bool
+	size       int
+}
+
+// Compile Compiles permission patterns into a matcher | 将权限模式编译为匹配器
+func Compile(patterns []string, ignoreCase bool) *Matcher {
+	m := &Matcher{
+		root:       newNode(),
+		ignoreCase: ignoreCase,
+	}
+	for _, p := range patterns {
+		m.Add(p)
+	}
+	return m
+}
+
+// Add Adds a pattern to the matcher (not safe for concurrent use with Match) | 添加模式（与Match并发调用不安全）
+func (m *Matcher) Add(pattern string) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return
+	}
+	if m.ignoreCase {
+		pattern = strings.ToLower(pattern)
+	}
+	m.size++
+
+	if pattern == Wildcard || pattern == MultiWildcard {
+		m.matchAll = true
+		return
+	}
+
+	segments := strings.Split(pattern, Separator)
+	m.insert(m.root, segments)
+}
+
+// insert Inserts remaining segments under node | 在节点下插入剩余段
+func (m *Matcher) insert(n *node, segments []string) {
+	if len(segments) == 0 {
+		n.end = true
+		return
+	}
+
+	seg, rest := segments[0], segments[1:]
+	switch {
+	case seg == MultiWildcard:
+		if n.multi == nil {
+			n.multi = newNode()
+		}
+		m.insert(n.multi, rest)
+	case seg == Wildcard && len(rest) == 0:
+		n.tail = true
+	case seg == Wildcard:
+		if n.single == nil {
+			n.single = newNode()
+		}
+		m.insert(n.single, rest)
+	default:
+		for _, alt := range expandAlternatives(seg) {
+			m.insert(n.child(alt), rest)
+		}
+	}
+}
+
+// expandAlternatives Expands "{a,b}" segment syntax | 展开 "{a,b}" 段语法
+func expandAlternatives(seg string) []string {
+	open := strings.IndexByte(seg, '{')
+	if open < 0 {
+		return []string{seg}
+	}
+	closing := strings.IndexByte(seg[open:], '}')
+	if closing < 0 {
+		return []string{seg}
+	}
+	closing += open
+
+	prefix, suffix := seg[:open], seg[closing+1:]
+	var result []string
+	for _, alt := range strings.Split(seg[open+1:closing], ",") {
+		for _, tail := range expandAlternatives(suffix) {
+			result = append(result, prefix+strings.TrimSpace(alt)+tail)
+		}
+	}
+	return result
+}
+
+// Match Checks whether permission is granted by any pattern | 检查权限是否被任一模式授予
+func (m *Matcher) Match(permission string) bool {
+	if m == nil || permission == "" {
+		return false
+	}
+	if m.matchAll {
+		return true
+	}
+	if m.ignoreCase {
+		permission = strings.ToLower(permission)
+	}
+	return matchNode(m.root, permission, 0)
+}
+
+// MatchAll Checks whether all permissions are granted (AND) | 检查是否拥有所有权限（AND）
+func (m *Matcher) MatchAll(permissions []string) bool {
+	for _, p := range permissions {
+		if !m.Match(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchAny Checks whether any permission is granted (OR) | 检查是否拥有任一权限（OR）
+func (m *Matcher) MatchAny(permissions []string) bool {
+	for _, p := range permissions {
+		if m.Match(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Size Returns the number of compiled patterns | 返回已编译的模式数量
+func (m *Matcher) Size() int {
+	if m == nil {
+		return 0
+	}
+	return m.size
+}
+
+// matchNode Matches permission starting at pos against node, pos > len means no segments left
+// 从pos开始匹配权限，pos > len 表示没有剩余段
+func matchNode(n *node, perm string, pos int) bool {
+	if pos > len(perm) {
+		if n.end {
+			return true
+		}
+		return n.multi != nil && matchNode(n.multi, perm, pos)
+	}
+
+	if n.tail {
+		return true
+	}
+
+	// Locate current segment without allocating | 无分配地定位当前段
+	next := strings.Index(perm[pos:], Separator)
+	var seg string
+	var rest int
+	if next < 0 {
+		seg = perm[pos:]
+		rest = len(perm) + 1
+	} else {
+		seg = perm[pos : pos+next]
+		rest = pos + next + 1
+	}
+
+	if n.children != nil {
+		if c, ok := n.children[seg]; ok && matchNode(c, perm, rest) {
+			return true
+		}
+	}
+	if n.single != nil && matchNode(n.single, perm, rest) {
+		return true
+	}
+	if n.multi != nil {
+		// "**" consumes zero or more segments | "**" 消耗零个或多个段
+		p := pos
+		for {
+			if matchNode(n.multi, perm, p) {
+				return true
+			}
+			if p > len(perm) {
+				return false
+			}
+			i := strings.Index(perm[p:], Separator)
+			if i < 0 {
+				p = len(perm) + 1
+			} else {
+				p += i + 1
+			}
+		}
+	}
+	return false
+}
+
+// ============ Compiled Matcher Cache | 编译缓存 ============
+
+// hashMultiplier Mixes pattern hashes in order (FNV-1 64-bit prime) | 按顺序混合模式哈希（FNV-1 64位素数）
+const hashMultiplier = 1099511628211
+
+// cacheEntry Compiled matcher and the patterns it was built from | 编译后的匹配器及其来源模式
+type cacheEntry struct {
+	patterns []string
+	matcher  *Matcher
+}
+
+// Cache Caches compiled matchers keyed by a hash of the permission set | 按权限集合的哈希缓存已编译的匹配器
+type Cache struct {
+	mu         sync.RWMutex
+	seed       maphash.Seed
+	items      map[uint64]*cacheEntry
+	keyed      map[string]*Matcher
+	maxSize    int
+	ignoreCase bool
+}
+
+// NewCache Creates a new matcher cache | 创建新的匹配器缓存
+func NewCache(maxSize int, ignoreCase bool) *Cache {
+	if maxSize <= 0 {
+		maxSize = DefaultCacheSize
+	}
+	return &Cache{
+		seed:       maphash.MakeSeed(),
+		items:      make(map[uint64]*cacheEntry),
+		keyed:      make(map[string]*Matcher),
+		maxSize:    maxSize,
+		ignoreCase: ignoreCase,
+	}
+}
+
+// Get Gets or compiles the matcher for a permission set, a hit allocates nothing | 获取或编译权限集合的匹配器，命中时不分配内存
+func (c *Cache) Get(patterns []string) *Matcher {
+	key := c.hash(patterns)
+
+	c.mu.RLock()
+	e, ok := c.items[key]
+	c.mu.RUnlock()
+	if ok && equalPatterns(e.patterns, patterns) {
+		return e.matcher
+	}
+
+	// Keep a copy, callers may reuse their slice | 保存副本，调用方可能复用其切片
+	e = &cacheEntry{patterns: append([]string(nil), patterns...)}
+	e.matcher = Compile(e.patterns, c.ignoreCase)
+
+	c.mu.Lock()
+	// Reset when full, permission sets are usually few and stable | 满时重置，权限集合通常少且稳定
+	if len(c.items) >= c.maxSize {
+		c.items = make(map[uint64]*cacheEntry)
+	}
+	c.items[key] = e
+	c.mu.Unlock()
+
+	return e.matcher
+}
+
+// GetKeyed Gets the matcher cached under a key that changes whenever the set does, e.g. a signed token; a hit neither
+// hashes nor compares the set | 获取以随权限集合变化的键（如签名Token）缓存的匹配器，命中时既不哈希也不比较集合
+func (c *Cache) GetKeyed(key string, patterns []string) *Matcher {
+	c.mu.RLock()
+	matcher, ok := c.keyed[key]
+	c.mu.RUnlock()
+	if ok {
+		return matcher
+	}
+
+	matcher = c.Get(patterns)
+	c.mu.Lock()
+	if len(c.keyed) >= c.maxSize {
+		c.keyed = make(map[string]*Matcher)
+	}
+	c.keyed[key] = matcher
+	c.mu.Unlock()
+	return matcher
+}
+
+// hash Hashes a permission set, pattern by pattern so ["ab"] and ["a", "b"] differ | 逐个模式哈希权限集合，使 ["ab"] 与 ["a", "b"] 不同
+func (c *Cache) hash(patterns []string) uint64 {
+	h := uint64(len(patterns))
+	for _, p := range patterns {
+		h = h*hashMultiplier ^ maphash.String(c.seed, p)
+	}
+	return h
+}
+
+// equalPatterns Guards against hash collisions | 防止哈希冲突
+func equalPatterns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Clear Clears all cached matchers | 清空所有缓存的匹配器
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items = make(map[uint64]*cacheEntry)
+	c.keyed = make(map[string]*Matcher)
+}
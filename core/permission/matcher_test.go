@@ -0,0 +1,175 @@
+package permission
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMatcherPatterns(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		permission string
+		want       bool
+	}{
+		{"*", "user:add", true},
+		{"user:add", "user:add", true},
+		{"user:add", "user:delete", false},
+		{"user:*", "user:add", true},
+		{"user:*", "user:add:view", true},
+		{"user:*", "user", false},
+		{"user:*:view", "user:add:view", true},
+		{"user:*:view", "user:add:edit", false},
+		{"user:*:view", "user:view", false},
+		{"user:**", "user", true},
+		{"user:**", "user:a:b:c", true},
+		{"user:**:view", "user:view", true},
+		{"user:**:view", "user:a:b:view", true},
+		{"user:**:view", "user:a:b:edit", false},
+		{"**:view", "order:1:view", true},
+		{"user:{add,edit}", "user:add", true},
+		{"user:{add,edit}", "user:edit", true},
+		{"user:{add,edit}", "user:delete", false},
+		{"{user,order}:{add,edit}:*", "order:edit:1", true},
+		{"user:{add,edit}-all", "user:edit-all", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"|"+tt.permission, func(t *testing.T) {
+			m := Compile([]string{tt.pattern}, false)
+			if got := m.Match(tt.permission); got != tt.want {
+				t.Errorf("Compile(%q).Match(%q) = %v, want %v", tt.pattern, tt.permission, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatcherIgnoreCase(t *testing.T) {
+	m := Compile([]string{"User:{Add,Edit}"}, true)
+	if !m.Match("user:ADD") {
+		t.Error("case-insensitive matcher should match user:ADD")
+	}
+
+	m = Compile([]string{"User:Add"}, false)
+	if m.Match("user:add") {
+		t.Error("case-sensitive matcher should not match user:add")
+	}
+}
+
+func TestMatcherAndOr(t *testing.T) {
+	m := Compile([]string{"user:add", "order:*"}, false)
+	if !m.MatchAll([]string{"user:add", "order:refund"}) {
+		t.Error("MatchAll should be true")
+	}
+	if m.MatchAll([]string{"user:add", "user:delete"}) {
+		t.Error("MatchAll should be false")
+	}
+	if !m.MatchAny([]string{"user:delete", "order:refund"}) {
+		t.Error("MatchAny should be true")
+	}
+}
+
+func TestCache(t *testing.T) {
+	c := NewCache(2, false)
+	perms := []string{"user:*"}
+	if c.Get(perms) != c.Get(perms) {
+		t.Error("cache should return the same compiled matcher")
+	}
+	c.Get([]string{"a"})
+	c.Get([]string{"b"})
+	if !c.Get(perms).Match("user:add") {
+		t.Error("matcher should still work after eviction")
+	}
+
+	// Sets differ by pattern boundaries, and callers may reuse their slice | 集合按模式边界区分，调用方可复用其切片
+	if c.Get([]string{"ab"}) == c.Get([]string{"a", "b"}) {
+		t.Error("[ab] and [a b] should be different sets")
+	}
+	reused := []string{"order:*"}
+	c.Get(reused)
+	reused[0] = "user:*"
+	if c.Get([]string{"order:*"}).Match("user:add") {
+		t.Error("cached set changed with the caller's slice")
+	}
+
+	// Keyed lookups share compiled matchers and skip the set on a hit | 按键查找共享编译结果，命中时跳过集合
+	if c.GetKeyed("v1", perms) != c.Get(perms) || !c.GetKeyed("v1", nil).Match("user:add") {
+		t.Error("GetKeyed() should return the matcher of the set first cached under the key")
+	}
+}
+
+func largePermissionSet(n int) []string {
+	perms := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		perms = append(perms, fmt.Sprintf("module%d:resource%d:{read,write}", i%50, i))
+	}
+	perms = append(perms, "billing:**:refund", "report:*:export")
+	return perms
+}
+
+func BenchmarkMatcher1000Hit(b *testing.B) {
+	m := Compile(largePermissionSet(1000), false)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !m.Match("module7:resource507:write") {
+			b.Fatal("expected match")
+		}
+	}
+}
+
+func BenchmarkMatcher1000Wildcard(b *testing.B) {
+	m := Compile(largePermissionSet(1000), false)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !m.Match("billing:eu:order:42:refund") {
+			b.Fatal("expected match")
+		}
+	}
+}
+
+func BenchmarkMatcher1000Miss(b *testing.B) {
+	m := Compile(largePermissionSet(1000), false)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if m.Match("module7:resource508:delete") {
+			b.Fatal("unexpected match")
+		}
+	}
+}
+
+func BenchmarkMatcher10000IgnoreCase(b *testing.B) {
+	m := Compile(largePermissionSet(10000), true)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !m.Match("Module7:Resource507:Read") {
+			b.Fatal("expected match")
+		}
+	}
+}
+
+func BenchmarkCacheKeyed1000Hit(b *testing.B) {
+	c := NewCache(DefaultCacheSize, false)
+	perms := largePermissionSet(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !c.GetKeyed("v1", perms).Match("module7:resource507:write") {
+			b.Fatal("expected match")
+		}
+	}
+}
+
+func BenchmarkCache1000Hit(b *testing.B) {
+	c := NewCache(DefaultCacheSize, false)
+	perms := largePermissionSet(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !c.Get(perms).Match("module7:resource507:write") {
+			b.Fatal("expected match")
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Attribute-Based Access Control (ABAC)
+// 基于属性的访问控制
+//
+// Flow | 流程:
+// 1. Register policies on Manager | 在Manager上注册策略
+// 2. Authorize(loginID, action, resource) builds a Request with subject attributes | 构建包含主体属性的请求
+// 3. Every policy returns Allow, Deny or NotApplicable | 每个策略返回允许、拒绝或不适用
+// 4. Deny-overrides combining: any Deny wins, then any Allow, else NotApplicable | 拒绝优先合并：任一拒绝即拒绝，其次任一允许，否则不适用
+//
+// Usage | 用法:
+//   manager.RegisterPolicy("order-edit", policy.PolicyFunc(func(r *policy.Request) policy.Decision {
+//       if r.Action != "edit" || r.Resource.Type != "order" {
+//           return policy.NotApplicable
+//       }
+//       if r.Resource.Attr("ownerId") == r.Subject.LoginID {
+//           return policy.Allow
+//       }
+//       return policy.NotApplicable
+//   }))
+//   err := saCtx.Authorize("edit", policy.NewResource("order", "9", attrs))
+
+// Decision Policy evaluation result | 策略评估结果
+type Decision int
+
+const (
+	// NotApplicable Policy does not apply to the request | 策略不适用于该请求
+	NotApplicable Decision = iota
+	// Allow Policy permits the request | 策略允许该请求
+	Allow
+	// Deny Policy forbids the request | 策略拒绝该请求
+	Deny
+)
+
+// String Returns decision name | 返回决策名称
+func (d Decision) String() string {
+	switch d {
+	case Allow:
+		return "allow"
+	case Deny:
+		return "deny"
+	default:
+		return "not_applicable"
+	}
+}
+
+// Subject Subject being authorized | 被授权的主体
+type Subject struct {
+	LoginID    string         // Login ID | 登录ID
+	Roles      []string       // Roles of the subject | 主体角色
+	Attributes map[string]any // Session attributes | Session属性
+}
+
+// Attr Gets subject attribute | 获取主体属性
+func (s *Subject) Attr(key string) any {
+	if s == nil || s.Attributes == nil {
+		return nil
+	}
+	return s.Attributes[key]
+}
+
+// HasRole Checks if subject has role | 检查主体是否拥有角色
+func (s *Subject) HasRole(role string) bool {
+	if s == nil {
+		return false
+	}
+	for _, r := range s.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Resource Resource being accessed | 被访问的资源
+type Resource struct {
+	Type       string         // Resource type, e.g. "order" | 资源类型
+	ID         string         // Resource ID | 资源ID
+	Attributes map[string]any // Resource attributes | 资源属性
+}
+
+// NewResource Creates a resource | 创建资源
+func NewResource(resourceType, id string, attributes map[string]any) *Resource {
+	if attributes == nil {
+		attributes = make(map[string]any)
+	}
+	return &Resource{
+		Type:       resourceType,
+		ID:         id,
+		Attributes: attributes,
+	}
+}
+
+// Attr Gets resource attribute | 获取资源属性
+func (r *Resource) Attr(key string) any {
+	if r == nil || r.Attributes == nil {
+		return nil
+	}
+	return r.Attributes[key]
+}
+
+// Request Authorization request | 授权请求
+type Request struct {
+	Subject  *Subject  // Who | 主体
+	Action   string    // What | 操作
+	Resource *Resource // On what | 资源
+}
+
+// Policy Authorization policy | 授权策略
+type Policy interface {
+	// Evaluate evaluates the request | 评估请求
+	Evaluate(req *Request) Decision
+}
+
+// PolicyFunc Function adapter implementing Policy | 函数适配器，实现Policy接口
+type PolicyFunc func(req *Request) Decision
+
+// Evaluate implements Policy | 实现Policy接口
+func (f PolicyFunc) Evaluate(req *Request) Decision {
+	return f(req)
+}
+
+// Rule Declarative policy matching actions and resource type | 声明式策略，匹配操作和资源类型
+type Rule struct {
+	Actions      []string                // Actions this rule applies to, empty means all | 适用的操作，空表示全部
+	ResourceType string                  // Resource type, empty means all | 资源类型，空表示全部
+	Condition    func(req *Request) bool // Condition, nil means always | 条件，nil表示始终满足
+	Effect       Decision                // Effect when condition holds (Allow or Deny) | 条件满足时的效果
+}
+
+// Evaluate implements Policy | 实现Policy接口
+func (r *Rule) Evaluate(req *Request) Decision {
+	if r.ResourceType != "" && (req.Resource == nil || req.Resource.Type != r.ResourceType) {
+		return NotApplicable
+	}
+	if len(r.Actions) > 0 {
+		matched := false
+		for _, a := range r.Actions {
+			if a == req.Action || a == "*" {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return NotApplicable
+		}
+	}
+	if r.Condition != nil && !r.Condition(req) {
+		return NotApplicable
+	}
+	return r.Effect
+}
+
+// Error variables | 错误变量
+var (
+	ErrPolicyExists = fmt.Errorf("policy already registered")
+)
+
+// Engine Policy engine with deny-overrides combining | 拒绝优先合并的策略引擎
+type Engine struct {
+	mu       sync.RWMutex
+	policies map[string]Policy
+	names    []string // Sorted names for deterministic evaluation | 排序后的名称，保证评估顺序确定
+}
+
+// NewEngine Creates a new policy engine | 创建新的策略引擎
+func NewEngine() *Engine {
+	return &Engine{
+		policies: make(map[string]Policy),
+	}
+}
+
+// Register Registers a policy by name | 按名称注册策略
+func (e *Engine) Register(name string, p Policy) error {
+	if name == "" || p == nil {
+		return fmt.Errorf("policy name and policy cannot be empty")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, exists := e.policies[name]; exists {
+		return fmt.Errorf("%w: %s", ErrPolicyExists, name)
+	}
+	e.policies[name] = p
+	e.names = append(e.names, name)
+	sort.Strings(e.names)
+	return nil
+}
+
+// Unregister Removes a policy by name | 按名称移除策略
+func (e *Engine) Unregister(name string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, exists := e.policies[name]; !exists {
+		return false
+	}
+	delete(e.policies, name)
+	for i, n := range e.names {
+		if n == name {
+			e.names = append(e.names[:i], e.names[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// Names Returns registered policy names | 返回已注册的策略名称
+func (e *Engine) Names() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return append([]string(nil), e.names...)
+}
+
+// Evaluate Evaluates all policies with deny-overrides | 使用拒绝优先评估所有策略
+func (e *Engine) Evaluate(req *Request) Decision {
+	e.mu.RLock()
+	policies := make([]Policy, 0, len(e.names))
+	for _, name := range e.names {
+		policies = append(policies, e.policies[name])
+	}
+	e.mu.RUnlock()
+
+	result := NotApplicable
+	for _, p := range policies {
+		switch p.Evaluate(req) {
+		case Deny:
+			return Deny
+		case Allow:
+			result = Allow
+		}
+	}
+	return result
+}
@@ -0,0 +1,68 @@
+package policy
+
+import "testing"
+
+func TestEngineDenyOverrides(t *testing.T) {
+	e := NewEngine()
+
+	// Owner or same tenant may edit, locked orders may never be edited
+	_ = e.Register("order-owner", &Rule{
+		Actions:      []string{"edit"},
+		ResourceType: "order",
+		Condition: func(r *Request) bool {
+			return r.Resource.Attr("ownerId") == r.Subject.LoginID ||
+				r.Resource.Attr("tenantId") == r.Subject.Attr("tenantId")
+		},
+		Effect: Allow,
+	})
+	_ = e.Register("order-locked", &Rule{
+		ResourceType: "order",
+		Condition: func(r *Request) bool {
+			locked, _ := r.Resource.Attr("locked").(bool)
+			return locked
+		},
+		Effect: Deny,
+	})
+
+	subject := &Subject{LoginID: "42", Attributes: map[string]any{"tenantId": "t1"}}
+
+	tests := []struct {
+		name     string
+		action   string
+		resource *Resource
+		want     Decision
+	}{
+		{"owner", "edit", NewResource("order", "1", map[string]any{"ownerId": "42"}), Allow},
+		{"same tenant", "edit", NewResource("order", "2", map[string]any{"tenantId": "t1"}), Allow},
+		{"locked", "edit", NewResource("order", "3", map[string]any{"ownerId": "42", "locked": true}), Deny},
+		{"other tenant", "edit", NewResource("order", "4", map[string]any{"tenantId": "t2"}), NotApplicable},
+		{"other type", "edit", NewResource("invoice", "5", map[string]any{"ownerId": "42"}), NotApplicable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := e.Evaluate(&Request{Subject: subject, Action: tt.action, Resource: tt.resource})
+			if got != tt.want {
+				t.Errorf("Evaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngineRegister(t *testing.T) {
+	e := NewEngine()
+	allow := PolicyFunc(func(r *Request) Decision { return Allow })
+
+	if err := e.Register("p", allow); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := e.Register("p", allow); err == nil {
+		t.Error("Register() should reject duplicate names")
+	}
+	if !e.Unregister("p") {
+		t.Error("Unregister() should return true")
+	}
+	if got := e.Evaluate(&Request{}); got != NotApplicable {
+		t.Errorf("empty engine Evaluate() = %v, want NotApplicable", got)
+	}
+}
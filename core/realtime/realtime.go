@@ -0,0 +1,282 @@
+package realtime
+
+import (
+	"strings"
+	"sync"
+
+	"suwei.sa_token/core/adapter"
+	"suwei.sa_token/core/context"
+	"suwei.sa_token/core/listener"
+	"suwei.sa_token/core/manager"
+)
+
+// WebSocket / SSE Authentication
+// WebSocket / SSE 认证
+//
+// Browsers cannot set custom headers on WebSocket upgrades or EventSource requests. The
+// Authenticator accepts the token from the Sec-WebSocket-Protocol header ("satoken, <token>"),
+// from a one-time ticket in the query string, or from the usual header/cookie/query sources.
+// The Hub binds each live connection to its login ID and token, and closes it when the token
+// is logged out or kicked out, or the account is disabled.
+// 浏览器无法在WebSocket升级请求或EventSource请求上设置自定义请求头。Authenticator 从
+// Sec-WebSocket-Protocol 请求头（"satoken, <token>"）、查询参数中的一次性票据或常规的
+// Header/Cookie/Query 读取Token。Hub 将每个存活连接绑定到登录ID和Token，在Token登出、被踢下线
+// 或账号被封禁时关闭连接。
+//
+// Usage | 用法:
+//   auth := realtime.NewAuthenticator(mgr)
+//   hub := realtime.NewHub(mgr)
+//
+//   identity, err := auth.Authenticate(ctx)       // before upgrading | 升级之前
+//   conn := upgrade(w, r, identity.Subprotocol)    // echo the subprotocol | 回显子协议
+//   release := hub.Bind(identity, conn)
+//   defer release()
+
+// Default values | 默认值
+const (
+	DefaultProtocol    = "satoken" // Marker subprotocol, the next entry is the token | 标记子协议，下一项为Token
+	DefaultTicketParam = "ticket"  // Query parameter of one-time tickets | 一次性票据的查询参数
+
+	protocolHeader = "Sec-WebSocket-Protocol"
+)
+
+// Identity Authenticated connection owner | 已认证的连接归属
+type Identity struct {
+	LoginID     string // Login ID | 登录ID
+	Token       string // Token value | Token值
+	Subprotocol string // Subprotocol to echo in the upgrade response, empty if none | 升级响应中需回显的子协议，无则为空
+}
+
+// Conn Live connection that can be closed by the server | 可由服务端关闭的存活连接
+type Conn interface {
+	Close() error
+}
+
+// ConnFunc Adapts a close function to Conn, e.g. a context.CancelFunc of an SSE stream |
+// 将关闭函数适配为Conn，例如SSE流的context.CancelFunc
+type ConnFunc func() error
+
+// Close calls f | 调用f
+func (f ConnFunc) Close() error {
+	return f()
+}
+
+// ============ Authenticator | 认证器 ============
+
+// Authenticator Handshake authenticator | 握手认证器
+type Authenticator struct {
+	manager     *manager.Manager
+	Protocol    string // Marker subprotocol, empty disables the header source | 标记子协议，为空则不读取该请求头
+	TicketParam string // Ticket query parameter, empty disables tickets | 票据查询参数，为空则不支持票据
+}
+
+// NewAuthenticator Creates a handshake authenticator | 创建握手认证器
+func NewAuthenticator(mgr *manager.Manager) *Authenticator {
+	return &Authenticator{
+		manager:     mgr,
+		Protocol:    DefaultProtocol,
+		TicketParam: DefaultTicketParam,
+	}
+}
+
+// Authenticate Resolves and validates the handshake token | 解析并校验握手Token
+func (a *Authenticator) Authenticate(ctx adapter.RequestContext) (*Identity, error) {
+	identity := &Identity{}
+
+	if token := a.protocolToken(ctx); token != "" {
+		identity.Token = token
+		identity.Subprotocol = a.Protocol
+	} else if ticket := a.ticket(ctx); ticket != "" {
+		token, err := a.manager.ConsumeTicket(ticket)
+		if err != nil {
+			return nil, err
+		}
+		identity.Token = token
+	} else {
+		identity.Token = context.NewContext(ctx, a.manager).GetTokenValue()
+	}
+
+	loginID, err := a.manager.GetLoginID(identity.Token)
+	if err != nil {
+		return nil, err
+	}
+	identity.LoginID = loginID
+	return identity, nil
+}
+
+// protocolToken Reads the entry following the marker subprotocol | 读取标记子协议之后的一项
+func (a *Authenticator) protocolToken(ctx adapter.RequestContext) string {
+	if a.Protocol == "" {
+		return ""
+	}
+	header := ctx.GetHeader(protocolHeader)
+	if header == "" {
+		return ""
+	}
+
+	protocols := strings.Split(header, ",")
+	for i := 0; i < len(protocols)-1; i++ {
+		if strings.TrimSpace(protocols[i]) == a.Protocol {
+			return strings.TrimSpace(protocols[i+1])
+		}
+	}
+	return ""
+}
+
+// ticket Reads the ticket query parameter | 读取票据查询参数
+func (a *Authenticator) ticket(ctx adapter.RequestContext) string {
+	if a.TicketParam == "" {
+		return ""
+	}
+	return strings.TrimSpace(ctx.GetQuery(a.TicketParam))
+}
+
+// ============ Hub | 连接中心 ============
+
+// binding Connection bound to an identity | 绑定到身份的连接
+type binding struct {
+	identity *Identity
+	tokenID  string
+	conn     Conn
+	once     sync.Once
+}
+
+// close Closes the connection once | 只关闭一次连接
+func (b *binding) close() {
+	b.once.Do(func() { _ = b.conn.Close() })
+}
+
+// Hub Registry of live connections driven by listener events | 由监听事件驱动的存活连接注册中心
+type Hub struct {
+	mu          sync.Mutex
+	byToken     map[string]map[*binding]struct{}
+	byLoginID   map[string]map[*binding]struct{}
+	tokenID     func(string) string
+	events      *listener.Manager
+	listenerIDs []string
+}
+
+// NewHub Creates a hub listening to the manager's logout, kickout and disable events |
+// 创建监听管理器登出、踢下线和封禁事件的连接中心
+func NewHub(mgr *manager.Manager) *Hub {
+	h := &Hub{
+		byToken:   make(map[string]map[*binding]struct{}),
+		byLoginID: make(map[string]map[*binding]struct{}),
+		tokenID:   mgr.TokenID,
+		events:    mgr.GetEventManager(),
+	}
+
+	// Event data carries token IDs, see Manager.TokenID | 事件数据携带Token ID，参见 Manager.TokenID
+	// Sync listeners: connections are closed before Logout/Kickout/Disable returns |
+	// 同步监听器：在 Logout/Kickout/Disable 返回前关闭连接
+	syncConfig := listener.ListenerConfig{Async: false}
+	onToken := func(data *listener.EventData) {
+		if data.Token != "" {
+			h.closeAll(h.byToken, data.Token)
+		}
+	}
+	h.listenerIDs = []string{
+		h.events.RegisterFuncWithConfig(listener.EventLogout, onToken, syncConfig),
+		h.events.RegisterFuncWithConfig(listener.EventKickout, onToken, syncConfig),
+		h.events.RegisterFuncWithConfig(listener.EventDisable, func(data *listener.EventData) {
+			h.CloseLoginID(data.LoginID)
+		}, syncConfig),
+	}
+	return h
+}
+
+// Bind Binds a connection to an identity, call release when the connection ends |
+// 将连接绑定到身份，连接结束时调用release
+func (h *Hub) Bind(identity *Identity, conn Conn) (release func()) {
+	b := &binding{identity: identity, tokenID: h.tokenID(identity.Token), conn: conn}
+
+	h.mu.Lock()
+	add(h.byToken, b.tokenID, b)
+	add(h.byLoginID, identity.LoginID, b)
+	h.mu.Unlock()
+
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.remove(b)
+	}
+}
+
+// CloseToken Closes all connections of a token, returns the count | 关闭Token的所有连接，返回关闭数量
+func (h *Hub) CloseToken(tokenValue string) int {
+	return h.closeAll(h.byToken, h.tokenID(tokenValue))
+}
+
+// CloseLoginID Closes all connections of a login ID, returns the count | 关闭登录ID的所有连接，返回关闭数量
+func (h *Hub) CloseLoginID(loginID string) int {
+	return h.closeAll(h.byLoginID, loginID)
+}
+
+// Count Gets the number of live connections | 获取存活连接数量
+func (h *Hub) Count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	count := 0
+	for _, set := range h.byToken {
+		count += len(set)
+	}
+	return count
+}
+
+// CountByLoginID Gets the number of live connections of a login ID | 获取登录ID的存活连接数量
+func (h *Hub) CountByLoginID(loginID string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.byLoginID[loginID])
+}
+
+// Stop Unregisters the hub's listeners | 注销连接中心的监听器
+func (h *Hub) Stop() {
+	for _, id := range h.listenerIDs {
+		h.events.Unregister(id)
+	}
+	h.listenerIDs = nil
+}
+
+// closeAll Removes and closes the connections under key | 移除并关闭key下的连接
+func (h *Hub) closeAll(index map[string]map[*binding]struct{}, key string) int {
+	h.mu.Lock()
+	bindings := make([]*binding, 0, len(index[key]))
+	for b := range index[key] {
+		bindings = append(bindings, b)
+		h.remove(b)
+	}
+	h.mu.Unlock()
+
+	// Close outside the lock, Close may block on network I/O | 在锁外关闭，Close可能阻塞于网络I/O
+	for _, b := range bindings {
+		b.close()
+	}
+	return len(bindings)
+}
+
+// remove Removes a binding from both indexes, caller holds the lock | 从两个索引中移除绑定，调用方持有锁
+func (h *Hub) remove(b *binding) {
+	del(h.byToken, b.tokenID, b)
+	del(h.byLoginID, b.identity.LoginID, b)
+}
+
+// add Adds a binding to an index | 向索引添加绑定
+func add(index map[string]map[*binding]struct{}, key string, b *binding) {
+	set, ok := index[key]
+	if !ok {
+		set = make(map[*binding]struct{})
+		index[key] = set
+	}
+	set[b] = struct{}{}
+}
+
+// del Deletes a binding from an index | 从索引删除绑定
+func del(index map[string]map[*binding]struct{}, key string, b *binding) {
+	if set, ok := index[key]; ok {
+		delete(set, b)
+		if len(set) == 0 {
+			delete(index, key)
+		}
+	}
+}
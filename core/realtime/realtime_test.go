@@ -0,0 +1,161 @@
+package realtime
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"suwei.sa_token/core/adapter"
+	"suwei.sa_token/core/config"
+	"suwei.sa_token/core/manager"
+)
+
+// mapStorage minimal in-memory adapter.Storage for tests, safe for the async auto-renew
+type mapStorage struct {
+	mu sync.Mutex
+	m  map[string]any
+}
+
+func (s *mapStorage) Set(key string, value any, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[key] = value
+	return nil
+}
+func (s *mapStorage) Get(key string) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m[key], nil
+}
+func (s *mapStorage) Exists(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.m[key]
+	return ok
+}
+func (s *mapStorage) Delete(keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, k := range keys {
+		delete(s.m, k)
+	}
+	return nil
+}
+func (s *mapStorage) Keys(pattern string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for k := range s.m {
+		if strings.HasPrefix(k, strings.TrimSuffix(pattern, "*")) {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+func (s *mapStorage) Expire(string, time.Duration) error { return nil }
+func (s *mapStorage) TTL(string) (time.Duration, error)  { return -1, nil }
+func (s *mapStorage) Clear() error                       { return nil }
+func (s *mapStorage) Ping() error                        { return nil }
+
+// fakeContext Handshake request exposing headers and query | 提供请求头和查询参数的握手请求
+type fakeContext struct {
+	adapter.RequestContext
+	header map[string]string
+	query  map[string]string
+}
+
+func (f *fakeContext) GetHeader(key string) string { return f.header[key] }
+func (f *fakeContext) GetQuery(key string) string  { return f.query[key] }
+func (f *fakeContext) GetCookie(string) string     { return "" }
+
+// fakeConn Connection recording Close calls | 记录Close调用的连接
+type fakeConn struct{ closed int }
+
+func (c *fakeConn) Close() error { c.closed++; return nil }
+
+func newManager() *manager.Manager {
+	return manager.NewManager(&mapStorage{m: map[string]any{}}, config.DefaultConfig())
+}
+
+func TestAuthenticate(t *testing.T) {
+	mgr := newManager()
+	token, _ := mgr.Login("1001")
+	auth := NewAuthenticator(mgr)
+
+	identity, err := auth.Authenticate(&fakeContext{header: map[string]string{protocolHeader: "satoken, " + token}})
+	if err != nil {
+		t.Fatalf("subprotocol: error = %v", err)
+	}
+	if identity.LoginID != "1001" || identity.Subprotocol != DefaultProtocol {
+		t.Errorf("subprotocol: identity = %+v", identity)
+	}
+
+	ticket, err := mgr.IssueTicket(token, 0)
+	if err != nil {
+		t.Fatalf("IssueTicket() error = %v", err)
+	}
+	ticketCtx := &fakeContext{query: map[string]string{DefaultTicketParam: ticket}}
+	if identity, err := auth.Authenticate(ticketCtx); err != nil || identity.Token != token || identity.Subprotocol != "" {
+		t.Errorf("ticket: identity = %+v, error = %v", identity, err)
+	}
+	if _, err := auth.Authenticate(ticketCtx); !errors.Is(err, manager.ErrInvalidTicket) {
+		t.Errorf("reused ticket: error = %v, want ErrInvalidTicket", err)
+	}
+
+	if _, err := auth.Authenticate(&fakeContext{}); !errors.Is(err, manager.ErrNotLogin) {
+		t.Errorf("no token: error = %v, want ErrNotLogin", err)
+	}
+}
+
+func TestHubClosesOnEvents(t *testing.T) {
+	mgr := newManager()
+	hub := NewHub(mgr)
+	defer hub.Stop()
+
+	tokenA, _ := mgr.Login("1001", "web")
+	tokenB, _ := mgr.Login("1001", "app")
+	connA, connB := &fakeConn{}, &fakeConn{}
+	hub.Bind(&Identity{LoginID: "1001", Token: tokenA}, connA)
+	hub.Bind(&Identity{LoginID: "1001", Token: tokenB}, connB)
+
+	if err := mgr.Kickout("1001", "web"); err != nil {
+		t.Fatalf("Kickout() error = %v", err)
+	}
+	if connA.closed != 1 || connB.closed != 0 {
+		t.Errorf("after kickout: closed = %d/%d, want 1/0", connA.closed, connB.closed)
+	}
+
+	if err := mgr.Disable("1001", time.Minute); err != nil {
+		t.Fatalf("Disable() error = %v", err)
+	}
+	if connB.closed != 1 || hub.Count() != 0 {
+		t.Errorf("after disable: closed = %d, count = %d", connB.closed, hub.Count())
+	}
+}
+
+func TestHubRelease(t *testing.T) {
+	mgr := newManager()
+	hub := NewHub(mgr)
+	hub.Stop()
+
+	token, _ := mgr.Login("1001")
+	conn := &fakeConn{}
+	release := hub.Bind(&Identity{LoginID: "1001", Token: token}, conn)
+	if hub.CountByLoginID("1001") != 1 {
+		t.Fatalf("CountByLoginID() = %d, want 1", hub.CountByLoginID("1001"))
+	}
+
+	release()
+	if hub.Count() != 0 || hub.CloseToken(token) != 0 || conn.closed != 0 {
+		t.Errorf("released connection should not be closed, count = %d", hub.Count())
+	}
+
+	// Stopped hub ignores logout events | 已停止的连接中心忽略登出事件
+	hub.Bind(&Identity{LoginID: "1001", Token: token}, conn)
+	_ = mgr.LogoutByToken(token)
+	if conn.closed != 0 {
+		t.Error("stopped hub should not react to events")
+	}
+}
@@ -0,0 +1,327 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"suwei.sa_token/core/adapter"
+	"suwei.sa_token/core/context"
+	"suwei.sa_token/core/manager"
+)
+
+// Route Rule Engine
+// 路由规则引擎
+//
+// Rules are evaluated in registration order. A rule applies when the path matches one of
+// its patterns, matches none of its exclusions and the method passes its filters. Every
+// check of an applying rule must pass; Stop() ends evaluation after the rule applied.
+// OPTIONS preflight requests are never checked.
+// 规则按注册顺序评估。路径匹配任一模式、不匹配任何排除模式且方法通过过滤时规则生效。
+// 生效规则的所有检查都必须通过；Stop() 在规则生效后结束评估。OPTIONS 预检请求不做检查。
+//
+// Path patterns | 路径模式:
+//   /user/info     exact match | 精确匹配
+//   /user/*        "*" matches exactly one segment | "*" 匹配一个段
+//   /admin/**      "**" matches zero or more segments | "**" 匹配零个或多个段
+//
+// Usage | 用法:
+//   r := router.New(manager)
+//   r.Match("/admin/**").NotMatch("/admin/login").CheckRole("admin")
+//   r.Match("/api/**").NotMethod("GET").CheckPermission("api:write")
+//   r.Match("/**").NotMatch("/login", "/public/**").CheckLogin()
+//   r.Match("/api/**").NotMatch("/api/webhook/**").CheckCSRF()
+
+// Constants for path patterns | 路径模式常量
+const (
+	Wildcard      = "*"  // Single segment wildcard | 单段通配符
+	MultiWildcard = "**" // Multi segment wildcard | 多段通配符
+	PathSeparator = "/"  // Path separator | 路径分隔符
+)
+
+// Error variables, shared with manager | 错误变量，与manager共用
+var (
+	ErrPermissionDenied = manager.ErrPermissionDenied
+	ErrRoleDenied       = manager.ErrRoleDenied
+	ErrInvalidCSRFToken = manager.ErrInvalidCSRFToken
+)
+
+// CheckFunc Check executed when a rule applies | 规则生效时执行的检查
+type CheckFunc func(saCtx *context.SaTokenContext) error
+
+// Router Ordered set of route rules | 有序的路由规则集合
+type Router struct {
+	manager *manager.Manager
+	mu      sync.RWMutex
+	rules   []*Rule
+}
+
+// New Creates a new router | 创建新的路由器
+func New(mgr *manager.Manager) *Router {
+	return &Router{
+		manager: mgr,
+	}
+}
+
+// Match Adds a rule for path patterns | 为路径模式添加规则
+func (r *Router) Match(patterns ...string) *Rule {
+	rule := &Rule{patterns: patterns}
+
+	r.mu.Lock()
+	r.rules = append(r.rules, rule)
+	r.mu.Unlock()
+
+	return rule
+}
+
+// Rules Returns registered rules | 返回已注册的规则
+func (r *Router) Rules() []*Rule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]*Rule(nil), r.rules...)
+}
+
+// Handle Evaluates rules against the request, returns the first failed check | 对请求评估规则，返回第一个失败的检查
+func (r *Router) Handle(ctx adapter.RequestContext) error {
+	method := strings.ToUpper(ctx.GetMethod())
+	if method == http.MethodOptions {
+		return nil
+	}
+	path := normalizePath(ctx.GetPath())
+
+	var saCtx *context.SaTokenContext
+	for _, rule := range r.Rules() {
+		if !rule.Applies(method, path) {
+			continue
+		}
+		if saCtx == nil {
+			saCtx = context.NewContext(ctx, r.manager)
+		}
+		for _, check := range rule.checks {
+			if err := check(saCtx); err != nil {
+				return err
+			}
+		}
+		if rule.stop {
+			return nil
+		}
+	}
+	return nil
+}
+
+// ============ Rule | 规则 ============
+
+// Rule Route rule built with chained calls | 通过链式调用构建的路由规则
+type Rule struct {
+	patterns       []string
+	excludes       []string
+	methods        []string
+	excludeMethods []string
+	checks         []CheckFunc
+	stop           bool
+}
+
+// NotMatch Excludes path patterns | 排除路径模式
+func (r *Rule) NotMatch(patterns ...string) *Rule {
+	r.excludes = append(r.excludes, patterns...)
+	return r
+}
+
+// Method Restricts the rule to HTTP methods | 限定规则的HTTP方法
+func (r *Rule) Method(methods ...string) *Rule {
+	r.methods = append(r.methods, upperAll(methods)...)
+	return r
+}
+
+// NotMethod Excludes HTTP methods | 排除HTTP方法
+func (r *Rule) NotMethod(methods ...string) *Rule {
+	r.excludeMethods = append(r.excludeMethods, upperAll(methods)...)
+	return r
+}
+
+// Check Adds custom checks | 添加自定义检查
+func (r *Rule) Check(checks ...CheckFunc) *Rule {
+	r.checks = append(r.checks, checks...)
+	return r
+}
+
+// CheckLogin Requires login | 要求登录
+func (r *Rule) CheckLogin() *Rule {
+	return r.Check(func(saCtx *context.SaTokenContext) error {
+		return saCtx.CheckLogin()
+	})
+}
+
+// CheckPermission Requires all permissions (AND) | 要求拥有所有权限（AND）
+func (r *Rule) CheckPermission(permissions ...string) *Rule {
+	return r.Check(func(saCtx *context.SaTokenContext) error {
+		if err := saCtx.CheckLogin(); err != nil {
+			return err
+		}
+		for _, perm := range permissions {
+			if !saCtx.HasPermission(perm) {
+				return fmt.Errorf("%w: %s", ErrPermissionDenied, perm)
+			}
+		}
+		return nil
+	})
+}
+
+// CheckPermissionOr Requires any permission (OR) | 要求拥有任一权限（OR）
+func (r *Rule) CheckPermissionOr(permissions ...string) *Rule {
+	return r.Check(func(saCtx *context.SaTokenContext) error {
+		if err := saCtx.CheckLogin(); err != nil {
+			return err
+		}
+		for _, perm := range permissions {
+			if saCtx.HasPermission(perm) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %s", ErrPermissionDenied, strings.Join(permissions, ","))
+	})
+}
+
+// CheckRole Requires all roles (AND) | 要求拥有所有角色（AND）
+func (r *Rule) CheckRole(roles ...string) *Rule {
+	return r.Check(func(saCtx *context.SaTokenContext) error {
+		if err := saCtx.CheckLogin(); err != nil {
+			return err
+		}
+		for _, role := range roles {
+			if !saCtx.HasRole(role) {
+				return fmt.Errorf("%w: %s", ErrRoleDenied, role)
+			}
+		}
+		return nil
+	})
+}
+
+// CheckRoleOr Requires any role (OR) | 要求拥有任一角色（OR）
+func (r *Rule) CheckRoleOr(roles ...string) *Rule {
+	return r.Check(func(saCtx *context.SaTokenContext) error {
+		if err := saCtx.CheckLogin(); err != nil {
+			return err
+		}
+		for _, role := range roles {
+			if saCtx.HasRole(role) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %s", ErrRoleDenied, strings.Join(roles, ","))
+	})
+}
+
+// CheckCSRF Requires a valid CSRF token on unsafe requests authenticated by cookie | 要求通过Cookie认证的非安全请求携带有效的CSRF令牌
+func (r *Rule) CheckCSRF() *Rule {
+	return r.Check(func(saCtx *context.SaTokenContext) error {
+		return saCtx.CheckCSRF()
+	})
+}
+
+// Stop Stops evaluating later rules once this rule applied | 本规则生效后停止评估后续规则
+func (r *Rule) Stop() *Rule {
+	r.stop = true
+	return r
+}
+
+// Applies Checks if rule applies to method and path | 检查规则是否适用于该方法和路径
+func (r *Rule) Applies(method, path string) bool {
+	method = strings.ToUpper(method)
+	if len(r.methods) > 0 && !containsString(r.methods, method) {
+		return false
+	}
+	if containsString(r.excludeMethods, method) {
+		return false
+	}
+
+	path = normalizePath(path)
+	if !matchAny(r.patterns, path) {
+		return false
+	}
+	return !matchAny(r.excludes, path)
+}
+
+// ============ Path Matching | 路径匹配 ============
+
+// MatchPath Checks if path matches pattern | 检查路径是否匹配模式
+func MatchPath(pattern, path string) bool {
+	return matchSegments(splitPath(pattern), splitPath(path))
+}
+
+// matchAny Checks if path matches any pattern | 检查路径是否匹配任一模式
+func matchAny(patterns []string, path string) bool {
+	for _, p := range patterns {
+		if MatchPath(p, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments Matches pattern segments against path segments | 将模式段与路径段匹配
+func matchSegments(pattern, path []string) bool {
+	for len(pattern) > 0 {
+		seg := pattern[0]
+		if seg == MultiWildcard {
+			// "**" consumes zero or more segments | "**" 消耗零个或多个段
+			for i := 0; i <= len(path); i++ {
+				if matchSegments(pattern[1:], path[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(path) == 0 {
+			return false
+		}
+		if seg != Wildcard && seg != path[0] {
+			return false
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return len(path) == 0
+}
+
+// splitPath Splits path into non-empty segments | 将路径拆分为非空段
+func splitPath(path string) []string {
+	parts := strings.Split(path, PathSeparator)
+	segments := parts[:0]
+	for _, p := range parts {
+		if p != "" {
+			segments = append(segments, p)
+		}
+	}
+	return segments
+}
+
+// normalizePath Ensures leading slash and strips query string | 确保前导斜杠并去除查询字符串
+func normalizePath(path string) string {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	if !strings.HasPrefix(path, PathSeparator) {
+		path = PathSeparator + path
+	}
+	return path
+}
+
+// upperAll Converts strings to upper case | 将字符串转换为大写
+func upperAll(values []string) []string {
+	result := make([]string, len(values))
+	for i, v := range values {
+		result[i] = strings.ToUpper(v)
+	}
+	return result
+}
+
+// containsString Checks if slice contains item | 检查切片是否包含元素
+func containsString(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package router
+
+import (
+	"errors"
+	"testing"
+
+	"suwei.sa_token/core/adapter"
+	"suwei.sa_token/core/context"
+)
+
+// fakeContext Request context exposing only method and path | 仅提供方法和路径的请求上下文
+type fakeContext struct {
+	adapter.RequestContext
+	method string
+	path   string
+}
+
+func (f *fakeContext) GetMethod() string { return f.method }
+func (f *fakeContext) GetPath() string   { return f.path }
+
+var errDenied = errors.New("denied")
+
+func deny(*context.SaTokenContext) error { return errDenied }
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"/user/info", "/user/info", true},
+		{"/user/info", "/user/info/", true},
+		{"/user/info", "/user/list", false},
+		{"/user/*", "/user/1", true},
+		{"/user/*", "/user/1/edit", false},
+		{"/user/*", "/user", false},
+		{"/admin/**", "/admin", true},
+		{"/admin/**", "/admin/a/b/c", true},
+		{"/admin/**", "/administrator", false},
+		{"/**/export", "/report/2024/export", true},
+		{"/**", "/", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"|"+tt.path, func(t *testing.T) {
+			if got := MatchPath(tt.pattern, tt.path); got != tt.want {
+				t.Errorf("MatchPath(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouterRules(t *testing.T) {
+	r := New(nil)
+	r.Match("/admin/**").NotMatch("/admin/login").Check(deny)
+	r.Match("/api/**").Method("POST", "delete").Check(deny)
+
+	tests := []struct {
+		method string
+		path   string
+		denied bool
+	}{
+		{"GET", "/admin/users", true},
+		{"GET", "/admin/login", false},
+		{"OPTIONS", "/admin/users", false},
+		{"GET", "/api/orders", false},
+		{"POST", "/api/orders", true},
+		{"DELETE", "/api/orders/1", true},
+		{"GET", "/public/index?x=1", false},
+	}
+
+	for _, tt := range tests {
+		err := r.Handle(&fakeContext{method: tt.method, path: tt.path})
+		if got := errors.Is(err, errDenied); got != tt.denied {
+			t.Errorf("%s %s denied = %v, want %v", tt.method, tt.path, got, tt.denied)
+		}
+	}
+}
+
+func TestRouterStop(t *testing.T) {
+	r := New(nil)
+	r.Match("/public/**").Stop()
+	r.Match("/**").NotMethod("GET").Check(deny)
+
+	if err := r.Handle(&fakeContext{method: "POST", path: "/public/upload"}); err != nil {
+		t.Errorf("stopped rule should skip later rules, got %v", err)
+	}
+	if err := r.Handle(&fakeContext{method: "POST", path: "/private"}); !errors.Is(err, errDenied) {
+		t.Errorf("expected denied, got %v", err)
+	}
+	if err := r.Handle(&fakeContext{method: "GET", path: "/private"}); err != nil {
+		t.Errorf("excluded method should pass, got %v", err)
+	}
+}
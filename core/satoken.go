@@ -1,15 +1,23 @@
 package core
 
 import (
+	"crypto"
 	"time"
 
+	"suwei.sa_token/core/acl"
 	"suwei.sa_token/core/adapter"
+	"suwei.sa_token/core/annotation"
 	"suwei.sa_token/core/builder"
 	"suwei.sa_token/core/config"
 	"suwei.sa_token/core/context"
+	"suwei.sa_token/core/jwk"
 	"suwei.sa_token/core/listener"
 	"suwei.sa_token/core/manager"
 	"suwei.sa_token/core/oauth2"
+	"suwei.sa_token/core/permission"
+	"suwei.sa_token/core/policy"
+	"suwei.sa_token/core/realtime"
+	"suwei.sa_token/core/router"
 	"suwei.sa_token/core/security"
 	"suwei.sa_token/core/session"
 	"suwei.sa_token/core/token"
@@ -24,28 +32,70 @@ const Version = "0.1.1"
 
 // Configuration related types | 配置相关类型
 type (
-	Config       = config.Config
-	CookieConfig = config.CookieConfig
-	TokenStyle   = config.TokenStyle
+	Config              = config.Config
+	CookieConfig        = config.CookieConfig
+	TokenBindingConfig  = config.TokenBindingConfig
+	JwtRevocationConfig = config.JwtRevocationConfig
+	BindingPolicy       = config.BindingPolicy
+	TokenStyle          = config.TokenStyle
+	TokenSource         = config.TokenSource
+	JwtMode             = config.JwtMode
 )
 
 // Token style constants | Token风格常量
 const (
-	TokenStyleUUID      = config.TokenStyleUUID
-	TokenStyleSimple    = config.TokenStyleSimple
-	TokenStyleRandom32  = config.TokenStyleRandom32
-	TokenStyleRandom64  = config.TokenStyleRandom64
-	TokenStyleRandom128 = config.TokenStyleRandom128
-	TokenStyleJWT       = config.TokenStyleJWT
-	TokenStyleHash      = config.TokenStyleHash
-	TokenStyleTimestamp = config.TokenStyleTimestamp
-	TokenStyleTik       = config.TokenStyleTik
+	TokenStyleUUID           = config.TokenStyleUUID
+	TokenStyleSimple         = config.TokenStyleSimple
+	TokenStyleRandom32       = config.TokenStyleRandom32
+	TokenStyleRandom64       = config.TokenStyleRandom64
+	TokenStyleRandom128      = config.TokenStyleRandom128
+	TokenStyleJWT            = config.TokenStyleJWT
+	TokenStyleHash           = config.TokenStyleHash
+	TokenStyleTimestamp      = config.TokenStyleTimestamp
+	TokenStyleTik            = config.TokenStyleTik
+	TokenStyleULID           = config.TokenStyleULID
+	TokenStyleSnowflake      = config.TokenStyleSnowflake
+	TokenStylePasetoV4Public = config.TokenStylePasetoV4Public
+	TokenStylePasetoV4Local  = config.TokenStylePasetoV4Local
+)
+
+// Token source constants | Token来源常量
+const (
+	TokenSourceHeader = config.TokenSourceHeader
+	TokenSourceCookie = config.TokenSourceCookie
+	TokenSourceBody   = config.TokenSourceBody
+	TokenSourceQuery  = config.TokenSourceQuery
+)
+
+// Token binding policy constants | Token绑定策略常量
+const (
+	BindingPolicyReject = config.BindingPolicyReject
+	BindingPolicySafe   = config.BindingPolicySafe
+	BindingPolicyEvent  = config.BindingPolicyEvent
+)
+
+// JWT mode constants | JWT模式常量
+const (
+	JwtModeSimple    = config.JwtModeSimple
+	JwtModeMixin     = config.JwtModeMixin
+	JwtModeStateless = config.JwtModeStateless
+)
+
+// JWT signing algorithm constants | JWT签名算法常量
+const (
+	JwtAlgHS256 = jwk.AlgHS256
+	JwtAlgRS256 = jwk.AlgRS256
+	JwtAlgES256 = jwk.AlgES256
+	JwtAlgEdDSA = jwk.AlgEdDSA
 )
 
 // Core types | 核心类型
 type (
 	Manager             = manager.Manager
 	TokenInfo           = manager.TokenInfo
+	LoginOptions        = manager.LoginOptions
+	ClientFingerprint   = manager.ClientFingerprint
+	TimedGrant          = manager.TimedGrant
 	Session             = session.Session
 	TokenGenerator      = token.Generator
 	SaTokenContext      = context.SaTokenContext
@@ -57,6 +107,46 @@ type (
 	OAuth2Client        = oauth2.Client
 	OAuth2AccessToken   = oauth2.AccessToken
 	OAuth2GrantType     = oauth2.GrantType
+	PermissionMatcher   = permission.Matcher
+	Policy              = policy.Policy
+	PolicyFunc          = policy.PolicyFunc
+	PolicyRule          = policy.Rule
+	PolicyRequest       = policy.Request
+	PolicySubject       = policy.Subject
+	PolicyResource      = policy.Resource
+	PolicyDecision      = policy.Decision
+	ACLStore            = acl.Store
+	ACLGrant            = acl.Grant
+	Router              = router.Router
+	RouterRule          = router.Rule
+	RouterCheckFunc     = router.CheckFunc
+	Annotation          = annotation.Annotation
+	AnnotationMode      = annotation.Mode
+	RealtimeHub         = realtime.Hub
+	RealtimeIdentity    = realtime.Identity
+	RealtimeConn        = realtime.Conn
+	RealtimeConnFunc    = realtime.ConnFunc
+	HandshakeAuth       = realtime.Authenticator
+	JwtKey              = jwk.Key
+	JwtKeySet           = jwk.KeySet
+	JWKS                = jwk.JWKS
+	// TokenStyleGenerator generates tokens of a registered style; TokenGenerator is the configured generator |
+	// TokenStyleGenerator 生成某种已注册风格的Token；TokenGenerator 是按配置工作的生成器
+	TokenStyleGenerator     = token.TokenGenerator
+	TokenStyleGeneratorFunc = token.TokenGeneratorFunc
+)
+
+// Annotation mode constants | 注解模式常量
+const (
+	AnnotationModeAnd = annotation.ModeAnd
+	AnnotationModeOr  = annotation.ModeOr
+)
+
+// Policy decision constants | 策略决策常量
+const (
+	PolicyNotApplicable = policy.NotApplicable
+	PolicyAllow         = policy.Allow
+	PolicyDeny          = policy.Deny
 )
 
 // Adapter interfaces | 适配器接口
@@ -77,17 +167,19 @@ type (
 
 // Event constants | 事件常量
 const (
-	EventLogin           = listener.EventLogin
-	EventLogout          = listener.EventLogout
-	EventKickout         = listener.EventKickout
-	EventDisable         = listener.EventDisable
-	EventUntie           = listener.EventUntie
-	EventRenew           = listener.EventRenew
-	EventCreateSession   = listener.EventCreateSession
-	EventDestroySession  = listener.EventDestroySession
-	EventPermissionCheck = listener.EventPermissionCheck
-	EventRoleCheck       = listener.EventRoleCheck
-	EventAll             = listener.EventAll
+	EventLogin             = listener.EventLogin
+	EventLogout            = listener.EventLogout
+	EventKickout           = listener.EventKickout
+	EventDisable           = listener.EventDisable
+	EventUntie             = listener.EventUntie
+	EventRenew             = listener.EventRenew
+	EventCreateSession     = listener.EventCreateSession
+	EventDestroySession    = listener.EventDestroySession
+	EventPermissionCheck   = listener.EventPermissionCheck
+	EventRoleCheck         = listener.EventRoleCheck
+	EventBindingMismatch   = listener.EventBindingMismatch
+	EventRefreshTokenReuse = listener.EventRefreshTokenReuse
+	EventAll               = listener.EventAll
 )
 
 const (
@@ -185,6 +277,102 @@ func NewRefreshTokenManager(storage Storage, prefix string, cfg *Config) *Refres
 	return security.NewRefreshTokenManager(storage, prefix, cfg)
 }
 
+// CompilePermissions Compiles permission patterns into a matcher | 将权限模式编译为匹配器
+func CompilePermissions(patterns []string, ignoreCase bool) *PermissionMatcher {
+	return permission.Compile(patterns, ignoreCase)
+}
+
+// NewPolicyResource Creates a policy resource | 创建策略资源
+func NewPolicyResource(resourceType, id string, attributes map[string]any) *PolicyResource {
+	return policy.NewResource(resourceType, id, attributes)
+}
+
+// NewACLStore Creates a new ACL store | 创建新的ACL存储
+func NewACLStore(storage Storage, prefix string) *ACLStore {
+	return acl.NewStore(storage, prefix)
+}
+
+// ACLUserSubject Builds ACL subject for a login ID | 构建登录ID的ACL主体
+func ACLUserSubject(loginID string) string {
+	return acl.UserSubject(loginID)
+}
+
+// ACLRoleSubject Builds ACL subject for a role | 构建角色的ACL主体
+func ACLRoleSubject(role string) string {
+	return acl.RoleSubject(role)
+}
+
+// NewRouter Creates a new route rule engine | 创建新的路由规则引擎
+func NewRouter(mgr *Manager) *Router {
+	return router.New(mgr)
+}
+
+// MatchPath Checks if path matches a route pattern | 检查路径是否匹配路由模式
+func MatchPath(pattern, path string) bool {
+	return router.MatchPath(pattern, path)
+}
+
+// ParseAnnotationTag Parses an annotation tag | 解析注解标签
+func ParseAnnotationTag(tag string) *Annotation {
+	return annotation.ParseTag(tag)
+}
+
+// CheckAnnotations Runs annotation checks for the request | 对请求执行注解检查
+func CheckAnnotations(saCtx *SaTokenContext, annotations ...*Annotation) error {
+	return annotation.CheckAll(saCtx, annotations...)
+}
+
+// NewHandshakeAuth Creates a WebSocket/SSE handshake authenticator | 创建WebSocket/SSE握手认证器
+func NewHandshakeAuth(mgr *Manager) *HandshakeAuth {
+	return realtime.NewAuthenticator(mgr)
+}
+
+// NewRealtimeHub Creates a hub closing live connections on logout, kickout and disable |
+// 创建在登出、踢下线和封禁时关闭存活连接的连接中心
+func NewRealtimeHub(mgr *Manager) *RealtimeHub {
+	return realtime.NewHub(mgr)
+}
+
+// RegisterTokenGenerator Registers the generator of a token style | 注册某种Token风格的生成器
+func RegisterTokenGenerator(style TokenStyle, gen TokenStyleGenerator) error {
+	return token.RegisterGenerator(style, gen)
+}
+
+// NewRandomTokenGenerator Creates a generator of random tokens with a given length and charset | 创建指定长度和字符集的随机Token生成器
+func NewRandomTokenGenerator(length int, charset string) TokenStyleGenerator {
+	return token.NewRandomGenerator(length, charset)
+}
+
+// NewSnowflakeTokenGenerator Creates a snowflake ID generator for a node | 为节点创建雪花ID生成器
+func NewSnowflakeTokenGenerator(node int64) (TokenStyleGenerator, error) {
+	return token.NewSnowflakeGenerator(node)
+}
+
+// NewJwtKeySet Creates an empty JWT key set | 创建空的JWT密钥集
+func NewJwtKeySet() *JwtKeySet {
+	return jwk.NewKeySet()
+}
+
+// GenerateJwtKey Generates an RS256, ES256 or EdDSA signing key | 生成RS256、ES256或EdDSA签名密钥
+func GenerateJwtKey(id string, algorithm string) (*JwtKey, error) {
+	return jwk.GenerateKey(id, algorithm)
+}
+
+// NewJwtKey Creates a signing key from an RSA, ECDSA P-256 or Ed25519 private key | 从RSA、ECDSA P-256或Ed25519私钥创建签名密钥
+func NewJwtKey(id string, private crypto.PrivateKey) (*JwtKey, error) {
+	return jwk.NewKey(id, private)
+}
+
+// NewJwtHMACKey Creates an HS256 signing key | 创建HS256签名密钥
+func NewJwtHMACKey(id string, secret []byte) (*JwtKey, error) {
+	return jwk.NewHMACKey(id, secret)
+}
+
+// ParseJWKS Builds a verification-only key set from a JWKS document | 从JWKS文档构建仅用于验证的密钥集
+func ParseJWKS(data []byte) (*JwtKeySet, error) {
+	return jwk.ParseJWKS(data)
+}
+
 // NewOAuth2Server Creates a new OAuth2 server | 创建新的OAuth2服务器
 func NewOAuth2Server(storage Storage, prefix string) *OAuth2Server {
 	return oauth2.NewOAuth2Server(storage, prefix)
@@ -0,0 +1,62 @@
+package security
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// bloomFilter Fixed-size Bloom filter, answers "maybe present" or "definitely absent" |
+// 固定大小的布隆过滤器，回答"可能存在"或"一定不存在"
+type bloomFilter struct {
+	bits   []uint64
+	size   uint64 // Number of bits | 位数
+	hashes uint64 // Number of hash functions | 哈希函数数量
+}
+
+// newBloomFilter Sizes a filter for n items at false positive rate p | 按n个元素和误判率p确定过滤器大小
+func newBloomFilter(n int, p float64) *bloomFilter {
+	if n < 1 {
+		n = 1
+	}
+	size := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	size = (size + 63) / 64 * 64
+	hashes := uint64(math.Round(float64(size) / float64(n) * math.Ln2))
+	if hashes < 1 {
+		hashes = 1
+	}
+	return &bloomFilter{
+		bits:   make([]uint64, size/64),
+		size:   size,
+		hashes: hashes,
+	}
+}
+
+// add Adds an item | 添加元素
+func (f *bloomFilter) add(item string) {
+	h1, h2 := bloomHash(item)
+	for i := uint64(0); i < f.hashes; i++ {
+		bit := (h1 + i*h2) % f.size
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// test Checks if an item may have been added | 检查元素是否可能已添加
+func (f *bloomFilter) test(item string) bool {
+	h1, h2 := bloomHash(item)
+	for i := uint64(0); i < f.hashes; i++ {
+		bit := (h1 + i*h2) % f.size
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// bloomHash Derives the two base hashes of double hashing | 推导双重哈希的两个基础哈希
+func bloomHash(item string) (uint64, uint64) {
+	a := fnv.New64a()
+	a.Write([]byte(item))
+	b := fnv.New64()
+	b.Write([]byte(item))
+	return a.Sum64(), b.Sum64() | 1
+}
@@ -0,0 +1,18 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// TokenID Gets the form of a token kept in storage: its HMAC-SHA256 keyed with pepper, or the value
+// itself when pepper is empty | 获取Token在存储中的形式：以pepper为密钥的HMAC-SHA256，pepper为空时为原值
+func TokenID(pepper, tokenValue string) string {
+	if pepper == "" || tokenValue == "" {
+		return tokenValue
+	}
+	mac := hmac.New(sha256.New, []byte(pepper))
+	mac.Write([]byte(tokenValue))
+	return hex.EncodeToString(mac.Sum(nil))
+}
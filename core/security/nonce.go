@@ -60,11 +60,10 @@ func NewNonceManager(storage adapter.Storage, prefix string, ttl time.Duration)
 // Generate Generates a new nonce and stores it | 生成新的nonce并存储
 // Returns 64-char hex string | 返回64字符的十六进制字符串
 func (nm *NonceManager) Generate() (string, error) {
-	bytes := make([]byte, NonceLength)
-	if _, err := rand.Read(bytes); err != nil {
-		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	nonce, err := nm.Random()
+	if err != nil {
+		return "", err
 	}
-	nonce := hex.EncodeToString(bytes)
 
 	key := nm.getNonceKey(nonce)
 	if err := nm.storage.Set(key, time.Now().Unix(), nm.ttl); err != nil {
@@ -74,6 +73,16 @@ func (nm *NonceManager) Generate() (string, error) {
 	return nonce, nil
 }
 
+// Random Generates a nonce-strength random value without storing it | 生成nonce强度的随机值，不存储
+// Returns 64-char hex string | 返回64字符的十六进制字符串
+func (nm *NonceManager) Random() (string, error) {
+	bytes := make([]byte, NonceLength)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
 // Verify Verifies nonce and consumes it (one-time use) | 验证nonce并消费它（一次性使用）
 // Returns false if nonce doesn't exist or already used | 如果nonce不存在或已使用则返回false
 func (nm *NonceManager) Verify(nonce string) bool {
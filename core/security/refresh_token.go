@@ -3,7 +3,9 @@ package security
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"suwei.sa_token/core/adapter"
@@ -17,20 +19,52 @@ import (
 // Flow | 流程:
 // 1. GenerateTokenPair() - Create access token + refresh token | 创建访问令牌 + 刷新令牌
 // 2. Access token expires (short-lived, e.g. 2h) | 访问令牌过期（短期，如2小时）
-// 3. RefreshAccessToken() - Use refresh token to get new access token | 使用刷新令牌获取新访问令牌
-// 4. Refresh token expires (long-lived, 30 days) | 刷新令牌过期（长期，30天）
+// 3. RefreshAccessToken() - Use refresh token to get new access token and refresh token | 使用刷新令牌获取新访问令牌和新刷新令牌
+// 4. Refresh token expires (Config.RefreshTokenTimeout, default 30 days) | 刷新令牌过期（Config.RefreshTokenTimeout，默认30天）
+//
+// Rotation | 轮换:
+// Every refresh token is single-use. A refresh issues a new refresh token in the same family and
+// the family remembers only its latest token. Presenting a token that was already rotated means
+// it leaked: the whole family is revoked and ErrRefreshTokenReused is returned (OAuth 2.0
+// Security BCP). Each new token lives for RefreshTokenTimeout from its refresh.
+// 每个刷新令牌只能使用一次。刷新会在同一家族中签发新的刷新令牌，家族只记录其最新令牌。出示已被轮换的
+// 令牌意味着令牌已泄露：整个家族被撤销并返回 ErrRefreshTokenReused（OAuth 2.0 安全最佳实践）。
+// 每个新令牌从刷新时起有效期为 RefreshTokenTimeout。
+//
+// A refresh claims the family with a compare-and-swap of its record before issuing anything, so
+// of concurrent refreshes with one token, across instances too, only one can win. The others
+// count as reuse. Storages implementing adapter.AtomicStorage swap in one step; for the others
+// the swap is only atomic within this process.
+// 刷新在签发任何内容之前先通过比较并交换家族记录来占有家族，因此使用同一令牌的并发刷新（包括跨实例）
+// 只有一个能成功，其余视为重用。实现了 adapter.AtomicStorage 的存储一步完成交换；其他存储的交换只在
+// 本进程内是原子的。
+//
+// With Config.TokenHashPepper set, records are keyed by the token ID of the refresh token and the
+// family keeps only that ID. Records read back from storage, e.g. by ListRefreshTokens, carry the
+// token ID in RefreshToken.
+// 设置 Config.TokenHashPepper 后，记录以刷新令牌的Token ID为键，家族也只保存该ID。从存储读取的记录
+// （如 ListRefreshTokens）的 RefreshToken 为Token ID。
+//
+// Manager logs the access token in with Issue and swaps it with Rotate, so the token pair is a
+// real login and a refresh retires the previous access token. GenerateTokenPair and
+// RefreshAccessToken only mint tokens and are kept for standalone use.
+// Manager 通过 Issue 登录访问令牌并通过 Rotate 替换，因此令牌对是真实的登录，刷新会使之前的访问令牌失效。
+// GenerateTokenPair 和 RefreshAccessToken 只生成令牌，保留用于单独使用。
 //
 // Usage | 用法:
 //   tokenInfo, _ := manager.LoginWithRefreshToken(loginID, "web")
 //   // ... access token expires ...
 //   newInfo, _ := manager.RefreshAccessToken(tokenInfo.RefreshToken)
+//   // tokenInfo.RefreshToken is spent, keep newInfo.RefreshToken
 
 // Constants for refresh token | 刷新令牌常量
 const (
-	DefaultRefreshTTL  = 30 * 24 * time.Hour // 30 days | 30天
-	DefaultAccessTTL   = 2 * time.Hour       // 2 hours | 2小时
-	RefreshTokenLength = 32                  // Refresh token byte length | 刷新令牌字节长度
-	RefreshKeySuffix   = "refresh:"          // Key suffix after prefix | 前缀后的键后缀
+	DefaultRefreshTTL      = 30 * 24 * time.Hour // 30 days | 30天
+	DefaultAccessTTL       = 2 * time.Hour       // 2 hours | 2小时
+	RefreshTokenLength     = 32                  // Refresh token byte length | 刷新令牌字节长度
+	RefreshFamilyLength    = 16                  // Family ID byte length | 家族ID字节长度
+	RefreshKeySuffix       = "refresh:"          // Key suffix after prefix | 前缀后的键后缀
+	RefreshFamilyKeySuffix = "refresh-family:"   // Family key suffix after prefix | 前缀后的家族键后缀
 )
 
 // Error variables | 错误变量
@@ -38,16 +72,36 @@ var (
 	ErrInvalidRefreshToken = fmt.Errorf("invalid refresh token")
 	ErrRefreshTokenExpired = fmt.Errorf("refresh token expired")
 	ErrInvalidRefreshData  = fmt.Errorf("invalid refresh token data")
+	ErrRefreshTokenReused  = fmt.Errorf("refresh token reused: the token family is revoked")
 )
 
 // RefreshTokenInfo refresh token information | 刷新令牌信息
 type RefreshTokenInfo struct {
-	RefreshToken string // Refresh token (long-lived) | 刷新令牌（长期有效）
-	AccessToken  string // Access token (short-lived) | 访问令牌（短期有效）
-	LoginID      string // User login ID | 用户登录ID
-	Device       string // Device type | 设备类型
-	CreateTime   int64  // Creation timestamp | 创建时间戳
-	ExpireTime   int64  // Expiration timestamp | 过期时间戳
+	RefreshToken string `json:"refreshToken"` // Refresh token (long-lived) | 刷新令牌（长期有效）
+	AccessToken  string `json:"accessToken"`  // Access token issued with it (short-lived) | 与其一起签发的访问令牌（短期有效）
+	FamilyID     string `json:"familyId"`     // Rotation family, shared by all refresh tokens of one login | 轮换家族，同一次登录的所有刷新令牌共享
+	LoginID      string `json:"loginId"`      // User login ID | 用户登录ID
+	Device       string `json:"device"`       // Device type | 设备类型
+	CreateTime   int64  `json:"createTime"`   // Creation timestamp | 创建时间戳
+	ExpireTime   int64  `json:"expireTime"`   // Expiration timestamp, 0 never expires | 过期时间戳，0为永不过期
+
+	// AccessExpireTime, AccessJTI exp and jti claims of a JWT or PASETO access token, to revoke it for as long as it lives |
+	// JWT或PASETO访问令牌的exp和jti声明，用于在其有效期内撤销
+	AccessExpireTime int64  `json:"accessExpireTime,omitempty"`
+	AccessJTI        string `json:"accessJti,omitempty"`
+
+	// Options Caller data kept for the whole family, e.g. the login options to reissue with |
+	// 整个家族保留的调用方数据，例如重新签发时使用的登录选项
+	Options json.RawMessage `json:"options,omitempty"`
+}
+
+// AccessTokenIssuer Issues the access token that replaces old on refresh, setting AccessToken and the Access fields of next |
+// 刷新时签发替换old的访问令牌，并设置next的AccessToken及Access字段
+type AccessTokenIssuer func(old, next *RefreshTokenInfo) error
+
+// refreshFamily Rotation state of a family | 家族的轮换状态
+type refreshFamily struct {
+	Latest string `json:"latest"` // Token ID of the only refresh token of the family that may rotate | 家族中唯一可以轮换的刷新令牌的Token ID
 }
 
 // RefreshTokenManager Refresh token manager | 刷新令牌管理器
@@ -55,13 +109,16 @@ type RefreshTokenManager struct {
 	storage    adapter.Storage
 	keyPrefix  string // Configurable prefix | 可配置的前缀
 	tokenGen   *token.Generator
-	refreshTTL time.Duration // Refresh token TTL (30 days) | 刷新令牌有效期（30天）
+	refreshTTL time.Duration // Refresh token TTL, 0 never expires | 刷新令牌有效期，0为永不过期
 	accessTTL  time.Duration // Access token TTL (configurable) | 访问令牌有效期（可配置）
+	pepper     string        // Config.TokenHashPepper, refresh tokens are stored by ID when set | 设置时刷新令牌以ID存储
+	mu         sync.Mutex    // Guards compare-and-swap on storages without AtomicStorage | 为不支持AtomicStorage的存储保护比较并交换
 }
 
 // NewRefreshTokenManager Creates a new refresh token manager | 创建新的刷新令牌管理器
 // prefix: key prefix (e.g., "satoken:" or "" for Java compatibility) | 键前缀（如："satoken:" 或 "" 兼容Java）
-// cfg: configuration, uses Timeout for access token TTL | 配置，使用Timeout作为访问令牌有效期
+// cfg: configuration, uses Timeout for access token TTL and RefreshTokenTimeout for refresh token TTL |
+// 配置，使用Timeout作为访问令牌有效期，RefreshTokenTimeout作为刷新令牌有效期
 func NewRefreshTokenManager(storage adapter.Storage, prefix string, cfg *config.Config) *RefreshTokenManager {
 	accessTTL := time.Duration(cfg.Timeout) * time.Second
 
@@ -69,12 +126,21 @@ func NewRefreshTokenManager(storage adapter.Storage, prefix string, cfg *config.
 		accessTTL = DefaultAccessTTL
 	}
 
+	refreshTTL := time.Duration(cfg.RefreshTokenTimeout) * time.Second
+	switch {
+	case cfg.RefreshTokenTimeout == 0:
+		refreshTTL = DefaultRefreshTTL
+	case cfg.RefreshTokenTimeout < 0:
+		refreshTTL = 0
+	}
+
 	return &RefreshTokenManager{
 		storage:    storage,
 		keyPrefix:  prefix,
 		tokenGen:   token.NewGenerator(cfg),
-		refreshTTL: DefaultRefreshTTL,
+		refreshTTL: refreshTTL,
 		accessTTL:  accessTTL,
+		pepper:     cfg.TokenHashPepper,
 	}
 }
 
@@ -90,78 +156,191 @@ func (rtm *RefreshTokenManager) GenerateTokenPair(loginID, device string) (*Refr
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
-	// Generate refresh token | 生成刷新令牌
-	refreshTokenBytes := make([]byte, RefreshTokenLength)
-	if _, err := rand.Read(refreshTokenBytes); err != nil {
-		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	return rtm.Issue(loginID, device, accessToken)
+}
+
+// Issue Starts a refresh token family for an access token issued by the caller | 为调用方签发的访问令牌创建新的刷新令牌家族
+func (rtm *RefreshTokenManager) Issue(loginID, device, accessToken string) (*RefreshTokenInfo, error) {
+	return rtm.IssueFor(&RefreshTokenInfo{LoginID: loginID, Device: device, AccessToken: accessToken})
+}
+
+// IssueFor Starts a refresh token family for the access token in access: LoginID, Device, AccessToken, the Access
+// fields and Options, which is carried to every rotation | 为access中的访问令牌创建刷新令牌家族：使用LoginID、Device、
+// AccessToken、Access字段及Options，Options在每次轮换时保留
+func (rtm *RefreshTokenManager) IssueFor(access *RefreshTokenInfo) (*RefreshTokenInfo, error) {
+	if access.LoginID == "" {
+		return nil, fmt.Errorf("loginID cannot be empty")
 	}
-	refreshToken := hex.EncodeToString(refreshTokenBytes)
 
-	now := time.Now()
-	info := &RefreshTokenInfo{
-		RefreshToken: refreshToken,
-		AccessToken:  accessToken,
-		LoginID:      loginID,
-		Device:       device,
-		CreateTime:   now.Unix(),
-		ExpireTime:   now.Add(rtm.refreshTTL).Unix(),
+	familyID, err := randomHex(RefreshFamilyLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token family: %w", err)
 	}
 
-	key := rtm.getRefreshKey(refreshToken)
-	if err := rtm.storage.Set(key, info, rtm.refreshTTL); err != nil {
+	refreshToken, err := randomHex(RefreshTokenLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	info := rtm.newRecord(refreshToken, &RefreshTokenInfo{
+		AccessToken:      access.AccessToken,
+		AccessExpireTime: access.AccessExpireTime,
+		AccessJTI:        access.AccessJTI,
+		FamilyID:         familyID,
+		LoginID:          access.LoginID,
+		Device:           access.Device,
+		Options:          access.Options,
+	})
+	if err := rtm.save(rtm.getRefreshKey(info.RefreshToken), info); err != nil {
 		return nil, fmt.Errorf("failed to store refresh token: %w", err)
 	}
+	if err := rtm.save(rtm.getFamilyKey(info), &refreshFamily{Latest: info.RefreshToken}); err != nil {
+		rtm.storage.Delete(rtm.getRefreshKey(info.RefreshToken))
+		return nil, fmt.Errorf("failed to store refresh token family: %w", err)
+	}
 
+	info.RefreshToken = refreshToken
 	return info, nil
 }
 
-// RefreshAccessToken Generates new access token using refresh token | 使用刷新令牌生成新的访问令牌
+// RefreshAccessToken Generates new access token and refresh token using refresh token | 使用刷新令牌生成新的访问令牌和刷新令牌
 func (rtm *RefreshTokenManager) RefreshAccessToken(refreshToken string) (*RefreshTokenInfo, error) {
-	if refreshToken == "" {
-		return nil, ErrInvalidRefreshToken
-	}
+	return rtm.Rotate(refreshToken, func(old, next *RefreshTokenInfo) error {
+		accessToken, err := rtm.tokenGen.Generate(old.LoginID, old.Device)
+		next.AccessToken = accessToken
+		return err
+	}, nil)
+}
 
-	key := rtm.getRefreshKey(refreshToken)
+// Rotate Spends a refresh token for a new one in its family, carrying the access token from issue |
+// 消费刷新令牌并换取同一家族的新刷新令牌，携带issue签发的访问令牌
+// Presenting a spent token, or losing a concurrent refresh, revokes the family and calls reused with
+// the family's latest token, or the presented one when the latest is gone |
+// 出示已消费的令牌或在并发刷新中失败会撤销家族，并以家族的最新令牌调用reused，最新令牌已不存在时使用出示的令牌
+func (rtm *RefreshTokenManager) Rotate(refreshToken string, issue AccessTokenIssuer, reused func(latest *RefreshTokenInfo)) (*RefreshTokenInfo, error) {
+	id := rtm.tokenID(refreshToken)
+	info, err := rtm.getRecord(id)
+	if err != nil {
+		return nil, err
+	}
 
-	data, err := rtm.storage.Get(key)
-	if err != nil || data == nil {
-		return nil, ErrInvalidRefreshToken
+	// Check expiration | 检查是否过期
+	if info.ExpireTime > 0 && time.Now().Unix() > info.ExpireTime {
+		rtm.storage.Delete(rtm.getRefreshKey(id))
+		return nil, ErrRefreshTokenExpired
 	}
 
-	oldInfo, ok := data.(*RefreshTokenInfo)
+	// Only the latest token of a live family rotates | 只有存活家族的最新令牌可以轮换
+	familyKey := rtm.getFamilyKey(info)
+	current, family, ok := rtm.readFamily(familyKey)
 	if !ok {
-		return nil, ErrInvalidRefreshData
+		return nil, ErrInvalidRefreshToken
 	}
-
-	// Check expiration | 检查是否过期
-	if time.Now().Unix() > oldInfo.ExpireTime {
-		rtm.storage.Delete(key)
-		return nil, ErrRefreshTokenExpired
+	if family.Latest != id {
+		rtm.reuse(info, reused)
+		return nil, ErrRefreshTokenReused
 	}
 
-	// Generate new access token | 生成新的访问令牌
-	newAccessToken, err := rtm.tokenGen.Generate(oldInfo.LoginID, oldInfo.Device)
+	// Claim the family for the next token, a concurrent refresh that got here first wins |
+	// 为下一个令牌占有家族，先到达的并发刷新获胜
+	nextToken, err := randomHex(RefreshTokenLength)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate new access token: %w", err)
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	next := rtm.newRecord(nextToken, &RefreshTokenInfo{
+		FamilyID: info.FamilyID,
+		LoginID:  info.LoginID,
+		Device:   info.Device,
+		Options:  info.Options,
+	})
+	claimed, err := json.Marshal(&refreshFamily{Latest: next.RefreshToken})
+	if err != nil {
+		return nil, err
+	}
+	swapped, err := rtm.compareAndSwap(familyKey, current, string(claimed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update refresh token family: %w", err)
+	}
+	if !swapped {
+		rtm.reuse(info, reused)
+		return nil, ErrRefreshTokenReused
 	}
 
-	oldInfo.AccessToken = newAccessToken
-
-	// Update storage | 更新存储
-	if err := rtm.storage.Set(key, oldInfo, rtm.refreshTTL); err != nil {
+	// Issue new access token, hand the family back on failure | 签发新的访问令牌，失败时归还家族
+	old := *info
+	if err := issue(&old, next); err != nil {
+		rtm.compareAndSwap(familyKey, string(claimed), current)
+		return nil, fmt.Errorf("failed to generate new access token: %w", err)
+	}
+	if err := rtm.save(rtm.getRefreshKey(next.RefreshToken), next); err != nil {
+		rtm.compareAndSwap(familyKey, string(claimed), current)
 		return nil, fmt.Errorf("failed to update refresh token: %w", err)
 	}
 
-	return oldInfo, nil
+	// A reuse may have revoked the family meanwhile | 期间家族可能已因重用被撤销
+	if _, family, ok := rtm.readFamily(familyKey); !ok || family.Latest != next.RefreshToken {
+		rtm.storage.Delete(rtm.getRefreshKey(next.RefreshToken))
+		return nil, ErrRefreshTokenReused
+	}
+
+	next.RefreshToken = nextToken
+	return next, nil
 }
 
-// RevokeRefreshToken Revokes a refresh token | 撤销刷新令牌
+// RevokeRefreshToken Revokes a refresh token with its family | 撤销刷新令牌及其家族
 func (rtm *RefreshTokenManager) RevokeRefreshToken(refreshToken string) error {
+	_, err := rtm.RevokeFamily(refreshToken)
+	return err
+}
+
+// RevokeFamily Revokes the family of a refresh token, returns its latest token, nil if already gone |
+// 撤销刷新令牌所在的家族，返回其最新令牌，已不存在时返回nil
+func (rtm *RefreshTokenManager) RevokeFamily(refreshToken string) (*RefreshTokenInfo, error) {
 	if refreshToken == "" {
-		return nil
+		return nil, nil
+	}
+
+	info, err := rtm.getRecord(rtm.tokenID(refreshToken))
+	if err != nil {
+		return nil, nil // Already gone | 已不存在
+	}
+	return rtm.revokeFamily(info), nil
+}
+
+// ListRefreshTokens Gets the latest refresh token of every family of an account, of all devices if device is empty |
+// 获取账号每个家族的最新刷新令牌，device为空时包括所有设备
+func (rtm *RefreshTokenManager) ListRefreshTokens(loginID, device string) ([]*RefreshTokenInfo, error) {
+	keys, err := rtm.storage.Keys(rtm.getFamilyPattern(loginID, device))
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*RefreshTokenInfo, 0, len(keys))
+	for _, key := range keys {
+		_, family, ok := rtm.readFamily(key)
+		if !ok {
+			continue
+		}
+		info, err := rtm.getRecord(family.Latest)
+		if err != nil || info.LoginID != loginID || (device != "" && info.Device != device) {
+			continue
+		}
+		list = append(list, info)
+	}
+	return list, nil
+}
+
+// RevokeRefreshTokens Revokes every family of an account, of all devices if device is empty, returns their latest tokens |
+// 撤销账号的所有家族，device为空时包括所有设备，返回它们的最新令牌
+func (rtm *RefreshTokenManager) RevokeRefreshTokens(loginID, device string) ([]*RefreshTokenInfo, error) {
+	list, err := rtm.ListRefreshTokens(loginID, device)
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range list {
+		rtm.revokeFamily(info)
 	}
-	key := rtm.getRefreshKey(refreshToken)
-	return rtm.storage.Delete(key)
+	return list, nil
 }
 
 // GetRefreshTokenInfo Gets refresh token information | 获取刷新令牌信息
@@ -170,32 +349,186 @@ func (rtm *RefreshTokenManager) GetRefreshTokenInfo(refreshToken string) (*Refre
 		return nil, ErrInvalidRefreshToken
 	}
 
-	key := rtm.getRefreshKey(refreshToken)
+	info, err := rtm.getRecord(rtm.tokenID(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+	info.RefreshToken = refreshToken
+	return info, nil
+}
 
-	data, err := rtm.storage.Get(key)
-	if err != nil || data == nil {
+// IsValid Checks if refresh token is valid and not spent | 检查刷新令牌是否有效且未被消费
+func (rtm *RefreshTokenManager) IsValid(refreshToken string) bool {
+	id := rtm.tokenID(refreshToken)
+	info, err := rtm.getRecord(id)
+	if err != nil {
+		return false
+	}
+	if info.ExpireTime > 0 && time.Now().Unix() > info.ExpireTime {
+		return false
+	}
+
+	_, family, ok := rtm.getFamily(info)
+	return ok && family.Latest == id
+}
+
+// getRecord Gets the stored record of a refresh token ID | 获取刷新令牌ID的存储记录
+func (rtm *RefreshTokenManager) getRecord(id string) (*RefreshTokenInfo, error) {
+	if id == "" {
 		return nil, ErrInvalidRefreshToken
 	}
 
-	info, ok := data.(*RefreshTokenInfo)
-	if !ok {
+	value, err := rtm.storage.Get(rtm.getRefreshKey(id))
+	if err != nil || value == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	var info RefreshTokenInfo
+	if !decodeJSON(value, &info) {
 		return nil, ErrInvalidRefreshData
 	}
 
-	return info, nil
+	return &info, nil
 }
 
-// IsValid Checks if refresh token is valid | 检查刷新令牌是否有效
-func (rtm *RefreshTokenManager) IsValid(refreshToken string) bool {
-	info, err := rtm.GetRefreshTokenInfo(refreshToken)
+// newRecord Stamps the record of a new refresh token, keyed by its token ID | 为新的刷新令牌生成记录，以其Token ID为键
+func (rtm *RefreshTokenManager) newRecord(refreshToken string, info *RefreshTokenInfo) *RefreshTokenInfo {
+	now := time.Now()
+	info.RefreshToken = rtm.tokenID(refreshToken)
+	info.CreateTime = now.Unix()
+	if rtm.refreshTTL > 0 {
+		info.ExpireTime = now.Add(rtm.refreshTTL).Unix()
+	}
+	return info
+}
+
+// reuse Revokes the family of a spent token and reports it | 撤销已消费令牌的家族并上报
+func (rtm *RefreshTokenManager) reuse(info *RefreshTokenInfo, reused func(latest *RefreshTokenInfo)) {
+	latest := rtm.revokeFamily(info)
+	if latest == nil {
+		latest = info
+	}
+	if reused != nil {
+		reused(latest)
+	}
+}
+
+// revokeFamily Deletes a family and its latest token, spent tokens die with the family |
+// 删除家族及其最新令牌，已消费的令牌随家族失效
+func (rtm *RefreshTokenManager) revokeFamily(info *RefreshTokenInfo) *RefreshTokenInfo {
+	_, family, ok := rtm.getFamily(info)
+	if !ok {
+		rtm.storage.Delete(rtm.getRefreshKey(info.RefreshToken))
+		return nil
+	}
+
+	latest, err := rtm.getRecord(family.Latest)
+	rtm.storage.Delete(rtm.getFamilyKey(info), rtm.getRefreshKey(info.RefreshToken), rtm.getRefreshKey(family.Latest))
 	if err != nil {
-		return false
+		return nil
 	}
+	return latest
+}
+
+// getFamily Gets the family of a refresh token | 获取刷新令牌所在的家族
+func (rtm *RefreshTokenManager) getFamily(info *RefreshTokenInfo) (string, *refreshFamily, bool) {
+	if info.FamilyID == "" {
+		return "", nil, false
+	}
+	return rtm.readFamily(rtm.getFamilyKey(info))
+}
+
+// readFamily Reads a family record, with its stored form for compareAndSwap | 读取家族记录及其存储形式（用于compareAndSwap）
+func (rtm *RefreshTokenManager) readFamily(key string) (string, *refreshFamily, bool) {
+	value, err := rtm.storage.Get(key)
+	if err != nil || value == nil {
+		return "", nil, false
+	}
+	var family refreshFamily
+	if !decodeJSON(value, &family) || family.Latest == "" {
+		return "", nil, false
+	}
+	raw, _ := value.(string)
+	if data, ok := value.([]byte); ok {
+		raw = string(data)
+	}
+	return raw, &family, true
+}
+
+// compareAndSwap Replaces a record only if it still holds oldValue | 仅当记录仍为oldValue时替换
+func (rtm *RefreshTokenManager) compareAndSwap(key, oldValue, newValue string) (bool, error) {
+	if atomic, ok := rtm.storage.(adapter.AtomicStorage); ok {
+		return atomic.CompareAndSwap(key, oldValue, newValue, rtm.refreshTTL)
+	}
+
+	// Atomic within this process only | 仅在本进程内原子
+	rtm.mu.Lock()
+	defer rtm.mu.Unlock()
+	value, err := rtm.storage.Get(key)
+	if err != nil || value == nil {
+		return false, nil
+	}
+	current, _ := value.(string)
+	if data, ok := value.([]byte); ok {
+		current = string(data)
+	}
+	if current != oldValue {
+		return false, nil
+	}
+	return true, rtm.storage.Set(key, newValue, rtm.refreshTTL)
+}
 
-	return time.Now().Unix() <= info.ExpireTime
+// save Stores a record as JSON until the token expires | 以JSON存储记录直到令牌过期
+func (rtm *RefreshTokenManager) save(key string, record any) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return rtm.storage.Set(key, string(data), rtm.refreshTTL)
+}
+
+// tokenID Gets the storage form of a refresh token | 获取刷新令牌的存储形式
+func (rtm *RefreshTokenManager) tokenID(refreshToken string) string {
+	return TokenID(rtm.pepper, refreshToken)
+}
+
+// getRefreshKey Gets storage key for a refresh token ID | 获取刷新令牌ID的存储键
+func (rtm *RefreshTokenManager) getRefreshKey(id string) string {
+	return rtm.keyPrefix + RefreshKeySuffix + id
+}
+
+// getFamilyKey Gets storage key for the family of a refresh token | 获取刷新令牌家族的存储键
+func (rtm *RefreshTokenManager) getFamilyKey(info *RefreshTokenInfo) string {
+	return rtm.keyPrefix + RefreshFamilyKeySuffix + info.LoginID + ":" + info.Device + ":" + info.FamilyID
 }
 
-// getRefreshKey Gets storage key for refresh token | 获取刷新令牌的存储键
-func (rtm *RefreshTokenManager) getRefreshKey(refreshToken string) string {
-	return rtm.keyPrefix + RefreshKeySuffix + refreshToken
+// getFamilyPattern Gets the key pattern of the families of an account | 获取账号家族的键模式
+func (rtm *RefreshTokenManager) getFamilyPattern(loginID, device string) string {
+	if device == "" {
+		return rtm.keyPrefix + RefreshFamilyKeySuffix + loginID + ":*"
+	}
+	return rtm.keyPrefix + RefreshFamilyKeySuffix + loginID + ":" + device + ":*"
+}
+
+// randomHex Generates n random bytes as hex | 生成n个随机字节的十六进制形式
+func randomHex(n int) (string, error) {
+	bytes := make([]byte, n)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// decodeJSON Decodes a JSON record read from storage | 解码从存储读取的JSON记录
+func decodeJSON(value any, v any) bool {
+	var data []byte
+	switch val := value.(type) {
+	case string:
+		data = []byte(val)
+	case []byte:
+		data = val
+	default:
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
 }
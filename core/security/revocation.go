@@ -0,0 +1,189 @@
+package security
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"suwei.sa_token/core/adapter"
+)
+
+// JWT Revocation List
+// JWT撤销列表
+//
+// Revoked token IDs (the jti claim) are stored until the token's exp, so the list never outgrows
+// the tokens still in circulation. Every instance mirrors the list in a local Bloom filter:
+// IsRevoked answers "not revoked" without I/O and only confirms filter hits in storage. The
+// filter is rebuilt from storage in the background once SyncInterval has passed, so a revocation
+// made on another instance takes effect there within SyncInterval; on this instance it is
+// immediate. Call Sync once at startup: until a sync succeeds every check reads storage, and
+// failed syncs are retried with a backoff rather than on every check.
+// 已撤销的Token ID（jti声明）保存至Token的exp，因此列表不会超过仍在流通的Token数量。每个实例在本地
+// 布隆过滤器中镜像该列表：IsRevoked 无需I/O即可回答"未撤销"，只有过滤器命中时才在存储中确认。过滤器
+// 在超过SyncInterval后于后台从存储重建，因此其他实例上的撤销在SyncInterval内生效，本实例上立即生效。
+// 启动时应调用一次Sync：同步成功前每次检查都读取存储，同步失败后按退避时间重试，而不是每次检查都重试。
+//
+// Usage | 用法:
+//   rl := security.NewRevocationList(storage, "satoken:", 10*time.Second, 100000)
+//   rl.Sync()
+//   rl.Revoke(jti, exp)
+//   rl.IsRevoked(jti)  // true
+
+// Constants for the revocation list | 撤销列表常量
+const (
+	RevocationKeySuffix         = "jwt:jti:"  // Key suffix after prefix | 前缀后的键后缀
+	RevocationFalsePositiveRate = 0.001       // Share of filter hits confirmed in storage in vain | 过滤器命中但存储确认未撤销的比例
+	RevocationRetryDelay        = time.Second // First retry after a failed sync, doubles up to the sync interval | 同步失败后的首次重试间隔，翻倍直至同步间隔
+)
+
+// Error variables | 错误变量
+var (
+	ErrEmptyJTI = fmt.Errorf("jti cannot be empty")
+)
+
+// RevocationList Storage-backed jti revocation list with a local Bloom filter | 带本地布隆过滤器、基于存储的jti撤销列表
+type RevocationList struct {
+	storage   adapter.Storage
+	keyPrefix string // Configurable prefix | 可配置的前缀
+	interval  time.Duration
+	capacity  int
+
+	mu       sync.RWMutex
+	filter   *bloomFilter
+	syncedAt time.Time
+	nextSync time.Time // When the next background sync is due | 下次后台同步的时间
+	failures int       // Failed syncs in a row | 连续同步失败次数
+	syncing  bool
+	pending  []string // Revoked while a sync is loading keys | 同步加载键期间撤销的jti
+
+	syncMu     sync.Mutex  // Serializes syncs | 串行化同步
+	refreshing atomic.Bool // Background sync in flight | 后台同步进行中
+}
+
+// NewRevocationList Creates a revocation list | 创建撤销列表
+// interval: how long the local filter is trusted before reloading | 本地过滤器重新加载前的可信时长
+// capacity: expected number of revoked tokens | 预期的已撤销Token数量
+func NewRevocationList(storage adapter.Storage, prefix string, interval time.Duration, capacity int) *RevocationList {
+	return &RevocationList{
+		storage:   storage,
+		keyPrefix: prefix,
+		interval:  interval,
+		capacity:  capacity,
+		filter:    newBloomFilter(capacity, RevocationFalsePositiveRate),
+	}
+}
+
+// Revoke Revokes a token ID until exp, zero exp keeps it forever | 撤销Token ID直至exp，exp为零值时永久保留
+func (rl *RevocationList) Revoke(jti string, exp time.Time) error {
+	if jti == "" {
+		return ErrEmptyJTI
+	}
+
+	var ttl time.Duration
+	var value int64
+	if !exp.IsZero() {
+		if ttl = time.Until(exp); ttl <= 0 {
+			return nil // Expired tokens are rejected already | 已过期的Token本就会被拒绝
+		}
+		value = exp.Unix()
+	}
+	if err := rl.storage.Set(rl.getKey(jti), value, ttl); err != nil {
+		return fmt.Errorf("failed to store revoked jti: %w", err)
+	}
+
+	rl.mu.Lock()
+	rl.filter.add(jti)
+	if rl.syncing {
+		rl.pending = append(rl.pending, jti)
+	}
+	rl.mu.Unlock()
+	return nil
+}
+
+// IsRevoked Checks if a token ID is revoked, only filter hits read storage | 检查Token ID是否已撤销，只有过滤器命中时才读取存储
+func (rl *RevocationList) IsRevoked(jti string) bool {
+	if jti == "" {
+		return false
+	}
+	if !rl.refresh() {
+		// The filter was never loaded, storage is the only source | 过滤器从未加载，存储是唯一来源
+		return rl.storage.Exists(rl.getKey(jti))
+	}
+
+	rl.mu.RLock()
+	hit := rl.filter.test(jti)
+	rl.mu.RUnlock()
+	return hit && rl.storage.Exists(rl.getKey(jti))
+}
+
+// Sync Rebuilds the local filter from storage, listing entries with Storage.Keys (SCAN on Redis) |
+// 从存储重建本地过滤器，通过Storage.Keys列出条目（Redis上使用SCAN）
+func (rl *RevocationList) Sync() error {
+	rl.syncMu.Lock()
+	defer rl.syncMu.Unlock()
+
+	rl.mu.Lock()
+	rl.syncing, rl.pending = true, nil
+	rl.mu.Unlock()
+
+	keys, err := rl.storage.Keys(rl.getKey("*"))
+	if err != nil {
+		rl.mu.Lock()
+		rl.syncing, rl.pending = false, nil
+		rl.failures++
+		rl.nextSync = time.Now().Add(rl.retryDelay())
+		rl.mu.Unlock()
+		return fmt.Errorf("failed to sync revocation list: %w", err)
+	}
+
+	// Leave room to grow until the next sync | 为下次同步前的增长预留空间
+	filter := newBloomFilter(max(rl.capacity, 2*len(keys)), RevocationFalsePositiveRate)
+	keyPrefix := rl.getKey("")
+	for _, key := range keys {
+		filter.add(strings.TrimPrefix(key, keyPrefix))
+	}
+
+	rl.mu.Lock()
+	for _, jti := range rl.pending {
+		filter.add(jti)
+	}
+	rl.filter, rl.syncedAt = filter, time.Now()
+	rl.nextSync, rl.failures = rl.syncedAt.Add(rl.interval), 0
+	rl.syncing, rl.pending = false, nil
+	rl.mu.Unlock()
+	return nil
+}
+
+// refresh Reloads the filter in the background once a sync is due, reports if it was ever loaded |
+// 到达同步时间时在后台重新加载过滤器，返回过滤器是否曾加载
+func (rl *RevocationList) refresh() bool {
+	rl.mu.RLock()
+	loaded := !rl.syncedAt.IsZero()
+	due := !time.Now().Before(rl.nextSync)
+	rl.mu.RUnlock()
+
+	if due && rl.refreshing.CompareAndSwap(false, true) {
+		go func() {
+			defer rl.refreshing.Store(false)
+			rl.Sync()
+		}()
+	}
+	return loaded
+}
+
+// retryDelay Gets the backoff after the current run of failed syncs, caller holds mu |
+// 获取当前连续同步失败后的退避时间，调用方需持有mu
+func (rl *RevocationList) retryDelay() time.Duration {
+	delay := RevocationRetryDelay
+	for i := 1; i < rl.failures && delay < rl.interval; i++ {
+		delay *= 2
+	}
+	return min(delay, rl.interval)
+}
+
+// getKey Gets storage key for a revoked jti | 获取已撤销jti的存储键
+func (rl *RevocationList) getKey(jti string) string {
+	return rl.keyPrefix + RevocationKeySuffix + jti
+}
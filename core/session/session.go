@@ -1,8 +1,11 @@
 package session
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
@@ -11,7 +14,8 @@ import (
 
 // Constants for session keys | Session键常量
 const (
-	SessionKeyPrefix = "session:" // Storage key prefix | 存储键前缀
+	SessionKeyPrefix        = "session:"         // Storage key prefix | 存储键前缀
+	SessionVersionKeyPrefix = "session-version:" // Storage key prefix of session versions | Session版本的存储键前缀
 )
 
 // Error variables | 错误变量
@@ -177,7 +181,26 @@ func (s *Session) save() error {
 	}
 
 	key := s.getStorageKey()
-	return s.storage.Set(key, string(data), 0)
+	if err := s.storage.Set(key, string(data), 0); err != nil {
+		return err
+	}
+	// Written after the data, a reader that sees a version also sees its data | 在数据之后写入，读到版本即可读到对应数据
+	return s.storage.Set(VersionKey(s.prefix, s.ID), newVersion(), 0)
+}
+
+// VersionKey Gets the storage key of a session's version, which changes on every save |
+// 获取Session版本的存储键，每次保存都会变化
+func VersionKey(prefix, id string) string {
+	return prefix + SessionVersionKeyPrefix + id
+}
+
+// newVersion Generates a random session version | 生成随机的Session版本
+func newVersion() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(buf)
 }
 
 // getStorageKey Gets storage key for this session | 获取Session的存储键
@@ -224,5 +247,8 @@ func (s *Session) Destroy() error {
 	defer s.mu.Unlock()
 
 	key := s.getStorageKey()
-	return s.storage.Delete(key)
+	if err := s.storage.Delete(key); err != nil {
+		return err
+	}
+	return s.storage.Delete(VersionKey(s.prefix, s.ID))
 }
@@ -0,0 +1,167 @@
+package token
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Charsets for random tokens | 随机Token字符集
+const (
+	URLSafeCharset      = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	AlphanumericCharset = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	NumericCharset      = "0123456789"
+	HexCharset          = "0123456789abcdef"
+)
+
+// ID generator constants | ID生成器常量
+const (
+	MaxCharsetSize   = 256                                // Largest charset sampled from one byte | 单字节可采样的最大字符集
+	ULIDLength       = 26                                 // Length of a ULID | ULID长度
+	ULIDCharset      = "0123456789ABCDEFGHJKMNPQRSTVWXYZ" // Crockford base32 | Crockford base32
+	SnowflakeEpoch   = int64(1704067200000)               // 2024-01-01 UTC in milliseconds | 2024-01-01 UTC（毫秒）
+	SnowflakeNodeMax = 1<<snowflakeNodeBits - 1           // Largest node ID | 最大节点ID
+
+	snowflakeNodeBits = 10
+	snowflakeSeqBits  = 12
+	snowflakeSeqMask  = 1<<snowflakeSeqBits - 1
+)
+
+// ============ Random | 随机 ============
+
+// RandomGenerator Random tokens of a given length and charset | 指定长度和字符集的随机Token
+type RandomGenerator struct {
+	length  int
+	charset string
+}
+
+// NewRandomGenerator Creates a random generator, defaults are DefaultSimpleLength and URLSafeCharset |
+// 创建随机生成器，默认长度为DefaultSimpleLength，默认字符集为URLSafeCharset
+func NewRandomGenerator(length int, charset string) *RandomGenerator {
+	if length <= 0 {
+		length = DefaultSimpleLength
+	}
+	if charset == "" {
+		charset = URLSafeCharset
+	}
+	return &RandomGenerator{length: length, charset: charset}
+}
+
+// Generate Generates a random token | 生成随机Token
+func (r *RandomGenerator) Generate(string, string) (string, error) {
+	return RandomString(r.length, r.charset)
+}
+
+// RandomString Generates a uniformly random string from charset | 从字符集中生成均匀分布的随机字符串
+func RandomString(length int, charset string) (string, error) {
+	size := len(charset)
+	if size < 2 || size > MaxCharsetSize {
+		return "", fmt.Errorf("%w: charset must have 2 to %d characters, got %d", ErrInvalidGenerator, MaxCharsetSize, size)
+	}
+
+	// Reject bytes past the last full multiple of size to avoid modulo bias | 拒绝超出size最大整数倍的字节以避免取模偏差
+	limit := MaxCharsetSize - MaxCharsetSize%size
+	result := make([]byte, 0, length)
+	buf := make([]byte, length+length/4+8)
+	for len(result) < length {
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("failed to generate random bytes: %w", err)
+		}
+		for _, b := range buf {
+			if int(b) >= limit {
+				continue
+			}
+			result = append(result, charset[int(b)%size])
+			if len(result) == length {
+				break
+			}
+		}
+	}
+	return string(result), nil
+}
+
+// ============ ULID | ULID ============
+
+// ULIDGenerator Lexicographically sortable IDs: 48-bit millisecond time plus 80 random bits |
+// 可按字典序排序的ID：48位毫秒时间加80位随机数
+type ULIDGenerator struct{}
+
+// NewULIDGenerator Creates a ULID generator | 创建ULID生成器
+func NewULIDGenerator() *ULIDGenerator {
+	return &ULIDGenerator{}
+}
+
+// Generate Generates a ULID | 生成ULID
+func (ULIDGenerator) Generate(string, string) (string, error) {
+	var id [16]byte
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(time.Now().UnixMilli()))
+	copy(id[:6], ms[2:])
+	if _, err := rand.Read(id[6:]); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+
+	// 128 bits in 26 base32 characters, most significant first | 128位编码为26个base32字符，高位在前
+	n := new(big.Int).SetBytes(id[:])
+	mask := big.NewInt(int64(len(ULIDCharset) - 1))
+	digit := new(big.Int)
+	result := make([]byte, ULIDLength)
+	for i := ULIDLength - 1; i >= 0; i-- {
+		result[i] = ULIDCharset[digit.And(n, mask).Int64()]
+		n.Rsh(n, 5)
+	}
+	return string(result), nil
+}
+
+// ============ Snowflake | 雪花ID ============
+
+// SnowflakeGenerator Time-ordered 64-bit IDs: 41-bit milliseconds since SnowflakeEpoch, 10-bit node,
+// 12-bit sequence. The IDs are predictable, use them only where token values need not be secret |
+// 按时间排序的64位ID：41位自SnowflakeEpoch起的毫秒数、10位节点、12位序列号。ID可被预测，仅适用于Token值无需保密的场景
+type SnowflakeGenerator struct {
+	mu   sync.Mutex
+	node int64
+	last int64
+	seq  int64
+}
+
+// NewSnowflakeGenerator Creates a snowflake generator for a node, unique per instance | 为节点创建雪花ID生成器，节点在实例间需唯一
+func NewSnowflakeGenerator(node int64) (*SnowflakeGenerator, error) {
+	if node < 0 || node > SnowflakeNodeMax {
+		return nil, fmt.Errorf("%w: snowflake node must be 0-%d, got %d", ErrInvalidGenerator, SnowflakeNodeMax, node)
+	}
+	return &SnowflakeGenerator{node: node}, nil
+}
+
+// NewRandomNodeSnowflakeGenerator Creates a snowflake generator with a random node | 创建随机节点的雪花ID生成器
+func NewRandomNodeSnowflakeGenerator() *SnowflakeGenerator {
+	node, err := rand.Int(rand.Reader, big.NewInt(SnowflakeNodeMax+1))
+	if err != nil {
+		return &SnowflakeGenerator{}
+	}
+	return &SnowflakeGenerator{node: node.Int64()}
+}
+
+// Generate Generates a snowflake ID | 生成雪花ID
+func (s *SnowflakeGenerator) Generate(string, string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UnixMilli() - SnowflakeEpoch
+	if now > s.last {
+		s.last, s.seq = now, 0
+	} else {
+		// Same millisecond or clock moved back: keep counting, borrowing the next millisecond on overflow |
+		// 同一毫秒或时钟回拨：继续计数，序列号溢出时借用下一毫秒
+		s.seq = (s.seq + 1) & snowflakeSeqMask
+		if s.seq == 0 {
+			s.last++
+		}
+	}
+	id := s.last<<(snowflakeNodeBits+snowflakeSeqBits) | s.node<<snowflakeSeqBits | s.seq
+	return strconv.FormatInt(id, 10), nil
+}
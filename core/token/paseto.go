@@ -0,0 +1,344 @@
+package token
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/chacha20"
+	"suwei.sa_token/core/config"
+	"suwei.sa_token/core/jwk"
+)
+
+// PASETO v4 Tokens
+// PASETO v4 Token
+//
+// PASETO fixes the algorithm per version and purpose, so there is no "alg" header to confuse.
+// The paseto-v4-public style signs claims with Ed25519 (EdDSA keys), paseto-v4-local encrypts
+// them with XChaCha20 and BLAKE2b (HS256 keys with a 32-byte secret). Both use the same claims
+// as JWT, keys come from Config.JwtKeySet and rotate the same way, the key ID travels in the
+// footer as {"kid":"..."}. iat and exp are RFC 3339 strings in the token as the spec requires,
+// and Unix seconds in the parsed claims like JWT.
+// PASETO 为每个版本和用途固定了算法，不存在可被混淆的 "alg" 头。paseto-v4-public 风格使用Ed25519
+// （EdDSA密钥）签名声明，paseto-v4-local 使用XChaCha20和BLAKE2b（32字节密钥的HS256密钥）加密声明。
+// 两者与JWT使用相同的声明，密钥来自 Config.JwtKeySet 且轮换方式相同，密钥ID以 {"kid":"..."} 的形式
+// 放在footer中。按照规范，iat和exp在Token中为RFC 3339字符串，解析后的声明中与JWT一样为Unix秒。
+//
+// Usage | 用法:
+//   key, _ := jwk.GenerateKey("2026-10", jwk.AlgEdDSA)
+//   ks := jwk.NewKeySet()
+//   ks.SetSigningKey(key)
+//   cfg.SetTokenStyle(config.TokenStylePasetoV4Public).SetJwtKeySet(ks).SetJwtMode(config.JwtModeStateless)
+
+// PASETO constants | PASETO常量
+const (
+	PasetoV4PublicHeader = "v4.public." // Header of v4.public tokens | v4.public Token的头部
+	PasetoV4LocalHeader  = "v4.local."  // Header of v4.local tokens | v4.local Token的头部
+	PasetoLocalKeySize   = 32           // Secret size of v4.local keys | v4.local密钥长度
+
+	pasetoNonceSize   = 32
+	pasetoMACSize     = 32
+	pasetoEncKeyInfo  = "paseto-encryption-key"
+	pasetoAuthKeyInfo = "paseto-auth-key-for-aead"
+)
+
+// ErrInvalidPasetoKey Key cannot be used for the PASETO purpose | 密钥不能用于该PASETO用途
+var ErrInvalidPasetoKey = fmt.Errorf("invalid paseto key")
+
+// pasetoFooter Footer carrying the key ID | 携带密钥ID的footer
+type pasetoFooter struct {
+	KeyID string `json:"kid"`
+}
+
+// signPaseto Signs or encrypts claims with the current key of JwtKeySet | 使用JwtKeySet的当前密钥签名或加密声明
+func (g *Generator) signPaseto(claims jwt.MapClaims) (string, error) {
+	if g.config.JwtKeySet == nil {
+		return "", ErrMissingJWTKey
+	}
+	key, err := g.config.JwtKeySet.SigningKey()
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(toPasetoClaims(claims))
+	if err != nil {
+		return "", err
+	}
+	footer, err := json.Marshal(pasetoFooter{KeyID: key.ID})
+	if err != nil {
+		return "", err
+	}
+
+	if g.config.TokenStyle == config.TokenStylePasetoV4Local {
+		secret, err := pasetoLocalKey(key)
+		if err != nil {
+			return "", err
+		}
+		return encryptPasetoV4(secret, payload, footer)
+	}
+	private, ok := key.SignKey().(ed25519.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("%w: v4.public needs an EdDSA key, got %s key %s", ErrInvalidPasetoKey, key.Algorithm, key.ID)
+	}
+	return signPasetoV4(private, payload, footer), nil
+}
+
+// parsePaseto Verifies or decrypts a PASETO token and returns JWT-style claims | 校验或解密PASETO Token并返回JWT形式的声明
+func (g *Generator) parsePaseto(tokenStr string) (jwt.MapClaims, error) {
+	header := PasetoV4PublicHeader
+	if g.config.TokenStyle == config.TokenStylePasetoV4Local {
+		header = PasetoV4LocalHeader
+	}
+	if !strings.HasPrefix(tokenStr, header) {
+		return nil, fmt.Errorf("%w: not a %stoken", ErrInvalidToken, header)
+	}
+	body, footer, err := splitPaseto(tokenStr[len(header):])
+	if err != nil {
+		return nil, err
+	}
+
+	// Footer is authenticated below, it only picks the key here | footer会在下面被认证，这里只用于选择密钥
+	var f pasetoFooter
+	if err := json.Unmarshal(footer, &f); err != nil || f.KeyID == "" {
+		return nil, fmt.Errorf("%w: missing kid footer", ErrInvalidToken)
+	}
+	if g.config.JwtKeySet == nil {
+		return nil, fmt.Errorf("%w: %s", jwk.ErrKeyNotFound, f.KeyID)
+	}
+	key, found := g.config.JwtKeySet.Lookup(f.KeyID)
+	if !found {
+		return nil, fmt.Errorf("%w: %s", jwk.ErrKeyNotFound, f.KeyID)
+	}
+
+	var payload []byte
+	if header == PasetoV4LocalHeader {
+		secret, err := pasetoLocalKey(key)
+		if err != nil {
+			return nil, err
+		}
+		payload, err = decryptPasetoV4(secret, body, footer)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		public, ok := key.VerifyKey().(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: v4.public needs an EdDSA key, got %s key %s", ErrInvalidPasetoKey, key.Algorithm, key.ID)
+		}
+		payload, err = verifyPasetoV4(public, body, footer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidToken)
+	}
+	claims, err := fromPasetoClaims(raw)
+	if err != nil {
+		return nil, err
+	}
+	if exp, ok := claims["exp"].(float64); ok && !time.Now().Before(time.Unix(int64(exp), 0)) {
+		return nil, jwt.ErrTokenExpired
+	}
+	return claims, nil
+}
+
+// pasetoLocalKey Gets the 32-byte secret of a v4.local key | 获取v4.local密钥的32字节密钥
+func pasetoLocalKey(key *jwk.Key) ([]byte, error) {
+	secret, ok := key.SignKey().([]byte)
+	if !ok || len(secret) != PasetoLocalKeySize {
+		return nil, fmt.Errorf("%w: v4.local needs an HS256 key with a %d-byte secret: %s", ErrInvalidPasetoKey, PasetoLocalKeySize, key.ID)
+	}
+	return secret, nil
+}
+
+// ============ v4 Protocol | v4协议 ============
+
+// signPasetoV4 Builds a v4.public token | 构建v4.public Token
+func signPasetoV4(private ed25519.PrivateKey, payload, footer []byte) string {
+	sig := ed25519.Sign(private, pae([]byte(PasetoV4PublicHeader), payload, footer, nil))
+	return joinPaseto(PasetoV4PublicHeader, append(payload, sig...), footer)
+}
+
+// verifyPasetoV4 Verifies a v4.public body and returns the payload | 校验v4.public主体并返回载荷
+func verifyPasetoV4(public ed25519.PublicKey, body, footer []byte) ([]byte, error) {
+	if len(body) < ed25519.SignatureSize {
+		return nil, ErrInvalidToken
+	}
+	payload, sig := body[:len(body)-ed25519.SignatureSize], body[len(body)-ed25519.SignatureSize:]
+	if !ed25519.Verify(public, pae([]byte(PasetoV4PublicHeader), payload, footer, nil), sig) {
+		return nil, fmt.Errorf("%w: signature mismatch", ErrInvalidToken)
+	}
+	return payload, nil
+}
+
+// encryptPasetoV4 Builds a v4.local token | 构建v4.local Token
+func encryptPasetoV4(secret, payload, footer []byte) (string, error) {
+	nonce := make([]byte, pasetoNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return sealPasetoV4(secret, nonce, payload, footer)
+}
+
+// sealPasetoV4 Builds a v4.local token with the given nonce | 使用给定nonce构建v4.local Token
+func sealPasetoV4(secret, nonce, payload, footer []byte) (string, error) {
+	encKey, counterNonce, authKey, err := pasetoLocalKeys(secret, nonce)
+	if err != nil {
+		return "", err
+	}
+	stream, err := chacha20.NewUnauthenticatedCipher(encKey, counterNonce)
+	if err != nil {
+		return "", err
+	}
+	ciphertext := make([]byte, len(payload))
+	stream.XORKeyStream(ciphertext, payload)
+
+	tag, err := pasetoMAC(authKey, nonce, ciphertext, footer)
+	if err != nil {
+		return "", err
+	}
+	body := append(append(nonce, ciphertext...), tag...)
+	return joinPaseto(PasetoV4LocalHeader, body, footer), nil
+}
+
+// decryptPasetoV4 Authenticates and decrypts a v4.local body | 认证并解密v4.local主体
+func decryptPasetoV4(secret, body, footer []byte) ([]byte, error) {
+	if len(body) < pasetoNonceSize+pasetoMACSize {
+		return nil, ErrInvalidToken
+	}
+	nonce := body[:pasetoNonceSize]
+	ciphertext := body[pasetoNonceSize : len(body)-pasetoMACSize]
+	encKey, counterNonce, authKey, err := pasetoLocalKeys(secret, nonce)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := pasetoMAC(authKey, nonce, ciphertext, footer)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(tag, body[len(body)-pasetoMACSize:]) {
+		return nil, fmt.Errorf("%w: authentication failed", ErrInvalidToken)
+	}
+
+	stream, err := chacha20.NewUnauthenticatedCipher(encKey, counterNonce)
+	if err != nil {
+		return nil, err
+	}
+	payload := make([]byte, len(ciphertext))
+	stream.XORKeyStream(payload, ciphertext)
+	return payload, nil
+}
+
+// pasetoLocalKeys Derives the encryption key, XChaCha20 nonce and auth key from the nonce |
+// 根据nonce派生加密密钥、XChaCha20 nonce和认证密钥
+func pasetoLocalKeys(secret, nonce []byte) (encKey, counterNonce, authKey []byte, err error) {
+	tmp, err := blake2bSum(secret, chacha20.KeySize+chacha20.NonceSizeX, []byte(pasetoEncKeyInfo), nonce)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	authKey, err = blake2bSum(secret, pasetoMACSize, []byte(pasetoAuthKeyInfo), nonce)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return tmp[:chacha20.KeySize], tmp[chacha20.KeySize:], authKey, nil
+}
+
+// pasetoMAC Computes the v4.local tag | 计算v4.local标签
+func pasetoMAC(authKey, nonce, ciphertext, footer []byte) ([]byte, error) {
+	return blake2bSum(authKey, pasetoMACSize, pae([]byte(PasetoV4LocalHeader), nonce, ciphertext, footer, nil))
+}
+
+// blake2bSum Keyed BLAKE2b of the concatenated parts | 对拼接后的各部分计算带密钥的BLAKE2b
+func blake2bSum(key []byte, size int, parts ...[]byte) ([]byte, error) {
+	h, err := blake2b.New(size, key)
+	if err != nil {
+		return nil, err
+	}
+	for _, part := range parts {
+		h.Write(part)
+	}
+	return h.Sum(nil), nil
+}
+
+// pae Pre-authentication encoding: piece count and lengths as little-endian uint64 |
+// 预认证编码：片段数量和长度为小端uint64
+func pae(pieces ...[]byte) []byte {
+	var buf bytes.Buffer
+	var n [8]byte
+	binary.LittleEndian.PutUint64(n[:], uint64(len(pieces)))
+	buf.Write(n[:])
+	for _, piece := range pieces {
+		binary.LittleEndian.PutUint64(n[:], uint64(len(piece))&(1<<63-1))
+		buf.Write(n[:])
+		buf.Write(piece)
+	}
+	return buf.Bytes()
+}
+
+// joinPaseto Assembles header, body and optional footer | 拼接头部、主体和可选的footer
+func joinPaseto(header string, body, footer []byte) string {
+	token := header + base64.RawURLEncoding.EncodeToString(body)
+	if len(footer) > 0 {
+		token += "." + base64.RawURLEncoding.EncodeToString(footer)
+	}
+	return token
+}
+
+// splitPaseto Decodes the body and footer after the header | 解码头部之后的主体和footer
+func splitPaseto(rest string) (body, footer []byte, err error) {
+	encodedBody, encodedFooter, _ := strings.Cut(rest, ".")
+	if body, err = base64.RawURLEncoding.DecodeString(encodedBody); err != nil {
+		return nil, nil, fmt.Errorf("%w: malformed body", ErrInvalidToken)
+	}
+	if footer, err = base64.RawURLEncoding.DecodeString(encodedFooter); err != nil {
+		return nil, nil, fmt.Errorf("%w: malformed footer", ErrInvalidToken)
+	}
+	return body, footer, nil
+}
+
+// ============ Claims | 声明 ============
+
+// pasetoTimeClaims Registered claims holding RFC 3339 times | 保存RFC 3339时间的注册声明
+var pasetoTimeClaims = []string{"iat", "exp", "nbf"}
+
+// toPasetoClaims Converts Unix-second time claims to RFC 3339 | 将Unix秒时间声明转换为RFC 3339
+func toPasetoClaims(claims jwt.MapClaims) map[string]any {
+	out := make(map[string]any, len(claims))
+	for key, value := range claims {
+		out[key] = value
+	}
+	for _, name := range pasetoTimeClaims {
+		if sec, ok := out[name].(int64); ok {
+			out[name] = time.Unix(sec, 0).UTC().Format(time.RFC3339)
+		}
+	}
+	return out
+}
+
+// fromPasetoClaims Converts RFC 3339 time claims back to Unix seconds | 将RFC 3339时间声明转换回Unix秒
+func fromPasetoClaims(raw map[string]any) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims(raw)
+	for _, name := range pasetoTimeClaims {
+		value, ok := claims[name].(string)
+		if !ok {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: malformed %s claim", ErrInvalidToken, name)
+		}
+		claims[name] = float64(t.Unix())
+	}
+	return claims, nil
+}
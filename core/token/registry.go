@@ -0,0 +1,90 @@
+package token
+
+import (
+	"fmt"
+	"sync"
+
+	"suwei.sa_token/core/config"
+)
+
+// Token Generator Registry
+// Token生成器注册表
+//
+// Every TokenStyle except jwt and paseto maps to a TokenGenerator. Built-in styles are registered at start
+// up; RegisterGenerator adds a style (KSUID, an in-house format...) or replaces a built-in one,
+// and makes Config.Validate accept its name. jwt and paseto need the configured keys and are
+// handled by Generator itself.
+// 除jwt和paseto外，每种TokenStyle都对应一个TokenGenerator。内置风格在启动时注册；RegisterGenerator 可以添加
+// 新风格（KSUID、自定义格式等）或替换内置风格，并使 Config.Validate 接受该名称。jwt和paseto依赖配置的
+// 密钥，由 Generator 自行处理。
+//
+// Usage | 用法:
+//   token.RegisterGenerator("pin", token.NewRandomGenerator(6, token.NumericCharset))
+//   cfg.SetTokenStyle("pin")
+
+// Error variables | 错误变量
+var (
+	ErrInvalidGenerator  = fmt.Errorf("invalid token generator")
+	ErrUnknownTokenStyle = fmt.Errorf("unknown token style")
+)
+
+// TokenGenerator Generates token values of one style | 生成某种风格的Token值
+type TokenGenerator interface {
+	Generate(loginID string, device string) (string, error)
+}
+
+// TokenGeneratorFunc Adapts a function to TokenGenerator | 将函数适配为TokenGenerator
+type TokenGeneratorFunc func(loginID string, device string) (string, error)
+
+// Generate Calls f | 调用f
+func (f TokenGeneratorFunc) Generate(loginID string, device string) (string, error) {
+	return f(loginID, device)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[config.TokenStyle]TokenGenerator)
+)
+
+func init() {
+	builtins := map[config.TokenStyle]TokenGenerator{
+		config.TokenStyleUUID:      TokenGeneratorFunc(generateUUID),
+		config.TokenStyleSimple:    NewRandomGenerator(DefaultSimpleLength, URLSafeCharset),
+		config.TokenStyleRandom32:  NewRandomGenerator(32, URLSafeCharset),
+		config.TokenStyleRandom64:  NewRandomGenerator(64, URLSafeCharset),
+		config.TokenStyleRandom128: NewRandomGenerator(128, URLSafeCharset),
+		config.TokenStyleHash:      TokenGeneratorFunc(generateHash),
+		config.TokenStyleTimestamp: TokenGeneratorFunc(generateTimestamp),
+		config.TokenStyleTik:       NewRandomGenerator(TikTokenLength, TikCharset),
+		config.TokenStyleULID:      NewULIDGenerator(),
+		config.TokenStyleSnowflake: NewRandomNodeSnowflakeGenerator(),
+	}
+	for style, gen := range builtins {
+		registry[style] = gen
+	}
+}
+
+// RegisterGenerator Registers the generator of a style, replacing any previous one | 注册某种风格的生成器，替换已有的生成器
+func RegisterGenerator(style config.TokenStyle, gen TokenGenerator) error {
+	if style == "" || gen == nil {
+		return fmt.Errorf("%w: style and generator are required", ErrInvalidGenerator)
+	}
+	if style.IsClaims() {
+		return fmt.Errorf("%w: %s is signed with the configured keys", ErrInvalidGenerator, style)
+	}
+
+	registryMu.Lock()
+	registry[style] = gen
+	registryMu.Unlock()
+
+	config.RegisterTokenStyle(style)
+	return nil
+}
+
+// GetGenerator Gets the generator of a style | 获取某种风格的生成器
+func GetGenerator(style config.TokenStyle) (TokenGenerator, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	gen, ok := registry[style]
+	return gen, ok
+}
@@ -0,0 +1,85 @@
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"suwei.sa_token/core/jwk"
+)
+
+// Signed Opaque Tokens
+// 自校验的不透明Token
+//
+// With Config.TokenSignKeySet set, every non-JWT token is issued as "<random>.<tag>", the tag
+// being a truncated HMAC-SHA256 of the random part. Manager checks the tag before touching
+// storage, so garbage or forged tokens cost no storage round trip. The token is still opaque
+// and storage stays authoritative for login state. Keys rotate like JWT keys: tags made with
+// the previous key verify until its grace period ends.
+// 设置Config.TokenSignKeySet后，所有非JWT Token都以 "<随机值>.<标签>" 的形式签发，标签是随机部分
+// 截断后的HMAC-SHA256。Manager 在访问存储前校验标签，因此无效或伪造的Token不会产生存储请求。
+// Token依然是不透明的，登录状态仍以存储为准。密钥轮换方式与JWT密钥相同：旧密钥生成的标签在宽限期
+// 结束前仍可通过校验。
+//
+// Usage | 用法:
+//   key, _ := jwk.NewHMACKey("2026-10", secret)
+//   ks := jwk.NewKeySet()
+//   ks.SetSigningKey(key)
+//   cfg.SetTokenSignKeySet(ks)
+
+// Signed token constants | 签名Token常量
+const (
+	SignedTagSeparator = "." // Separates the random part from the tag | 分隔随机部分与标签
+	SignedTagBytes     = 16  // HMAC bytes kept in the tag, 128 bits | 标签中保留的HMAC字节数（128位）
+)
+
+// ErrUnsignedToken Token has no valid tag | Token没有有效的标签
+var ErrUnsignedToken = fmt.Errorf("token signature is missing or invalid")
+
+// Sign Appends the tag of the current signing key, value is returned as is when signing is off |
+// 追加当前签名密钥的标签，未开启签名时原样返回
+func (g *Generator) Sign(value string) (string, error) {
+	ks := g.config.TokenSignKeySet
+	if ks == nil {
+		return value, nil
+	}
+	key, err := ks.SigningKey()
+	if err != nil {
+		return "", err
+	}
+	secret, ok := key.SignKey().([]byte)
+	if !ok {
+		return "", fmt.Errorf("%w: %s key %s cannot tag tokens", jwk.ErrUnsupportedKey, key.Algorithm, key.ID)
+	}
+	return value + SignedTagSeparator + tokenTag(secret, value), nil
+}
+
+// VerifySignature Checks the tag of an opaque token against every valid key, true when signing is off |
+// 使用所有有效密钥校验不透明Token的标签，未开启签名时返回true
+func (g *Generator) VerifySignature(tokenValue string) bool {
+	ks := g.config.TokenSignKeySet
+	if ks == nil {
+		return true
+	}
+	i := strings.LastIndex(tokenValue, SignedTagSeparator)
+	if i <= 0 {
+		return false
+	}
+	value, tag := tokenValue[:i], tokenValue[i+1:]
+	for _, key := range ks.Keys() {
+		secret, ok := key.VerifyKey().([]byte)
+		if ok && hmac.Equal([]byte(tag), []byte(tokenTag(secret, value))) {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenTag Computes the truncated HMAC-SHA256 tag of value | 计算value截断后的HMAC-SHA256标签
+func tokenTag(secret []byte, value string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:SignedTagBytes])
+}
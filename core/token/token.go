@@ -5,34 +5,51 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
-	"math/big"
 	"time"
 
 	"suwei.sa_token/core/config"
-	"suwei.sa_token/core/utils"
+	"suwei.sa_token/core/jwk"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
 // Constants for token generation | Token生成常量
 const (
-	DefaultJWTSecret    = "default-secret-key" // Should be overridden in production | 生产环境应覆盖
-	TikTokenLength      = 11                   // TikTok-style short ID length | Tik风格短ID长度
+	TikTokenLength      = 11 // TikTok-style short ID length | Tik风格短ID长度
 	TikCharset          = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
 	HashRandomBytesLen  = 16 // Random bytes length for hash token | 哈希Token的随机字节长度
 	TimestampRandomLen  = 8  // Random bytes length for timestamp token | 时间戳Token的随机字节长度
 	DefaultSimpleLength = 16 // Default simple token length | 默认简单Token长度
 )
 
+// JWT claim names | JWT声明名称
+const (
+	ClaimLoginID     = "loginId"
+	ClaimDevice      = "device"
+	ClaimDeviceID    = "deviceId"
+	ClaimLoginTime   = "loginTime" // Login time in microseconds, exact in JSON numbers | 登录时间（微秒），在JSON数值中可精确表示
+	ClaimPermissions = "permissions"
+	ClaimRoles       = "roles"
+	ClaimJTI         = "jti" // Unique token ID, the key of the revocation list | 唯一Token ID，撤销列表的键
+)
+
 // Error variables | 错误变量
 var (
 	ErrInvalidToken            = fmt.Errorf("invalid token")
 	ErrUnexpectedSigningMethod = fmt.Errorf("unexpected signing method")
+	ErrMissingJWTKey           = fmt.Errorf("JwtSecretKey or JwtKeySet is required for JWT tokens")
+	ErrTokenRevoked            = fmt.Errorf("token has been revoked")
 )
 
+// RevocationChecker Reports whether a token ID (jti) has been revoked | 报告Token ID（jti）是否已被撤销
+type RevocationChecker interface {
+	IsRevoked(jti string) bool
+}
+
 // Generator Token generator | Token生成器
 type Generator struct {
-	config *config.Config
+	config      *config.Config
+	revocations RevocationChecker
 }
 
 // NewGenerator Creates a new token generator | 创建新的Token生成器
@@ -45,6 +62,11 @@ func NewGenerator(cfg *config.Config) *Generator {
 	}
 }
 
+// SetRevocationChecker Sets the revocation list ParseJWT consults, nil disables | 设置ParseJWT查询的撤销列表，为nil时不启用
+func (g *Generator) SetRevocationChecker(checker RevocationChecker) {
+	g.revocations = checker
+}
+
 // ============ Public Methods | 公共方法 ============
 
 // Generate Generates token based on configured style | 根据配置的风格生成Token
@@ -53,34 +75,29 @@ func (g *Generator) Generate(loginID string, device string) (string, error) {
 		return "", fmt.Errorf("loginID cannot be empty")
 	}
 
-	switch g.config.TokenStyle {
-	case config.TokenStyleUUID:
-		return g.generateUUID()
-	case config.TokenStyleSimple:
-		return g.generateSimple(DefaultSimpleLength)
-	case config.TokenStyleRandom32:
-		return g.generateSimple(32)
-	case config.TokenStyleRandom64:
-		return g.generateSimple(64)
-	case config.TokenStyleRandom128:
-		return g.generateSimple(128)
-	case config.TokenStyleJWT:
+	style := g.config.TokenStyle
+	if style == "" {
+		style = config.TokenStyleUUID
+	}
+	if style.IsClaims() {
 		return g.generateJWT(loginID, device)
-	case config.TokenStyleHash:
-		return g.generateHash(loginID, device)
-	case config.TokenStyleTimestamp:
-		return g.generateTimestamp(loginID, device)
-	case config.TokenStyleTik:
-		return g.generateTik()
-	default:
-		return g.generateUUID()
 	}
+
+	gen, ok := GetGenerator(style)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownTokenStyle, style)
+	}
+	value, err := gen.Generate(loginID, device)
+	if err != nil {
+		return "", err
+	}
+	return g.Sign(value)
 }
 
 // ============ Token Generation Methods | Token生成方法 ============
 
 // generateUUID Generates UUID token | 生成UUID Token
-func (g *Generator) generateUUID() (string, error) {
+func generateUUID(string, string) (string, error) {
 	u, err := uuid.NewRandom()
 	if err != nil {
 		return "", fmt.Errorf("failed to generate UUID: %w", err)
@@ -88,37 +105,48 @@ func (g *Generator) generateUUID() (string, error) {
 	return u.String(), nil
 }
 
-// generateSimple Generates simple random string token | 生成简单随机字符串Token
-func (g *Generator) generateSimple(length int) (string, error) {
-	if length <= 0 {
-		length = DefaultSimpleLength
-	}
+// generateJWT Generates JWT token | 生成JWT Token
+func (g *Generator) generateJWT(loginID string, device string) (string, error) {
+	return g.GenerateJWT(loginID, device, nil)
+}
 
-	token := utils.RandomString(length)
-	if token == "" {
-		return "", fmt.Errorf("failed to generate random string")
-	}
-	return token, nil
+// GenerateJWT Generates a JWT carrying extra claims, which cannot override the built-in ones |
+// 生成携带额外声明的JWT，额外声明不能覆盖内置声明
+func (g *Generator) GenerateJWT(loginID string, device string, extra map[string]any) (string, error) {
+	return g.GenerateJWTWithTimeout(loginID, device, g.config.Timeout, extra)
 }
 
-// generateJWT Generates JWT token | 生成JWT Token
-func (g *Generator) generateJWT(loginID string, device string) (string, error) {
+// GenerateJWTWithTimeout Generates a JWT expiring after timeout seconds, no exp claim when timeout <= 0 |
+// 生成在timeout秒后过期的JWT，timeout <= 0时不设置exp声明
+func (g *Generator) GenerateJWTWithTimeout(loginID string, device string, timeout int64, extra map[string]any) (string, error) {
+	if loginID == "" {
+		return "", fmt.Errorf("loginID cannot be empty")
+	}
+
 	now := time.Now()
-	claims := jwt.MapClaims{
-		"loginId": loginID,
-		"device":  device,
-		"iat":     now.Unix(),
+	claims := jwt.MapClaims{}
+	for key, value := range extra {
+		claims[key] = value
 	}
+	claims[ClaimLoginID] = loginID
+	claims[ClaimDevice] = device
+	claims[ClaimLoginTime] = now.UnixMicro()
+	claims[ClaimJTI] = uuid.New().String()
+	claims["iat"] = now.Unix()
 
 	// Add expiration if timeout is configured | 如果配置了超时时间则添加过期时间
-	if g.config.Timeout > 0 {
-		claims["exp"] = now.Add(time.Duration(g.config.Timeout) * time.Second).Unix()
+	if timeout > 0 {
+		claims["exp"] = now.Add(time.Duration(timeout) * time.Second).Unix()
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	secretKey := g.getJWTSecret()
-
-	signedToken, err := token.SignedString([]byte(secretKey))
+	if g.config.TokenStyle.IsPaseto() {
+		signedToken, err := g.signPaseto(claims)
+		if err != nil {
+			return "", fmt.Errorf("failed to sign PASETO token: %w", err)
+		}
+		return signedToken, nil
+	}
+	signedToken, err := g.signJWT(claims)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign JWT token: %w", err)
 	}
@@ -126,43 +154,99 @@ func (g *Generator) generateJWT(loginID string, device string) (string, error) {
 	return signedToken, nil
 }
 
-// getJWTSecret Gets JWT secret key with fallback | 获取JWT密钥（带默认值）
-func (g *Generator) getJWTSecret() string {
-	if g.config.JwtSecretKey != "" {
-		return g.config.JwtSecretKey
+// signJWT Signs claims with the current key of JwtKeySet, or HS256 with JwtSecretKey |
+// 使用JwtKeySet的当前密钥签名，未配置时使用JwtSecretKey进行HS256签名
+func (g *Generator) signJWT(claims jwt.MapClaims) (string, error) {
+	if ks := g.config.JwtKeySet; ks != nil {
+		key, err := ks.SigningKey()
+		if err != nil {
+			return "", err
+		}
+		method := jwt.GetSigningMethod(key.Algorithm)
+		if method == nil {
+			return "", fmt.Errorf("%w: %s", jwk.ErrUnsupportedAlgorithm, key.Algorithm)
+		}
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = key.ID
+		return token.SignedString(key.SignKey())
+	}
+
+	if g.config.JwtSecretKey == "" {
+		return "", ErrMissingJWTKey
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(g.config.JwtSecretKey))
+}
+
+// verifyKey Picks the verification key by the kid header; tokens without kid are HS256 with JwtSecretKey |
+// 根据kid头选择验证密钥；没有kid的Token使用JwtSecretKey进行HS256验证
+func (g *Generator) verifyKey(token *jwt.Token) (any, error) {
+	if kid, ok := token.Header["kid"].(string); ok && kid != "" {
+		if g.config.JwtKeySet == nil {
+			return nil, fmt.Errorf("%w: %s", jwk.ErrKeyNotFound, kid)
+		}
+		key, found := g.config.JwtKeySet.Lookup(kid)
+		if !found {
+			return nil, fmt.Errorf("%w: %s", jwk.ErrKeyNotFound, kid)
+		}
+		// The alg header must match the key, never trust it alone | alg头必须与密钥匹配，不能单独信任它
+		if token.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("%w: %v", ErrUnexpectedSigningMethod, token.Header["alg"])
+		}
+		if key.Algorithm == jwk.AlgHS256 {
+			return key.SignKey(), nil
+		}
+		return key.VerifyKey(), nil
+	}
+
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok || token.Method.Alg() != jwk.AlgHS256 {
+		return nil, fmt.Errorf("%w: %v", ErrUnexpectedSigningMethod, token.Header["alg"])
 	}
-	return DefaultJWTSecret
+	if g.config.JwtSecretKey == "" {
+		return nil, ErrMissingJWTKey
+	}
+	return []byte(g.config.JwtSecretKey), nil
 }
 
 // ============ JWT Helper Methods | JWT辅助方法 ============
 
-// ParseJWT Parses JWT token and returns claims | 解析JWT Token并返回声明
+// ParseJWT Parses JWT token and returns claims, PASETO tokens when TokenStyle is paseto |
+// 解析JWT Token并返回声明，TokenStyle为paseto时解析PASETO Token
 func (g *Generator) ParseJWT(tokenStr string) (jwt.MapClaims, error) {
 	if tokenStr == "" {
 		return nil, fmt.Errorf("token string cannot be empty")
 	}
-
-	secretKey := g.getJWTSecret()
-
-	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (any, error) {
-		// Verify signing method | 验证签名方法
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("%w: %v", ErrUnexpectedSigningMethod, token.Header["alg"])
+	if g.config.TokenStyle.IsPaseto() {
+		claims, err := g.parsePaseto(tokenStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse PASETO: %w", err)
 		}
-		return []byte(secretKey), nil
-	})
+		return g.checkRevoked(claims)
+	}
+
+	token, err := jwt.Parse(tokenStr, g.verifyKey)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse JWT: %w", err)
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		return claims, nil
+		return g.checkRevoked(claims)
 	}
 
 	return nil, ErrInvalidToken
 }
 
+// checkRevoked Rejects verified claims whose jti is on the revocation list | 拒绝jti在撤销列表中的已验证声明
+func (g *Generator) checkRevoked(claims jwt.MapClaims) (jwt.MapClaims, error) {
+	if g.revocations == nil {
+		return claims, nil
+	}
+	if jti, _ := claims[ClaimJTI].(string); jti != "" && g.revocations.IsRevoked(jti) {
+		return nil, ErrTokenRevoked
+	}
+	return claims, nil
+}
+
 // ValidateJWT Validates JWT token | 验证JWT Token
 func (g *Generator) ValidateJWT(tokenStr string) error {
 	_, err := g.ParseJWT(tokenStr)
@@ -176,7 +260,7 @@ func (g *Generator) GetLoginIDFromJWT(tokenStr string) (string, error) {
 		return "", err
 	}
 
-	loginID, ok := claims["loginId"].(string)
+	loginID, ok := claims[ClaimLoginID].(string)
 	if !ok {
 		return "", fmt.Errorf("loginId not found in token claims")
 	}
@@ -185,7 +269,7 @@ func (g *Generator) GetLoginIDFromJWT(tokenStr string) (string, error) {
 }
 
 // generateHash Generates SHA256 hash-based token | 生成SHA256哈希风格Token
-func (g *Generator) generateHash(loginID string, device string) (string, error) {
+func generateHash(loginID string, device string) (string, error) {
 	// Combine loginID, device, timestamp and random bytes | 组合 loginID、device、时间戳和随机字节
 	randomBytes := make([]byte, HashRandomBytesLen)
 	if _, err := rand.Read(randomBytes); err != nil {
@@ -204,7 +288,7 @@ func (g *Generator) generateHash(loginID string, device string) (string, error)
 }
 
 // generateTimestamp Generates timestamp-based token | 生成时间戳风格Token
-func (g *Generator) generateTimestamp(loginID string, device string) (string, error) {
+func generateTimestamp(loginID string, device string) (string, error) {
 	// Format: timestamp_loginID_random | 格式：时间戳_loginID_随机数
 	randomBytes := make([]byte, TimestampRandomLen)
 	if _, err := rand.Read(randomBytes); err != nil {
@@ -215,19 +299,3 @@ func (g *Generator) generateTimestamp(loginID string, device string) (string, er
 	random := hex.EncodeToString(randomBytes)
 	return fmt.Sprintf("%d_%s_%s", timestamp, loginID, random), nil
 }
-
-// generateTik Generates short ID style token (like TikTok) | 生成Tik风格短ID Token（类似抖音）
-func (g *Generator) generateTik() (string, error) {
-	result := make([]byte, TikTokenLength)
-	charsetLen := int64(len(TikCharset))
-
-	for i := range result {
-		num, err := rand.Int(rand.Reader, big.NewInt(charsetLen))
-		if err != nil {
-			return "", fmt.Errorf("failed to generate random number: %w", err)
-		}
-		result[i] = TikCharset[num.Int64()]
-	}
-
-	return string(result), nil
-}
@@ -1,9 +1,19 @@
 package token
 
 import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"suwei.sa_token/core/config"
+	"suwei.sa_token/core/jwk"
 )
 
 func TestGenerateHash(t *testing.T) {
@@ -93,6 +103,8 @@ func TestAllTokenStyles(t *testing.T) {
 		config.TokenStyleHash,
 		config.TokenStyleTimestamp,
 		config.TokenStyleTik,
+		config.TokenStyleULID,
+		config.TokenStyleSnowflake,
 	}
 
 	for _, style := range styles {
@@ -117,3 +129,259 @@ func TestAllTokenStyles(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateJWTExtraClaims(t *testing.T) {
+	gen := NewGenerator(&config.Config{TokenStyle: config.TokenStyleJWT, JwtSecretKey: "secret", Timeout: 3600})
+
+	token, err := gen.GenerateJWT("user1000", "web", map[string]any{
+		ClaimRoles:   []string{"admin"},
+		ClaimLoginID: "forged",
+	})
+	if err != nil {
+		t.Fatalf("GenerateJWT() error = %v", err)
+	}
+
+	claims, err := gen.ParseJWT(token)
+	if err != nil {
+		t.Fatalf("ParseJWT() error = %v", err)
+	}
+	if claims[ClaimLoginID] != "user1000" || claims[ClaimDevice] != "web" {
+		t.Errorf("built-in claims overridden: %v", claims)
+	}
+	if roles, ok := claims[ClaimRoles].([]any); !ok || len(roles) != 1 || roles[0] != "admin" {
+		t.Errorf("roles claim = %v", claims[ClaimRoles])
+	}
+}
+
+func TestJWTKeySet(t *testing.T) {
+	for _, alg := range []string{jwk.AlgRS256, jwk.AlgES256, jwk.AlgEdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			old, err := jwk.GenerateKey("old", alg)
+			if err != nil {
+				t.Fatalf("GenerateKey() error = %v", err)
+			}
+			ks := jwk.NewKeySet()
+			ks.SetSigningKey(old)
+			gen := NewGenerator(&config.Config{TokenStyle: config.TokenStyleJWT, JwtKeySet: ks, Timeout: 3600})
+
+			oldToken, err := gen.Generate("user1000", "web")
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			next, _ := jwk.GenerateKey("next", alg)
+			ks.Rotate(next, time.Hour)
+			newToken, _ := gen.Generate("user1000", "web")
+
+			for _, token := range []string{oldToken, newToken} {
+				if loginID, err := gen.GetLoginIDFromJWT(token); err != nil || loginID != "user1000" {
+					t.Errorf("GetLoginIDFromJWT() = %q, %v", loginID, err)
+				}
+			}
+
+			ks.Remove("old")
+			if err := gen.ValidateJWT(oldToken); err == nil {
+				t.Error("token signed with a removed key should be rejected")
+			}
+		})
+	}
+}
+
+func TestJWTMissingKey(t *testing.T) {
+	gen := NewGenerator(&config.Config{TokenStyle: config.TokenStyleJWT})
+	if _, err := gen.Generate("user1000", "web"); !errors.Is(err, ErrMissingJWTKey) {
+		t.Errorf("Generate() error = %v, want ErrMissingJWTKey", err)
+	}
+
+	// An HS256 token signed with the old default secret must not verify
+	forged, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{ClaimLoginID: "admin"}).SignedString([]byte("default-secret-key"))
+	if _, err := gen.ParseJWT(forged); err == nil {
+		t.Error("ParseJWT() should fail without a configured key")
+	}
+
+	// alg confusion: an HS256 token carrying the kid of an asymmetric key is rejected
+	key, _ := jwk.GenerateKey("k1", jwk.AlgEdDSA)
+	ks := jwk.NewKeySet()
+	ks.SetSigningKey(key)
+	gen = NewGenerator(&config.Config{TokenStyle: config.TokenStyleJWT, JwtKeySet: ks})
+	confused := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{ClaimLoginID: "admin"})
+	confused.Header["kid"] = "k1"
+	signed, _ := confused.SignedString([]byte("anything"))
+	if _, err := gen.ParseJWT(signed); err == nil {
+		t.Error("ParseJWT() should reject an alg that does not match the key")
+	}
+}
+
+func TestRegisterGenerator(t *testing.T) {
+	cfg := config.DefaultConfig().SetTokenStyle("pin")
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Validate() should reject an unregistered style")
+	}
+
+	if err := RegisterGenerator("pin", NewRandomGenerator(6, NumericCharset)); err != nil {
+		t.Fatalf("RegisterGenerator() error = %v", err)
+	}
+	if err := RegisterGenerator(config.TokenStyleJWT, NewULIDGenerator()); !errors.Is(err, ErrInvalidGenerator) {
+		t.Errorf("registering jwt error = %v, want ErrInvalidGenerator", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	token, err := NewGenerator(cfg).Generate("user1000", "default")
+	if err != nil || len(token) != 6 || strings.Trim(token, NumericCharset) != "" {
+		t.Errorf("pin token = %q, %v", token, err)
+	}
+}
+
+func TestULIDAndSnowflake(t *testing.T) {
+	first, _ := NewULIDGenerator().Generate("", "")
+	time.Sleep(2 * time.Millisecond)
+	second, _ := NewULIDGenerator().Generate("", "")
+	if len(first) != ULIDLength || strings.Trim(first, ULIDCharset) != "" || first[0] > '7' || first >= second {
+		t.Errorf("ULIDs %q, %q should be 26 sortable base32 characters", first, second)
+	}
+
+	if _, err := NewSnowflakeGenerator(SnowflakeNodeMax + 1); err == nil {
+		t.Error("NewSnowflakeGenerator() should reject an out-of-range node")
+	}
+	gen, _ := NewSnowflakeGenerator(7)
+	var last int64
+	for i := 0; i < 10000; i++ {
+		token, _ := gen.Generate("", "")
+		id, err := strconv.ParseInt(token, 10, 64)
+		if err != nil || id <= last || id>>snowflakeSeqBits&SnowflakeNodeMax != 7 {
+			t.Fatalf("snowflake id %q after %d", token, last)
+		}
+		last = id
+	}
+}
+
+func TestSignedTokens(t *testing.T) {
+	old, _ := jwk.NewHMACKey("old", []byte("old-secret"))
+	ks := jwk.NewKeySet()
+	ks.SetSigningKey(old)
+	gen := NewGenerator(&config.Config{TokenStyle: config.TokenStyleRandom32, TokenSignKeySet: ks})
+
+	oldToken, err := gen.Generate("user1000", "web")
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if value, _, ok := strings.Cut(oldToken, SignedTagSeparator); !ok || len(value) != 32 {
+		t.Fatalf("signed token = %q, want random.tag", oldToken)
+	}
+	if !gen.VerifySignature(oldToken) {
+		t.Error("freshly signed token should verify")
+	}
+	for _, forged := range []string{"", "garbage", oldToken[:32], oldToken + "x", "x" + oldToken} {
+		if gen.VerifySignature(forged) {
+			t.Errorf("VerifySignature(%q) = true, want false", forged)
+		}
+	}
+
+	// Old tags verify during the grace period only | 旧标签仅在宽限期内有效
+	next, _ := jwk.NewHMACKey("next", []byte("next-secret"))
+	ks.Rotate(next, time.Hour)
+	newToken, _ := gen.Generate("user1000", "web")
+	if !gen.VerifySignature(oldToken) || !gen.VerifySignature(newToken) {
+		t.Error("tokens of both keys should verify during rotation")
+	}
+	ks.Remove("old")
+	if gen.VerifySignature(oldToken) {
+		t.Error("token tagged with a removed key should be rejected")
+	}
+}
+
+func TestPasetoV4(t *testing.T) {
+	// Official test vector 4-S-1 | 官方测试向量 4-S-1
+	sk, _ := hex.DecodeString("b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a37741eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	vector := signPasetoV4(ed25519.PrivateKey(sk), []byte(`{"data":"this is a signed message","exp":"2022-01-01T00:00:00+00:00"}`), nil)
+	if vector != "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9bg_XBBzds8lTZShVlwwKSgeKpLT3yukTw6JUz3W4h_ExsQV-P0V54zemZDcAxFaSeef1QlXEFtkqxT1ciiQEDA" {
+		t.Errorf("v4.public vector = %s", vector)
+	}
+
+	publicKey, _ := jwk.GenerateKey("old", jwk.AlgEdDSA)
+	localKey, _ := jwk.NewHMACKey("old", bytes.Repeat([]byte{7}, PasetoLocalKeySize))
+	cases := map[config.TokenStyle]*jwk.Key{
+		config.TokenStylePasetoV4Public: publicKey,
+		config.TokenStylePasetoV4Local:  localKey,
+	}
+	for style, key := range cases {
+		t.Run(string(style), func(t *testing.T) {
+			ks := jwk.NewKeySet()
+			ks.SetSigningKey(key)
+			gen := NewGenerator(&config.Config{TokenStyle: style, JwtKeySet: ks, Timeout: 3600})
+
+			token, err := gen.GenerateJWT("user1000", "web", map[string]any{"tenantId": "t1"})
+			if err != nil {
+				t.Fatalf("GenerateJWT() error = %v", err)
+			}
+			claims, err := gen.ParseJWT(token)
+			if err != nil {
+				t.Fatalf("ParseJWT() error = %v", err)
+			}
+			if claims[ClaimLoginID] != "user1000" || claims["tenantId"] != "t1" {
+				t.Errorf("claims = %v", claims)
+			}
+			if exp, _ := claims["exp"].(float64); exp-claims["iat"].(float64) != 3600 {
+				t.Errorf("exp claim = %v, want Unix seconds 3600 after iat", claims["exp"])
+			}
+			if style == config.TokenStylePasetoV4Local && strings.Contains(token, "user1000") {
+				t.Error("v4.local claims should be encrypted")
+			}
+
+			// Tampering with body or footer fails | 篡改主体或footer会失败
+			i := strings.LastIndex(token, ".")
+			head, footer := token[:i], token[i:]
+			flipped := []byte(head)
+			flipped[len(flipped)-10] ^= 1
+			otherFooter := "." + base64.RawURLEncoding.EncodeToString([]byte(`{"kid":"old","x":1}`))
+			for _, forged := range []string{string(flipped) + footer, head + otherFooter, head} {
+				if _, err := gen.ParseJWT(forged); err == nil {
+					t.Errorf("ParseJWT(%q) should fail", forged)
+				}
+			}
+
+			ks.Remove("old")
+			if err := gen.ValidateJWT(token); !errors.Is(err, jwk.ErrKeyNotFound) {
+				t.Errorf("token of a removed key error = %v", err)
+			}
+		})
+	}
+
+	expired := NewGenerator(&config.Config{TokenStyle: config.TokenStylePasetoV4Public, JwtKeySet: jwk.NewKeySet()})
+	key, _ := jwk.GenerateKey("k", jwk.AlgEdDSA)
+	expired.config.JwtKeySet.SetSigningKey(key)
+	token, _ := expired.signPaseto(jwt.MapClaims{ClaimLoginID: "user1000", "exp": time.Now().Add(-time.Minute).Unix()})
+	if _, err := expired.ParseJWT(token); !errors.Is(err, jwt.ErrTokenExpired) {
+		t.Errorf("expired token error = %v", err)
+	}
+}
+
+// revokedSet RevocationChecker over a fixed set of jti | 基于固定jti集合的RevocationChecker
+type revokedSet map[string]bool
+
+func (s revokedSet) IsRevoked(jti string) bool { return s[jti] }
+
+func TestRevocationChecker(t *testing.T) {
+	gen := NewGenerator(&config.Config{TokenStyle: config.TokenStyleJWT, JwtSecretKey: "secret", Timeout: 3600})
+	first, _ := gen.GenerateJWT("user1000", "web", nil)
+	second, _ := gen.GenerateJWT("user1000", "web", nil)
+
+	claims, err := gen.ParseJWT(first)
+	if err != nil {
+		t.Fatalf("ParseJWT() error = %v", err)
+	}
+	jti, _ := claims[ClaimJTI].(string)
+	if other, _ := gen.ParseJWT(second); jti == "" || other[ClaimJTI] == jti {
+		t.Fatalf("jti = %q, want unique per token", jti)
+	}
+
+	gen.SetRevocationChecker(revokedSet{jti: true})
+	if _, err := gen.ParseJWT(first); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("revoked token error = %v", err)
+	}
+	if _, err := gen.ParseJWT(second); err != nil {
+		t.Errorf("other token error = %v", err)
+	}
+}
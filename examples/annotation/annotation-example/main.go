@@ -151,7 +151,7 @@ func main() {
 
 	// 需要用户权限或管理员权限（OR逻辑）
 	r.GET("/user-or-admin",
-		sagin.CheckPermission("user:read", "admin:*"),
+		sagin.CheckPermissionOr("user:read", "admin:*"),
 		handler.GetUserOrAdmin)
 
 	// 需要管理员角色
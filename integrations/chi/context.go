@@ -1,6 +1,7 @@
 package chi
 
 import (
+	"bytes"
 	"context"
 	"io"
 	"net/http"
@@ -8,6 +9,9 @@ import (
 	"suwei.sa_token/core/adapter"
 )
 
+// DefaultMaxBodySize Largest body GetBody reads, 1 MiB | GetBody读取的最大请求体，1 MiB
+const DefaultMaxBodySize int64 = 1 << 20
+
 // ChiContext Chi request context adapter | Chi请求上下文适配器
 type ChiContext struct {
 	w       http.ResponseWriter
@@ -125,9 +129,24 @@ func (c *ChiContext) GetPostForm(key string) string {
 	return c.r.FormValue(key)
 }
 
-// GetBody implements adapter.RequestContext.
+// GetBody implements adapter.RequestContext, reading up to DefaultMaxBodySize, the body stays readable for the handler.
 func (c *ChiContext) GetBody() ([]byte, error) {
-	return io.ReadAll(c.r.Body)
+	original := c.r.Body
+	if original == nil {
+		return nil, nil
+	}
+	var read bytes.Buffer
+	body, err := io.ReadAll(http.MaxBytesReader(c.w, io.NopCloser(io.TeeReader(original, &read)), DefaultMaxBodySize))
+	if err != nil {
+		// Hand back everything consumed, the handler decides about the rest | 交还已读取的全部内容，其余由处理器决定
+		c.r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(&read, original), original}
+		return nil, err
+	}
+	c.r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
 }
 
 // GetURL implements adapter.RequestContext.
@@ -147,7 +166,7 @@ func (c *ChiContext) SetCookieWithOptions(options *adapter.CookieOptions) {
 		Value:    options.Value,
 		Path:     options.Path,
 		Domain:   options.Domain,
-		MaxAge:   options.MaxAge,
+		MaxAge:   options.HTTPMaxAge(),
 		Secure:   options.Secure,
 		HttpOnly: options.HttpOnly,
 		SameSite: http.SameSiteLaxMode, // Default to Lax
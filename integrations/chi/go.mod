@@ -3,6 +3,7 @@ module suwei.sa_token/integrations/chi
 go 1.21
 
 require (
+	github.com/go-chi/chi/v5 v5.0.11
 	suwei.sa_token/core v0.1.2
 	suwei.sa_token/stputil v0.0.0-20251017234446-3cf2bdee68cc
 )
@@ -1,40 +1,40 @@
 package chi
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"net/http"
 
+	"github.com/go-chi/chi/v5"
 	"suwei.sa_token/core"
 )
 
 // Plugin Chi plugin for Sa-Token | Chi插件
 type Plugin struct {
 	manager *core.Manager
+	engine  *core.MiddlewareEngine
 }
 
 // NewPlugin creates a Chi plugin | 创建Chi插件
 func NewPlugin(manager *core.Manager) *Plugin {
 	return &Plugin{
 		manager: manager,
+		engine:  core.NewMiddlewareEngine(manager),
 	}
 }
 
+// SetErrorRenderer overrides the global error renderer for this plugin | 为此插件覆盖全局错误渲染器
+func (p *Plugin) SetErrorRenderer(renderer core.ErrorRenderer) *Plugin {
+	p.engine.SetErrorRenderer(renderer)
+	return p
+}
+
 // AuthMiddleware authentication middleware | 认证中间件
 func (p *Plugin) AuthMiddleware() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ctx := NewChiContext(w, r)
-			saCtx := core.NewContext(ctx, p.manager)
-
-			if err := saCtx.CheckLogin(); err != nil {
-				writeErrorResponse(w, err)
-				return
-			}
-
-			// Store Sa-Token context | 存储Sa-Token上下文
-			ctx.Set("satoken", saCtx)
-			next.ServeHTTP(w, r)
+			saCtx, err := p.engine.CheckLogin(NewChiContext(w, r))
+			p.proceed(w, r, next, saCtx, err)
 		})
 	}
 }
@@ -43,21 +43,8 @@ func (p *Plugin) AuthMiddleware() func(http.Handler) http.Handler {
 func (p *Plugin) PermissionRequired(permission string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ctx := NewChiContext(w, r)
-			saCtx := core.NewContext(ctx, p.manager)
-
-			if err := saCtx.CheckLogin(); err != nil {
-				writeErrorResponse(w, err)
-				return
-			}
-
-			if !saCtx.HasPermission(permission) {
-				writeErrorResponse(w, core.NewPermissionDeniedError(permission))
-				return
-			}
-
-			ctx.Set("satoken", saCtx)
-			next.ServeHTTP(w, r)
+			saCtx, err := p.engine.CheckPermission(NewChiContext(w, r), permission)
+			p.proceed(w, r, next, saCtx, err)
 		})
 	}
 }
@@ -66,25 +53,68 @@ func (p *Plugin) PermissionRequired(permission string) func(http.Handler) http.H
 func (p *Plugin) RoleRequired(role string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ctx := NewChiContext(w, r)
-			saCtx := core.NewContext(ctx, p.manager)
+			saCtx, err := p.engine.CheckRole(NewChiContext(w, r), role)
+			p.proceed(w, r, next, saCtx, err)
+		})
+	}
+}
 
-			if err := saCtx.CheckLogin(); err != nil {
-				writeErrorResponse(w, err)
-				return
-			}
+// AuthorizeRequired policy authorization middleware | 策略授权中间件
+// resolver builds the resource from the request, e.g. from route params | resolver 从请求构建资源，例如从路由参数
+func (p *Plugin) AuthorizeRequired(action string, resolver func(r *http.Request) (*core.PolicyResource, error)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			saCtx, err := p.engine.Authorize(NewChiContext(w, r), action, func() (*core.PolicyResource, error) {
+				return resolver(r)
+			})
+			p.proceed(w, r, next, saCtx, err)
+		})
+	}
+}
 
-			if !saCtx.HasRole(role) {
-				writeErrorResponse(w, core.NewRoleDeniedError(role))
+// ACLRequired resource ACL middleware, reads the resource ID from a route parameter | 资源ACL中间件，从路由参数读取资源ID
+func (p *Plugin) ACLRequired(resourceType, param, action string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			saCtx, err := p.engine.CheckAccess(NewChiContext(w, r), resourceType, chi.URLParam(r, param), action)
+			p.proceed(w, r, next, saCtx, err)
+		})
+	}
+}
+
+// CSRFRequired CSRF middleware, checks unsafe requests authenticated by cookie | CSRF中间件，检查通过Cookie认证的非安全请求
+func (p *Plugin) CSRFRequired() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			saCtx, err := p.engine.CheckCSRF(NewChiContext(w, r))
+			p.proceed(w, r, next, saCtx, err)
+		})
+	}
+}
+
+// RouterMiddleware global route rule middleware, mount once with r.Use | 全局路由规则中间件，通过 r.Use 挂载一次
+func (p *Plugin) RouterMiddleware(rt *core.Router) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := p.engine.CheckRouter(NewChiContext(w, r), rt); err != nil {
+				p.writeError(w, r, err)
 				return
 			}
-
-			ctx.Set("satoken", saCtx)
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
+// proceed serves next with the checked context stored in the request, or writes the rendered error |
+// 将检查后的上下文存入请求并继续处理，或写入渲染后的错误
+func (p *Plugin) proceed(w http.ResponseWriter, r *http.Request, next http.Handler, saCtx *core.SaTokenContext, err error) {
+	if err != nil {
+		p.writeError(w, r, err)
+		return
+	}
+	next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), "satoken", saCtx)))
+}
+
 // LoginHandler 登录处理器
 func (p *Plugin) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	var req struct {
@@ -94,7 +124,7 @@ func (p *Plugin) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeErrorResponse(w, core.NewError(core.CodeBadRequest, "invalid request parameters", err))
+		p.writeError(w, r, core.NewError(core.CodeBadRequest, "invalid request parameters", err))
 		return
 	}
 
@@ -103,9 +133,10 @@ func (p *Plugin) LoginHandler(w http.ResponseWriter, r *http.Request) {
 		device = "default"
 	}
 
-	token, err := p.manager.Login(req.Username, device)
+	// Issued through the context so the response header and cookie settings apply | 通过上下文签发，使响应头和Cookie配置生效
+	token, err := core.NewContext(NewChiContext(w, r), p.manager).Login(req.Username, device)
 	if err != nil {
-		writeErrorResponse(w, core.NewError(core.CodeServerError, "login failed", err))
+		p.writeError(w, r, core.NewError(core.CodeServerError, "login failed", err))
 		return
 	}
 
@@ -126,32 +157,21 @@ func GetSaToken(r *http.Request) (*core.SaTokenContext, bool) {
 
 // ============ Error Handling Helpers | 错误处理辅助函数 ============
 
-// writeErrorResponse writes a standardized error response | 写入标准化的错误响应
-func writeErrorResponse(w http.ResponseWriter, err error) {
-	var saErr *core.SaTokenError
-	var code int
-	var message string
-	var httpStatus int
-
-	// Check if it's a SaTokenError | 检查是否为SaTokenError
-	if errors.As(err, &saErr) {
-		code = saErr.Code
-		message = saErr.Message
-		httpStatus = getHTTPStatusFromCode(code)
-	} else {
-		// Handle standard errors | 处理标准错误
-		code = core.CodeServerError
-		message = err.Error()
-		httpStatus = http.StatusInternalServerError
-	}
+// writeErrorResponse writes err with the global error renderer | 使用全局错误渲染器写入错误
+func writeErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	writeRendered(w, core.RenderError(NewChiContext(w, r), err))
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(httpStatus)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"code":    code,
-		"message": message,
-		"error":   err.Error(),
-	})
+// writeError writes err with the plugin's error renderer | 使用插件的错误渲染器写入错误
+func (p *Plugin) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	writeRendered(w, p.engine.Render(NewChiContext(w, r), err))
+}
+
+// writeRendered writes a rendered error response | 写入渲染后的错误响应
+func writeRendered(w http.ResponseWriter, resp *core.ErrorResponse) {
+	w.Header().Set("Content-Type", resp.ContentType)
+	w.WriteHeader(resp.Status)
+	w.Write(resp.Body)
 }
 
 // writeSuccessResponse writes a standardized success response | 写入标准化的成功响应
@@ -163,21 +183,3 @@ func writeSuccessResponse(w http.ResponseWriter, data interface{}) {
 		"data":    data,
 	})
 }
-
-// getHTTPStatusFromCode converts Sa-Token error code to HTTP status | 将Sa-Token错误码转换为HTTP状态码
-func getHTTPStatusFromCode(code int) int {
-	switch code {
-	case core.CodeNotLogin:
-		return http.StatusUnauthorized
-	case core.CodePermissionDenied:
-		return http.StatusForbidden
-	case core.CodeBadRequest:
-		return http.StatusBadRequest
-	case core.CodeNotFound:
-		return http.StatusNotFound
-	case core.CodeServerError:
-		return http.StatusInternalServerError
-	default:
-		return http.StatusInternalServerError
-	}
-}
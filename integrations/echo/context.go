@@ -1,6 +1,7 @@
 package echo
 
 import (
+	"bytes"
 	"io"
 	"net/http"
 
@@ -8,6 +9,9 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// DefaultMaxBodySize Largest body GetBody reads, 1 MiB | GetBody读取的最大请求体，1 MiB
+const DefaultMaxBodySize int64 = 1 << 20
+
 // EchoContext Echo request context adapter | Echo请求上下文适配器
 type EchoContext struct {
 	c       echo.Context
@@ -109,14 +113,26 @@ func (e *EchoContext) GetPostForm(key string) string {
 	return e.c.FormValue(key)
 }
 
-// GetBody implements adapter.RequestContext.
+// GetBody implements adapter.RequestContext, reading up to DefaultMaxBodySize, the body stays readable for the handler.
 func (e *EchoContext) GetBody() ([]byte, error) {
-	body := e.c.Request().Body
-	if body == nil {
+	req := e.c.Request()
+	if req.Body == nil {
 		return nil, nil
 	}
-	defer body.Close()
-	return io.ReadAll(body)
+	original := req.Body
+	var read bytes.Buffer
+	body, err := io.ReadAll(http.MaxBytesReader(e.c.Response(), io.NopCloser(io.TeeReader(original, &read)), DefaultMaxBodySize))
+	if err != nil {
+		// Hand back everything consumed, the handler decides about the rest | 交还已读取的全部内容，其余由处理器决定
+		req.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(&read, original), original}
+		return nil, err
+	}
+	original.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
 }
 
 // GetURL implements adapter.RequestContext.
@@ -136,7 +152,7 @@ func (e *EchoContext) SetCookieWithOptions(options *adapter.CookieOptions) {
 		Value:    options.Value,
 		Path:     options.Path,
 		Domain:   options.Domain,
-		MaxAge:   options.MaxAge,
+		MaxAge:   options.HTTPMaxAge(),
 		Secure:   options.Secure,
 		HttpOnly: options.HttpOnly,
 		SameSite: http.SameSiteLaxMode, // Default to Lax
@@ -1,38 +1,38 @@
 package echo
 
 import (
-	"errors"
 	"net/http"
 
-	"suwei.sa_token/core"
 	"github.com/labstack/echo/v4"
+	"suwei.sa_token/core"
 )
 
 // Plugin Echo plugin for Sa-Token | Echo插件
 type Plugin struct {
 	manager *core.Manager
+	engine  *core.MiddlewareEngine
 }
 
 // NewPlugin creates an Echo plugin | 创建Echo插件
 func NewPlugin(manager *core.Manager) *Plugin {
 	return &Plugin{
 		manager: manager,
+		engine:  core.NewMiddlewareEngine(manager),
 	}
 }
 
+// SetErrorRenderer overrides the global error renderer for this plugin | 为此插件覆盖全局错误渲染器
+func (p *Plugin) SetErrorRenderer(renderer core.ErrorRenderer) *Plugin {
+	p.engine.SetErrorRenderer(renderer)
+	return p
+}
+
 // AuthMiddleware authentication middleware | 认证中间件
 func (p *Plugin) AuthMiddleware() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			ctx := NewEchoContext(c)
-			saCtx := core.NewContext(ctx, p.manager)
-
-			if err := saCtx.CheckLogin(); err != nil {
-				return writeErrorResponse(c, err)
-			}
-
-			c.Set("satoken", saCtx)
-			return next(c)
+			saCtx, err := p.engine.CheckLogin(NewEchoContext(c))
+			return p.proceed(c, next, saCtx, err)
 		}
 	}
 }
@@ -41,19 +41,8 @@ func (p *Plugin) AuthMiddleware() echo.MiddlewareFunc {
 func (p *Plugin) PermissionRequired(permission string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			ctx := NewEchoContext(c)
-			saCtx := core.NewContext(ctx, p.manager)
-
-			if err := saCtx.CheckLogin(); err != nil {
-				return writeErrorResponse(c, err)
-			}
-
-			if !saCtx.HasPermission(permission) {
-				return writeErrorResponse(c, core.NewPermissionDeniedError(permission))
-			}
-
-			c.Set("satoken", saCtx)
-			return next(c)
+			saCtx, err := p.engine.CheckPermission(NewEchoContext(c), permission)
+			return p.proceed(c, next, saCtx, err)
 		}
 	}
 }
@@ -62,23 +51,67 @@ func (p *Plugin) PermissionRequired(permission string) echo.MiddlewareFunc {
 func (p *Plugin) RoleRequired(role string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			ctx := NewEchoContext(c)
-			saCtx := core.NewContext(ctx, p.manager)
+			saCtx, err := p.engine.CheckRole(NewEchoContext(c), role)
+			return p.proceed(c, next, saCtx, err)
+		}
+	}
+}
 
-			if err := saCtx.CheckLogin(); err != nil {
-				return writeErrorResponse(c, err)
-			}
+// AuthorizeRequired policy authorization middleware | 策略授权中间件
+// resolver builds the resource from the request, e.g. from route params | resolver 从请求构建资源，例如从路由参数
+func (p *Plugin) AuthorizeRequired(action string, resolver func(c echo.Context) (*core.PolicyResource, error)) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			saCtx, err := p.engine.Authorize(NewEchoContext(c), action, func() (*core.PolicyResource, error) {
+				return resolver(c)
+			})
+			return p.proceed(c, next, saCtx, err)
+		}
+	}
+}
 
-			if !saCtx.HasRole(role) {
-				return writeErrorResponse(c, core.NewRoleDeniedError(role))
-			}
+// ACLRequired resource ACL middleware, reads the resource ID from a route parameter | 资源ACL中间件，从路由参数读取资源ID
+func (p *Plugin) ACLRequired(resourceType, param, action string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			saCtx, err := p.engine.CheckAccess(NewEchoContext(c), resourceType, c.Param(param), action)
+			return p.proceed(c, next, saCtx, err)
+		}
+	}
+}
 
-			c.Set("satoken", saCtx)
+// CSRFRequired CSRF middleware, checks unsafe requests authenticated by cookie | CSRF中间件，检查通过Cookie认证的非安全请求
+func (p *Plugin) CSRFRequired() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			saCtx, err := p.engine.CheckCSRF(NewEchoContext(c))
+			return p.proceed(c, next, saCtx, err)
+		}
+	}
+}
+
+// RouterMiddleware global route rule middleware, mount once with e.Use | 全局路由规则中间件，通过 e.Use 挂载一次
+func (p *Plugin) RouterMiddleware(r *core.Router) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if err := p.engine.CheckRouter(NewEchoContext(c), r); err != nil {
+				return p.writeError(c, err)
+			}
 			return next(c)
 		}
 	}
 }
 
+// proceed calls next with the checked context or writes the rendered error |
+// 使用检查后的上下文调用next，或写入渲染后的错误
+func (p *Plugin) proceed(c echo.Context, next echo.HandlerFunc, saCtx *core.SaTokenContext, err error) error {
+	if err != nil {
+		return p.writeError(c, err)
+	}
+	c.Set("satoken", saCtx)
+	return next(c)
+}
+
 // LoginHandler 登录处理器
 func (p *Plugin) LoginHandler(c echo.Context) error {
 	var req struct {
@@ -88,7 +121,7 @@ func (p *Plugin) LoginHandler(c echo.Context) error {
 	}
 
 	if err := c.Bind(&req); err != nil {
-		return writeErrorResponse(c, core.NewError(core.CodeBadRequest, "invalid request parameters", err))
+		return p.writeError(c, core.NewError(core.CodeBadRequest, "invalid request parameters", err))
 	}
 
 	device := req.Device
@@ -96,9 +129,10 @@ func (p *Plugin) LoginHandler(c echo.Context) error {
 		device = "default"
 	}
 
-	token, err := p.manager.Login(req.Username, device)
+	// Issued through the context so the response header and cookie settings apply | 通过上下文签发，使响应头和Cookie配置生效
+	token, err := core.NewContext(NewEchoContext(c), p.manager).Login(req.Username, device)
 	if err != nil {
-		return writeErrorResponse(c, core.NewError(core.CodeServerError, "login failed", err))
+		return p.writeError(c, core.NewError(core.CodeServerError, "login failed", err))
 	}
 
 	return writeSuccessResponse(c, map[string]interface{}{
@@ -118,30 +152,19 @@ func GetSaToken(c echo.Context) (*core.SaTokenContext, bool) {
 
 // ============ Error Handling Helpers | 错误处理辅助函数 ============
 
-// writeErrorResponse writes a standardized error response | 写入标准化的错误响应
+// writeErrorResponse writes err with the global error renderer | 使用全局错误渲染器写入错误
 func writeErrorResponse(c echo.Context, err error) error {
-	var saErr *core.SaTokenError
-	var code int
-	var message string
-	var httpStatus int
-
-	// Check if it's a SaTokenError | 检查是否为SaTokenError
-	if errors.As(err, &saErr) {
-		code = saErr.Code
-		message = saErr.Message
-		httpStatus = getHTTPStatusFromCode(code)
-	} else {
-		// Handle standard errors | 处理标准错误
-		code = core.CodeServerError
-		message = err.Error()
-		httpStatus = http.StatusInternalServerError
-	}
+	return writeRendered(c, core.RenderError(NewEchoContext(c), err))
+}
 
-	return c.JSON(httpStatus, map[string]interface{}{
-		"code":    code,
-		"message": message,
-		"error":   err.Error(),
-	})
+// writeError writes err with the plugin's error renderer | 使用插件的错误渲染器写入错误
+func (p *Plugin) writeError(c echo.Context, err error) error {
+	return writeRendered(c, p.engine.Render(NewEchoContext(c), err))
+}
+
+// writeRendered writes a rendered error response | 写入渲染后的错误响应
+func writeRendered(c echo.Context, resp *core.ErrorResponse) error {
+	return c.Blob(resp.Status, resp.ContentType, resp.Body)
 }
 
 // writeSuccessResponse writes a standardized success response | 写入标准化的成功响应
@@ -152,21 +175,3 @@ func writeSuccessResponse(c echo.Context, data interface{}) error {
 		"data":    data,
 	})
 }
-
-// getHTTPStatusFromCode converts Sa-Token error code to HTTP status | 将Sa-Token错误码转换为HTTP状态码
-func getHTTPStatusFromCode(code int) int {
-	switch code {
-	case core.CodeNotLogin:
-		return http.StatusUnauthorized
-	case core.CodePermissionDenied:
-		return http.StatusForbidden
-	case core.CodeBadRequest:
-		return http.StatusBadRequest
-	case core.CodeNotFound:
-		return http.StatusNotFound
-	case core.CodeServerError:
-		return http.StatusInternalServerError
-	default:
-		return http.StatusInternalServerError
-	}
-}
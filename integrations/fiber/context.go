@@ -129,7 +129,6 @@ func (f *FiberContext) SetCookieWithOptions(options *adapter.CookieOptions) {
 		Value:    options.Value,
 		Path:     options.Path,
 		Domain:   options.Domain,
-		MaxAge:   options.MaxAge,
 		Secure:   options.Secure,
 		HTTPOnly: options.HttpOnly,
 		SameSite: "Lax", // Default to Lax
@@ -145,8 +144,13 @@ func (f *FiberContext) SetCookieWithOptions(options *adapter.CookieOptions) {
 		cookie.SameSite = "None"
 	}
 	
-	if options.MaxAge > 0 {
-		cookie.Expires = time.Now().Add(time.Duration(options.MaxAge) * time.Second)
+	switch maxAge := options.HTTPMaxAge(); {
+	case maxAge > 0:
+		cookie.MaxAge = maxAge
+		cookie.Expires = time.Now().Add(time.Duration(maxAge) * time.Second)
+	case maxAge < 0:
+		// fasthttp drops negative Max-Age, expire in the past to delete the cookie
+		cookie.Expires = time.Unix(0, 0)
 	}
 	
 	f.c.Cookie(cookie)
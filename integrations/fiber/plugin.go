@@ -1,77 +1,101 @@
 package fiber
 
 import (
-	"errors"
-
-	"suwei.sa_token/core"
 	"github.com/gofiber/fiber/v2"
+	"suwei.sa_token/core"
 )
 
 // Plugin Fiber plugin for Sa-Token | Fiber插件
 type Plugin struct {
 	manager *core.Manager
+	engine  *core.MiddlewareEngine
 }
 
 // NewPlugin creates a Fiber plugin | 创建Fiber插件
 func NewPlugin(manager *core.Manager) *Plugin {
 	return &Plugin{
 		manager: manager,
+		engine:  core.NewMiddlewareEngine(manager),
 	}
 }
 
+// SetErrorRenderer overrides the global error renderer for this plugin | 为此插件覆盖全局错误渲染器
+func (p *Plugin) SetErrorRenderer(renderer core.ErrorRenderer) *Plugin {
+	p.engine.SetErrorRenderer(renderer)
+	return p
+}
+
 // AuthMiddleware authentication middleware | 认证中间件
 func (p *Plugin) AuthMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		ctx := NewFiberContext(c)
-		saCtx := core.NewContext(ctx, p.manager)
-
-		if err := saCtx.CheckLogin(); err != nil {
-			return writeErrorResponse(c, err)
-		}
-
-		c.Locals("satoken", saCtx)
-		return c.Next()
+		saCtx, err := p.engine.CheckLogin(NewFiberContext(c))
+		return p.proceed(c, saCtx, err)
 	}
 }
 
 // PermissionRequired permission validation middleware | 权限验证中间件
 func (p *Plugin) PermissionRequired(permission string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		ctx := NewFiberContext(c)
-		saCtx := core.NewContext(ctx, p.manager)
-
-		if err := saCtx.CheckLogin(); err != nil {
-			return writeErrorResponse(c, err)
-		}
-
-		if !saCtx.HasPermission(permission) {
-			return writeErrorResponse(c, core.NewPermissionDeniedError(permission))
-		}
-
-		c.Locals("satoken", saCtx)
-		return c.Next()
+		saCtx, err := p.engine.CheckPermission(NewFiberContext(c), permission)
+		return p.proceed(c, saCtx, err)
 	}
 }
 
 // RoleRequired role validation middleware | 角色验证中间件
 func (p *Plugin) RoleRequired(role string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		ctx := NewFiberContext(c)
-		saCtx := core.NewContext(ctx, p.manager)
+		saCtx, err := p.engine.CheckRole(NewFiberContext(c), role)
+		return p.proceed(c, saCtx, err)
+	}
+}
 
-		if err := saCtx.CheckLogin(); err != nil {
-			return writeErrorResponse(c, err)
-		}
+// AuthorizeRequired policy authorization middleware | 策略授权中间件
+// resolver builds the resource from the request, e.g. from route params | resolver 从请求构建资源，例如从路由参数
+func (p *Plugin) AuthorizeRequired(action string, resolver func(c *fiber.Ctx) (*core.PolicyResource, error)) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		saCtx, err := p.engine.Authorize(NewFiberContext(c), action, func() (*core.PolicyResource, error) {
+			return resolver(c)
+		})
+		return p.proceed(c, saCtx, err)
+	}
+}
 
-		if !saCtx.HasRole(role) {
-			return writeErrorResponse(c, core.NewRoleDeniedError(role))
-		}
+// ACLRequired resource ACL middleware, reads the resource ID from a route parameter | 资源ACL中间件，从路由参数读取资源ID
+func (p *Plugin) ACLRequired(resourceType, param, action string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		saCtx, err := p.engine.CheckAccess(NewFiberContext(c), resourceType, c.Params(param), action)
+		return p.proceed(c, saCtx, err)
+	}
+}
 
-		c.Locals("satoken", saCtx)
+// CSRFRequired CSRF middleware, checks unsafe requests authenticated by cookie | CSRF中间件，检查通过Cookie认证的非安全请求
+func (p *Plugin) CSRFRequired() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		saCtx, err := p.engine.CheckCSRF(NewFiberContext(c))
+		return p.proceed(c, saCtx, err)
+	}
+}
+
+// RouterMiddleware global route rule middleware, mount once with app.Use | 全局路由规则中间件，通过 app.Use 挂载一次
+func (p *Plugin) RouterMiddleware(r *core.Router) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := p.engine.CheckRouter(NewFiberContext(c), r); err != nil {
+			return p.writeError(c, err)
+		}
 		return c.Next()
 	}
 }
 
+// proceed continues the chain with the checked context or writes the rendered error |
+// 使用检查后的上下文继续执行，或写入渲染后的错误
+func (p *Plugin) proceed(c *fiber.Ctx, saCtx *core.SaTokenContext, err error) error {
+	if err != nil {
+		return p.writeError(c, err)
+	}
+	c.Locals("satoken", saCtx)
+	return c.Next()
+}
+
 // LoginHandler 登录处理器
 func (p *Plugin) LoginHandler(c *fiber.Ctx) error {
 	var req struct {
@@ -81,7 +105,7 @@ func (p *Plugin) LoginHandler(c *fiber.Ctx) error {
 	}
 
 	if err := c.BodyParser(&req); err != nil {
-		return writeErrorResponse(c, core.NewError(core.CodeBadRequest, "invalid request parameters", err))
+		return p.writeError(c, core.NewError(core.CodeBadRequest, "invalid request parameters", err))
 	}
 
 	device := req.Device
@@ -89,9 +113,10 @@ func (p *Plugin) LoginHandler(c *fiber.Ctx) error {
 		device = "default"
 	}
 
-	token, err := p.manager.Login(req.Username, device)
+	// Issued through the context so the response header and cookie settings apply | 通过上下文签发，使响应头和Cookie配置生效
+	token, err := core.NewContext(NewFiberContext(c), p.manager).Login(req.Username, device)
 	if err != nil {
-		return writeErrorResponse(c, core.NewError(core.CodeServerError, "login failed", err))
+		return p.writeError(c, core.NewError(core.CodeServerError, "login failed", err))
 	}
 
 	return writeSuccessResponse(c, fiber.Map{
@@ -111,30 +136,20 @@ func GetSaToken(c *fiber.Ctx) (*core.SaTokenContext, bool) {
 
 // ============ Error Handling Helpers | 错误处理辅助函数 ============
 
-// writeErrorResponse writes a standardized error response | 写入标准化的错误响应
+// writeErrorResponse writes err with the global error renderer | 使用全局错误渲染器写入错误
 func writeErrorResponse(c *fiber.Ctx, err error) error {
-	var saErr *core.SaTokenError
-	var code int
-	var message string
-	var httpStatus int
-
-	// Check if it's a SaTokenError | 检查是否为SaTokenError
-	if errors.As(err, &saErr) {
-		code = saErr.Code
-		message = saErr.Message
-		httpStatus = getHTTPStatusFromCode(code)
-	} else {
-		// Handle standard errors | 处理标准错误
-		code = core.CodeServerError
-		message = err.Error()
-		httpStatus = fiber.StatusInternalServerError
-	}
+	return writeRendered(c, core.RenderError(NewFiberContext(c), err))
+}
 
-	return c.Status(httpStatus).JSON(fiber.Map{
-		"code":    code,
-		"message": message,
-		"error":   err.Error(),
-	})
+// writeError writes err with the plugin's error renderer | 使用插件的错误渲染器写入错误
+func (p *Plugin) writeError(c *fiber.Ctx, err error) error {
+	return writeRendered(c, p.engine.Render(NewFiberContext(c), err))
+}
+
+// writeRendered writes a rendered error response | 写入渲染后的错误响应
+func writeRendered(c *fiber.Ctx, resp *core.ErrorResponse) error {
+	c.Set(fiber.HeaderContentType, resp.ContentType)
+	return c.Status(resp.Status).Send(resp.Body)
 }
 
 // writeSuccessResponse writes a standardized success response | 写入标准化的成功响应
@@ -145,21 +160,3 @@ func writeSuccessResponse(c *fiber.Ctx, data interface{}) error {
 		"data":    data,
 	})
 }
-
-// getHTTPStatusFromCode converts Sa-Token error code to HTTP status | 将Sa-Token错误码转换为HTTP状态码
-func getHTTPStatusFromCode(code int) int {
-	switch code {
-	case core.CodeNotLogin:
-		return fiber.StatusUnauthorized
-	case core.CodePermissionDenied:
-		return fiber.StatusForbidden
-	case core.CodeBadRequest:
-		return fiber.StatusBadRequest
-	case core.CodeNotFound:
-		return fiber.StatusNotFound
-	case core.CodeServerError:
-		return fiber.StatusInternalServerError
-	default:
-		return fiber.StatusInternalServerError
-	}
-}
@@ -116,7 +116,7 @@ func (g *GFContext) SetCookieWithOptions(options *adapter.CookieOptions) {
 	cookie := &http.Cookie{
 		Name:     options.Name,
 		Value:    options.Value,
-		MaxAge:   options.MaxAge,
+		MaxAge:   options.HTTPMaxAge(),
 		Path:     options.Path,
 		Domain:   options.Domain,
 		Secure:   options.Secure,
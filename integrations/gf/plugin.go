@@ -1,85 +1,107 @@
 package gf
 
 import (
-	"errors"
 	"net/http"
 
-	"suwei.sa_token/core"
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/net/ghttp"
+	"suwei.sa_token/core"
 )
 
 // Plugin GoFrame plugin for Sa-Token | GoFrame插件
 type Plugin struct {
 	manager *core.Manager
+	engine  *core.MiddlewareEngine
 }
 
 // NewPlugin creates an GoFrame plugin | 创建GoFrame插件
 func NewPlugin(manager *core.Manager) *Plugin {
 	return &Plugin{
 		manager: manager,
+		engine:  core.NewMiddlewareEngine(manager),
 	}
 }
 
+// SetErrorRenderer overrides the global error renderer for this plugin | 为此插件覆盖全局错误渲染器
+func (p *Plugin) SetErrorRenderer(renderer core.ErrorRenderer) *Plugin {
+	p.engine.SetErrorRenderer(renderer)
+	return p
+}
+
 // AuthMiddleware authentication middleware | 认证中间件
 func (p *Plugin) AuthMiddleware() ghttp.HandlerFunc {
 	return func(r *ghttp.Request) {
-		ctx := NewGFContext(r)
-		saCtx := core.NewContext(ctx, p.manager)
-		// Check login | 检查登录
-		if err := saCtx.CheckLogin(); err != nil {
-			writeErrorResponse(r, err)
-			return
-		}
-		// Store Sa-Token context in GoFrame context | 将Sa-Token上下文存储到GoFrame上下文
-		r.SetCtxVar("satoken", saCtx)
-
-		r.Middleware.Next()
+		saCtx, err := p.engine.CheckLogin(NewGFContext(r))
+		p.proceed(r, saCtx, err)
 	}
-
 }
 
 // PermissionRequired permission validation middleware | 权限验证中间件
 func (p *Plugin) PermissionRequired(permission string) ghttp.HandlerFunc {
 	return func(r *ghttp.Request) {
-		ctx := NewGFContext(r)
-		saCtx := core.NewContext(ctx, p.manager)
-
-		if err := saCtx.CheckLogin(); err != nil {
-			writeErrorResponse(r, err)
-			return
-		}
-		if !saCtx.HasPermission(permission) {
-			writeErrorResponse(r, core.NewPermissionDeniedError(permission))
-			return
-		}
-		r.SetCtxVar("satoken", saCtx)
-		r.Middleware.Next()
+		saCtx, err := p.engine.CheckPermission(NewGFContext(r), permission)
+		p.proceed(r, saCtx, err)
 	}
-
 }
 
 // RoleRequired role validation middleware | 角色验证中间件
 func (p *Plugin) RoleRequired(role string) ghttp.HandlerFunc {
 	return func(r *ghttp.Request) {
-		ctx := NewGFContext(r)
-		saCtx := core.NewContext(ctx, p.manager)
+		saCtx, err := p.engine.CheckRole(NewGFContext(r), role)
+		p.proceed(r, saCtx, err)
+	}
+}
 
-		if err := saCtx.CheckLogin(); err != nil {
-			writeErrorResponse(r, err)
-			return
-		}
+// AuthorizeRequired policy authorization middleware | 策略授权中间件
+// resolver builds the resource from the request, e.g. from route params | resolver 从请求构建资源，例如从路由参数
+func (p *Plugin) AuthorizeRequired(action string, resolver func(r *ghttp.Request) (*core.PolicyResource, error)) ghttp.HandlerFunc {
+	return func(r *ghttp.Request) {
+		saCtx, err := p.engine.Authorize(NewGFContext(r), action, func() (*core.PolicyResource, error) {
+			return resolver(r)
+		})
+		p.proceed(r, saCtx, err)
+	}
+}
 
-		if !saCtx.HasRole(role) {
-			writeErrorResponse(r, core.NewRoleDeniedError(role))
+// ACLRequired resource ACL middleware, reads the resource ID from a route parameter | 资源ACL中间件，从路由参数读取资源ID
+func (p *Plugin) ACLRequired(resourceType, param, action string) ghttp.HandlerFunc {
+	return func(r *ghttp.Request) {
+		saCtx, err := p.engine.CheckAccess(NewGFContext(r), resourceType, r.GetRouter(param).String(), action)
+		p.proceed(r, saCtx, err)
+	}
+}
+
+// CSRFRequired CSRF middleware, checks unsafe requests authenticated by cookie | CSRF中间件，检查通过Cookie认证的非安全请求
+func (p *Plugin) CSRFRequired() ghttp.HandlerFunc {
+	return func(r *ghttp.Request) {
+		saCtx, err := p.engine.CheckCSRF(NewGFContext(r))
+		p.proceed(r, saCtx, err)
+	}
+}
+
+// RouterMiddleware global route rule middleware, mount once with s.Use | 全局路由规则中间件，通过 s.Use 挂载一次
+func (p *Plugin) RouterMiddleware(rt *core.Router) ghttp.HandlerFunc {
+	return func(r *ghttp.Request) {
+		if err := p.engine.CheckRouter(NewGFContext(r), rt); err != nil {
+			p.writeError(r, err)
 			return
 		}
-
-		r.SetCtxVar("satoken", saCtx)
 		r.Middleware.Next()
 	}
 }
 
+// proceed stores the checked context and continues, or writes the rendered error |
+// 存储检查后的上下文并继续，或写入渲染后的错误
+func (p *Plugin) proceed(r *ghttp.Request, saCtx *core.SaTokenContext, err error) {
+	if err != nil {
+		p.writeError(r, err)
+		return
+	}
+	// Store Sa-Token context in GoFrame context | 将Sa-Token上下文存储到GoFrame上下文
+	r.SetCtxVar("satoken", saCtx)
+	r.Middleware.Next()
+}
+
 // LoginHandler 登录处理器
 func (p *Plugin) LoginHandler(r *ghttp.Request) {
 	var req struct {
@@ -89,7 +111,7 @@ func (p *Plugin) LoginHandler(r *ghttp.Request) {
 	}
 
 	if err := r.Parse(&req); err != nil {
-		writeErrorResponse(r, core.NewError(core.CodeBadRequest, "invalid request parameters", err))
+		p.writeError(r, core.NewError(core.CodeBadRequest, "invalid request parameters", err))
 		return
 	}
 
@@ -98,9 +120,10 @@ func (p *Plugin) LoginHandler(r *ghttp.Request) {
 		device = "default"
 	}
 
-	token, err := p.manager.Login(req.Username, device)
+	// Issued through the context so the response header and cookie settings apply | 通过上下文签发，使响应头和Cookie配置生效
+	token, err := core.NewContext(NewGFContext(r), p.manager).Login(req.Username, device)
 	if err != nil {
-		writeErrorResponse(r, core.NewError(core.CodeServerError, "login failed", err))
+		p.writeError(r, core.NewError(core.CodeServerError, "login failed", err))
 		return
 	}
 
@@ -116,7 +139,7 @@ func (p *Plugin) UserInfoHandler(r *ghttp.Request) {
 
 	loginID, err := saCtx.GetLoginID()
 	if err != nil {
-		writeErrorResponse(r, err)
+		p.writeError(r, err)
 		return
 	}
 
@@ -143,30 +166,20 @@ func GetSaToken(r *ghttp.Request) (*core.SaTokenContext, bool) {
 
 // ============ Error Handling Helpers | 错误处理辅助函数 ============
 
-// writeErrorResponse writes a standardized error response | 写入标准化的错误响应
+// writeErrorResponse writes err with the global error renderer | 使用全局错误渲染器写入错误
 func writeErrorResponse(r *ghttp.Request, err error) {
-	var saErr *core.SaTokenError
-	var code int
-	var message string
-	var httpStatus int
-
-	// Check if it's a SaTokenError | 检查是否为SaTokenError
-	if errors.As(err, &saErr) {
-		code = saErr.Code
-		message = saErr.Message
-		httpStatus = getHTTPStatusFromCode(code)
-	} else {
-		// Handle standard errors | 处理标准错误
-		code = core.CodeServerError
-		message = err.Error()
-		httpStatus = http.StatusInternalServerError
-	}
+	writeRendered(r, core.RenderError(NewGFContext(r), err))
+}
 
-	r.Response.WriteStatusExit(httpStatus, g.Map{
-		"code":    code,
-		"message": message,
-		"error":   err.Error(),
-	})
+// writeError writes err with the plugin's error renderer | 使用插件的错误渲染器写入错误
+func (p *Plugin) writeError(r *ghttp.Request, err error) {
+	writeRendered(r, p.engine.Render(NewGFContext(r), err))
+}
+
+// writeRendered writes a rendered error response | 写入渲染后的错误响应
+func writeRendered(r *ghttp.Request, resp *core.ErrorResponse) {
+	r.Response.Header().Set("Content-Type", resp.ContentType)
+	r.Response.WriteStatusExit(resp.Status, resp.Body)
 }
 
 // writeSuccessResponse writes a standardized success response | 写入标准化的成功响应
@@ -177,21 +190,3 @@ func writeSuccessResponse(r *ghttp.Request, data interface{}) {
 		"data":    data,
 	})
 }
-
-// getHTTPStatusFromCode converts Sa-Token error code to HTTP status | 将Sa-Token错误码转换为HTTP状态码
-func getHTTPStatusFromCode(code int) int {
-	switch code {
-	case core.CodeNotLogin:
-		return http.StatusUnauthorized
-	case core.CodePermissionDenied:
-		return http.StatusForbidden
-	case core.CodeBadRequest:
-		return http.StatusBadRequest
-	case core.CodeNotFound:
-		return http.StatusNotFound
-	case core.CodeServerError:
-		return http.StatusInternalServerError
-	default:
-		return http.StatusInternalServerError
-	}
-}
@@ -1,12 +1,17 @@
 package gin
 
 import (
+	"bytes"
+	"io"
 	"net/http"
 
 	"suwei.sa_token/core/adapter"
 	"github.com/gin-gonic/gin"
 )
 
+// DefaultMaxBodySize Largest body GetBody reads, 1 MiB | GetBody读取的最大请求体，1 MiB
+const DefaultMaxBodySize int64 = 1 << 20
+
 // GinContext Gin request context adapter | Gin请求上下文适配器
 type GinContext struct {
 	c       *gin.Context
@@ -87,9 +92,24 @@ func (g *GinContext) GetPostForm(key string) string {
 	return g.c.PostForm(key)
 }
 
-// GetBody implements adapter.RequestContext.
+// GetBody implements adapter.RequestContext, reading up to DefaultMaxBodySize, the body stays readable for the handler.
 func (g *GinContext) GetBody() ([]byte, error) {
-	return g.c.GetRawData()
+	original := g.c.Request.Body
+	if original == nil {
+		return nil, nil
+	}
+	var read bytes.Buffer
+	body, err := io.ReadAll(http.MaxBytesReader(g.c.Writer, io.NopCloser(io.TeeReader(original, &read)), DefaultMaxBodySize))
+	if err != nil {
+		// Hand back everything consumed, the handler decides about the rest | 交还已读取的全部内容，其余由处理器决定
+		g.c.Request.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(&read, original), original}
+		return nil, err
+	}
+	g.c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
 }
 
 // GetURL implements adapter.RequestContext.
@@ -104,17 +124,7 @@ func (g *GinContext) GetUserAgent() string {
 
 // SetCookieWithOptions implements adapter.RequestContext.
 func (g *GinContext) SetCookieWithOptions(options *adapter.CookieOptions) {
-	g.c.SetCookie(
-		options.Name,
-		options.Value,
-		options.MaxAge,
-		options.Path,
-		options.Domain,
-		options.Secure,
-		options.HttpOnly,
-	)
-	
-	// Set SameSite attribute
+	// SameSite must be set before SetCookie, gin reads it when writing the cookie
 	switch options.SameSite {
 	case "Strict":
 		g.c.SetSameSite(http.SameSiteStrictMode)
@@ -123,6 +133,16 @@ func (g *GinContext) SetCookieWithOptions(options *adapter.CookieOptions) {
 	case "None":
 		g.c.SetSameSite(http.SameSiteNoneMode)
 	}
+
+	g.c.SetCookie(
+		options.Name,
+		options.Value,
+		options.HTTPMaxAge(),
+		options.Path,
+		options.Domain,
+		options.Secure,
+		options.HttpOnly,
+	)
 }
 
 // GetString implements adapter.RequestContext.
@@ -1,94 +1,109 @@
 package gin
 
 import (
-	"errors"
 	"net/http"
 
-	"suwei.sa_token/core"
 	"github.com/gin-gonic/gin"
+	"suwei.sa_token/core"
 )
 
 // Plugin Gin plugin for Sa-Token | Gin插件
 type Plugin struct {
 	manager *core.Manager
+	engine  *core.MiddlewareEngine
 }
 
 // NewPlugin creates a Gin plugin | 创建Gin插件
 func NewPlugin(manager *core.Manager) *Plugin {
 	return &Plugin{
 		manager: manager,
+		engine:  core.NewMiddlewareEngine(manager),
 	}
 }
 
+// SetErrorRenderer overrides the global error renderer for this plugin | 为此插件覆盖全局错误渲染器
+func (p *Plugin) SetErrorRenderer(renderer core.ErrorRenderer) *Plugin {
+	p.engine.SetErrorRenderer(renderer)
+	return p
+}
+
 // AuthMiddleware authentication middleware | 认证中间件
 func (p *Plugin) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx := NewGinContext(c)
-		saCtx := core.NewContext(ctx, p.manager)
-
-		// Check login | 检查登录
-		if err := saCtx.CheckLogin(); err != nil {
-			writeErrorResponse(c, err)
-			c.Abort()
-			return
-		}
-
-		// Store Sa-Token context in Gin context | 将Sa-Token上下文存储到Gin上下文
-		c.Set("satoken", saCtx)
-		c.Next()
+		saCtx, err := p.engine.CheckLogin(NewGinContext(c))
+		p.proceed(c, saCtx, err)
 	}
 }
 
 // PermissionRequired permission validation middleware | 权限验证中间件
 func (p *Plugin) PermissionRequired(permission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx := NewGinContext(c)
-		saCtx := core.NewContext(ctx, p.manager)
-
-		// Check login | 检查登录
-		if err := saCtx.CheckLogin(); err != nil {
-			writeErrorResponse(c, err)
-			c.Abort()
-			return
-		}
-
-		// Check permission | 检查权限
-		if !saCtx.HasPermission(permission) {
-			writeErrorResponse(c, core.NewPermissionDeniedError(permission))
-			c.Abort()
-			return
-		}
-
-		c.Set("satoken", saCtx)
-		c.Next()
+		saCtx, err := p.engine.CheckPermission(NewGinContext(c), permission)
+		p.proceed(c, saCtx, err)
 	}
 }
 
 // RoleRequired role validation middleware | 角色验证中间件
 func (p *Plugin) RoleRequired(role string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		ctx := NewGinContext(c)
-		saCtx := core.NewContext(ctx, p.manager)
+		saCtx, err := p.engine.CheckRole(NewGinContext(c), role)
+		p.proceed(c, saCtx, err)
+	}
+}
 
-		// Check login | 检查登录
-		if err := saCtx.CheckLogin(); err != nil {
-			writeErrorResponse(c, err)
-			c.Abort()
-			return
-		}
+// AuthorizeRequired policy authorization middleware | 策略授权中间件
+// resolver builds the resource from the request, e.g. from route params | resolver 从请求构建资源，例如从路由参数
+func (p *Plugin) AuthorizeRequired(action string, resolver func(c *gin.Context) (*core.PolicyResource, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		saCtx, err := p.engine.Authorize(NewGinContext(c), action, func() (*core.PolicyResource, error) {
+			return resolver(c)
+		})
+		p.proceed(c, saCtx, err)
+	}
+}
 
-		// Check role | 检查角色
-		if !saCtx.HasRole(role) {
-			writeErrorResponse(c, core.NewRoleDeniedError(role))
+// ACLRequired resource ACL middleware, reads the resource ID from a route parameter | 资源ACL中间件，从路由参数读取资源ID
+func (p *Plugin) ACLRequired(resourceType, param, action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		saCtx, err := p.engine.CheckAccess(NewGinContext(c), resourceType, c.Param(param), action)
+		p.proceed(c, saCtx, err)
+	}
+}
+
+// CSRFRequired CSRF middleware, checks unsafe requests authenticated by cookie | CSRF中间件，检查通过Cookie认证的非安全请求
+func (p *Plugin) CSRFRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		saCtx, err := p.engine.CheckCSRF(NewGinContext(c))
+		p.proceed(c, saCtx, err)
+	}
+}
+
+// RouterMiddleware global route rule middleware, mount once with engine.Use | 全局路由规则中间件，通过 engine.Use 挂载一次
+func (p *Plugin) RouterMiddleware(r *core.Router) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := p.engine.CheckRouter(NewGinContext(c), r); err != nil {
+			p.writeError(c, err)
 			c.Abort()
 			return
 		}
-
-		c.Set("satoken", saCtx)
 		c.Next()
 	}
 }
 
+// proceed continues the chain with the checked context or aborts with the rendered error |
+// 使用检查后的上下文继续执行，或以渲染后的错误中止
+func (p *Plugin) proceed(c *gin.Context, saCtx *core.SaTokenContext, err error) {
+	if err != nil {
+		p.writeError(c, err)
+		c.Abort()
+		return
+	}
+
+	// Store Sa-Token context in Gin context | 将Sa-Token上下文存储到Gin上下文
+	c.Set("satoken", saCtx)
+	c.Next()
+}
+
 // LoginHandler login handler example | 登录处理器示例
 func (p *Plugin) LoginHandler(c *gin.Context) {
 	var req struct {
@@ -98,7 +113,7 @@ func (p *Plugin) LoginHandler(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		writeErrorResponse(c, core.NewError(core.CodeBadRequest, "invalid request parameters", err))
+		p.writeError(c, core.NewError(core.CodeBadRequest, "invalid request parameters", err))
 		return
 	}
 
@@ -111,48 +126,29 @@ func (p *Plugin) LoginHandler(c *gin.Context) {
 		device = "default"
 	}
 
-	token, err := p.manager.Login(req.Username, device)
+	// Issued through the context so the response header and cookie settings apply | 通过上下文签发，使响应头和Cookie配置生效
+	token, err := core.NewContext(NewGinContext(c), p.manager).Login(req.Username, device)
 	if err != nil {
-		writeErrorResponse(c, core.NewError(core.CodeServerError, "login failed", err))
+		p.writeError(c, core.NewError(core.CodeServerError, "login failed", err))
 		return
 	}
 
-	// Set cookie (optional) | 设置Cookie（可选）
-	cfg := p.manager.GetConfig()
-	if cfg.IsReadCookie {
-		maxAge := int(cfg.Timeout)
-		if maxAge < 0 {
-			maxAge = 0
-		}
-		c.SetCookie(
-			cfg.TokenName,
-			token,
-			maxAge,
-			cfg.CookieConfig.Path,
-			cfg.CookieConfig.Domain,
-			cfg.CookieConfig.Secure,
-			cfg.CookieConfig.HttpOnly,
-		)
-	}
-
 	writeSuccessResponse(c, gin.H{
 		"token": token,
 	})
 }
 
-// LogoutHandler logout handler | 登出处理器
+// LogoutHandler logout handler, logs out the current token and clears its cookie | 登出处理器，注销当前Token并清除Cookie
 func (p *Plugin) LogoutHandler(c *gin.Context) {
-	ctx := NewGinContext(c)
-	saCtx := core.NewContext(ctx, p.manager)
+	saCtx := core.NewContext(NewGinContext(c), p.manager)
 
-	loginID, err := saCtx.GetLoginID()
-	if err != nil {
-		writeErrorResponse(c, err)
+	if err := saCtx.CheckLogin(); err != nil {
+		p.writeError(c, err)
 		return
 	}
 
-	if err := p.manager.Logout(loginID); err != nil {
-		writeErrorResponse(c, core.NewError(core.CodeServerError, "logout failed", err))
+	if err := saCtx.Logout(); err != nil {
+		p.writeError(c, core.NewError(core.CodeServerError, "logout failed", err))
 		return
 	}
 
@@ -168,7 +164,7 @@ func (p *Plugin) UserInfoHandler(c *gin.Context) {
 
 	loginID, err := saCtx.GetLoginID()
 	if err != nil {
-		writeErrorResponse(c, err)
+		p.writeError(c, err)
 		return
 	}
 
@@ -195,30 +191,19 @@ func GetSaToken(c *gin.Context) (*core.SaTokenContext, bool) {
 
 // ============ Error Handling Helpers | 错误处理辅助函数 ============
 
-// writeErrorResponse writes a standardized error response | 写入标准化的错误响应
+// writeErrorResponse writes err with the global error renderer | 使用全局错误渲染器写入错误
 func writeErrorResponse(c *gin.Context, err error) {
-	var saErr *core.SaTokenError
-	var code int
-	var message string
-	var httpStatus int
-
-	// Check if it's a SaTokenError | 检查是否为SaTokenError
-	if errors.As(err, &saErr) {
-		code = saErr.Code
-		message = saErr.Message
-		httpStatus = getHTTPStatusFromCode(code)
-	} else {
-		// Handle standard errors | 处理标准错误
-		code = core.CodeServerError
-		message = err.Error()
-		httpStatus = http.StatusInternalServerError
-	}
-
-	c.JSON(httpStatus, gin.H{
-		"code":    code,
-		"message": message,
-		"error":   err.Error(),
-	})
+	writeRendered(c, core.RenderError(NewGinContext(c), err))
+}
+
+// writeError writes err with the plugin's error renderer | 使用插件的错误渲染器写入错误
+func (p *Plugin) writeError(c *gin.Context, err error) {
+	writeRendered(c, p.engine.Render(NewGinContext(c), err))
+}
+
+// writeRendered writes a rendered error response | 写入渲染后的错误响应
+func writeRendered(c *gin.Context, resp *core.ErrorResponse) {
+	c.Data(resp.Status, resp.ContentType, resp.Body)
 }
 
 // writeSuccessResponse writes a standardized success response | 写入标准化的成功响应
@@ -229,21 +214,3 @@ func writeSuccessResponse(c *gin.Context, data interface{}) {
 		"data":    data,
 	})
 }
-
-// getHTTPStatusFromCode converts Sa-Token error code to HTTP status | 将Sa-Token错误码转换为HTTP状态码
-func getHTTPStatusFromCode(code int) int {
-	switch code {
-	case core.CodeNotLogin:
-		return http.StatusUnauthorized
-	case core.CodePermissionDenied:
-		return http.StatusForbidden
-	case core.CodeBadRequest:
-		return http.StatusBadRequest
-	case core.CodeNotFound:
-		return http.StatusNotFound
-	case core.CodeServerError:
-		return http.StatusInternalServerError
-	default:
-		return http.StatusInternalServerError
-	}
-}
@@ -0,0 +1,30 @@
+package grpc
+
+import (
+	"suwei.sa_token/core"
+	"suwei.sa_token/core/annotation"
+)
+
+// Annotation constants | 注解常量
+const (
+	TagSaCheckLogin      = annotation.TagSaCheckLogin
+	TagSaCheckRole       = annotation.TagSaCheckRole
+	TagSaCheckPermission = annotation.TagSaCheckPermission
+	TagSaCheckDisable    = annotation.TagSaCheckDisable
+	TagSaCheckSafe       = annotation.TagSaCheckSafe
+	TagSaIgnore          = annotation.TagSaIgnore
+)
+
+// Annotation annotation structure | 注解结构体
+type Annotation = core.Annotation
+
+// Annotation mode constants | 注解模式常量
+const (
+	ModeAnd = core.AnnotationModeAnd
+	ModeOr  = core.AnnotationModeOr
+)
+
+// ParseTag parses struct tags | 解析结构体标签
+func ParseTag(tag string) *Annotation {
+	return core.ParseAnnotationTag(tag)
+}
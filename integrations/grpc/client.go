@@ -0,0 +1,63 @@
+package grpc
+
+import (
+	"context"
+
+	grpcfw "google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// authorizationKey Metadata key of bearer tokens | Bearer Token的metadata键
+const authorizationKey = "authorization"
+
+// TokenSource provides the token of an outgoing call, empty means no token |
+// 提供外发调用的Token，为空表示不携带Token
+type TokenSource func(ctx context.Context) (string, error)
+
+// StaticToken token source returning a fixed token | 返回固定Token的Token来源
+func StaticToken(token string) TokenSource {
+	return func(context.Context) (string, error) {
+		return token, nil
+	}
+}
+
+// UnaryClientInterceptor attaches the token to unary calls | 为一元调用附加Token
+// Without key the token is sent as "authorization: Bearer <token>", otherwise as "<key>: <token>" |
+// 未指定key时以 "authorization: Bearer <token>" 发送，否则以 "<key>: <token>" 发送
+func UnaryClientInterceptor(source TokenSource, key ...string) grpcfw.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpcfw.ClientConn, invoker grpcfw.UnaryInvoker, opts ...grpcfw.CallOption) error {
+		ctx, err := attachToken(ctx, source, key...)
+		if err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor attaches the token to streaming calls | 为流式调用附加Token
+func StreamClientInterceptor(source TokenSource, key ...string) grpcfw.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpcfw.StreamDesc, cc *grpcfw.ClientConn, method string, streamer grpcfw.Streamer, opts ...grpcfw.CallOption) (grpcfw.ClientStream, error) {
+		ctx, err := attachToken(ctx, source, key...)
+		if err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+// WithToken attaches a bearer token to a single outgoing call | 为单次外发调用附加Bearer Token
+func WithToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, authorizationKey, "Bearer "+token)
+}
+
+// attachToken appends the token to outgoing metadata | 将Token追加到外发metadata
+func attachToken(ctx context.Context, source TokenSource, key ...string) (context.Context, error) {
+	token, err := source(ctx)
+	if err != nil || token == "" {
+		return ctx, err
+	}
+	if len(key) > 0 && key[0] != "" {
+		return metadata.AppendToOutgoingContext(ctx, key[0], token), nil
+	}
+	return WithToken(ctx, token), nil
+}
@@ -0,0 +1,296 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"suwei.sa_token/core"
+	"suwei.sa_token/core/adapter"
+)
+
+// contextKey Private type for context keys | 上下文键的私有类型
+type contextKey struct{}
+
+// saTokenKey Key of SaTokenContext in the call context | SaTokenContext在调用上下文中的键
+var saTokenKey = contextKey{}
+
+// ContextOptions Settings of GRPCContext | GRPCContext设置
+type ContextOptions struct {
+	// TrustedProxies Networks whose x-real-ip and x-forwarded-for metadata are believed, none by default |
+	// 信任其 x-real-ip 和 x-forwarded-for metadata 的网络，默认不信任任何网络
+	TrustedProxies []*net.IPNet
+}
+
+// ParseTrustedProxies Parses IP addresses and CIDRs of trusted proxies | 解析受信任代理的IP地址和CIDR
+func ParseTrustedProxies(proxies ...string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy: %q", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy: %w", err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// GRPCContext gRPC call context adapter, headers are read from incoming metadata |
+// gRPC调用上下文适配器，请求头从传入的metadata读取
+//
+// gRPC has no query, form, cookie or body concept, those getters return empty values.
+// The path is the full method name ("/package.Service/Method") and the method is always POST,
+// so route rules written with core.Router work unchanged.
+// gRPC没有查询参数、表单、Cookie和请求体的概念，这些方法返回空值。
+// 路径为完整方法名（"/package.Service/Method"），方法固定为POST，因此core.Router路由规则可直接复用。
+type GRPCContext struct {
+	ctx        context.Context
+	fullMethod string
+	md         metadata.MD
+	header     metadata.MD
+	options    ContextOptions
+	mu         sync.RWMutex
+	values     map[string]any
+	aborted    bool
+}
+
+// NewGRPCContext creates a gRPC context adapter | 创建gRPC上下文适配器
+func NewGRPCContext(ctx context.Context, fullMethod string) adapter.RequestContext {
+	return newGRPCContext(ctx, fullMethod, ContextOptions{})
+}
+
+// NewGRPCContextWithOptions creates a gRPC context adapter | 创建gRPC上下文适配器
+func NewGRPCContextWithOptions(ctx context.Context, fullMethod string, options ContextOptions) adapter.RequestContext {
+	return newGRPCContext(ctx, fullMethod, options)
+}
+
+// newGRPCContext creates the concrete adapter | 创建具体适配器
+func newGRPCContext(ctx context.Context, fullMethod string, options ContextOptions) *GRPCContext {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		md = metadata.MD{}
+	}
+	return &GRPCContext{
+		ctx:        ctx,
+		fullMethod: fullMethod,
+		md:         md,
+		header:     metadata.MD{},
+		options:    options,
+		values:     make(map[string]any),
+	}
+}
+
+// GetHeader gets metadata value, keys are case-insensitive | 获取metadata值，键不区分大小写
+func (c *GRPCContext) GetHeader(key string) string {
+	if values := c.md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// GetHeaders gets all metadata | 获取所有metadata
+func (c *GRPCContext) GetHeaders() map[string][]string {
+	headers := make(map[string][]string, len(c.md))
+	for key, values := range c.md {
+		headers[key] = values
+	}
+	return headers
+}
+
+// GetQuery gRPC has no query parameters | gRPC没有查询参数
+func (c *GRPCContext) GetQuery(key string) string {
+	return ""
+}
+
+// GetQueryAll gRPC has no query parameters | gRPC没有查询参数
+func (c *GRPCContext) GetQueryAll() map[string][]string {
+	return map[string][]string{}
+}
+
+// GetPostForm gRPC has no form parameters | gRPC没有表单参数
+func (c *GRPCContext) GetPostForm(key string) string {
+	return ""
+}
+
+// GetCookie gRPC has no cookies | gRPC没有Cookie
+func (c *GRPCContext) GetCookie(key string) string {
+	return ""
+}
+
+// GetBody gRPC messages are decoded by the handler | gRPC消息由处理器解码
+func (c *GRPCContext) GetBody() ([]byte, error) {
+	return nil, nil
+}
+
+// GetClientIP gets client IP address without port, forwarding metadata only counts from trusted proxies |
+// 获取不含端口的客户端IP地址，转发metadata仅在来自受信任代理时生效
+func (c *GRPCContext) GetClientIP() string {
+	var remote string
+	if p, ok := peer.FromContext(c.ctx); ok && p.Addr != nil {
+		remote = p.Addr.String()
+		if host, _, err := net.SplitHostPort(remote); err == nil {
+			remote = host
+		}
+	}
+	if !c.isTrustedProxy(remote) {
+		return remote
+	}
+
+	if ip := strings.TrimSpace(c.GetHeader("x-real-ip")); net.ParseIP(ip) != nil {
+		return ip
+	}
+	// Walk the chain from the nearest hop, the first untrusted address is the client |
+	// 从最近的一跳向前遍历，第一个不受信任的地址即为客户端
+	hops := strings.Split(strings.Join(c.md.Get("x-forwarded-for"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(hops[i])
+		if net.ParseIP(ip) == nil {
+			break
+		}
+		if !c.isTrustedProxy(ip) {
+			return ip
+		}
+		remote = ip
+	}
+	return remote
+}
+
+// isTrustedProxy checks if ip belongs to a trusted proxy | 检查IP是否属于受信任代理
+func (c *GRPCContext) isTrustedProxy(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range c.options.TrustedProxies {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetMethod gets request method, gRPC calls are always POST | 获取请求方法，gRPC调用固定为POST
+func (c *GRPCContext) GetMethod() string {
+	return http.MethodPost
+}
+
+// GetPath gets full method name | 获取完整方法名
+func (c *GRPCContext) GetPath() string {
+	return c.fullMethod
+}
+
+// GetURL gets full method name | 获取完整方法名
+func (c *GRPCContext) GetURL() string {
+	return c.fullMethod
+}
+
+// GetUserAgent gets User-Agent | 获取User-Agent
+func (c *GRPCContext) GetUserAgent() string {
+	return c.GetHeader("user-agent")
+}
+
+// SetHeader sets response header metadata, sent by the interceptor | 设置响应头metadata，由拦截器发送
+func (c *GRPCContext) SetHeader(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.header.Set(key, value)
+}
+
+// SetCookie gRPC has no cookies | gRPC没有Cookie
+func (c *GRPCContext) SetCookie(name, value string, maxAge int, path, domain string, secure, httpOnly bool) {
+}
+
+// SetCookieWithOptions gRPC has no cookies | gRPC没有Cookie
+func (c *GRPCContext) SetCookieWithOptions(options *adapter.CookieOptions) {
+}
+
+// Set sets context value | 设置上下文值
+func (c *GRPCContext) Set(key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key] = value
+}
+
+// Get gets context value | 获取上下文值
+func (c *GRPCContext) Get(key string) (any, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, exists := c.values[key]
+	return value, exists
+}
+
+// GetString gets string context value | 获取字符串上下文值
+func (c *GRPCContext) GetString(key string) string {
+	if value, exists := c.Get(key); exists {
+		if str, ok := value.(string); ok {
+			return str
+		}
+	}
+	return ""
+}
+
+// MustGet gets context value, panics if missing | 获取上下文值，不存在则panic
+func (c *GRPCContext) MustGet(key string) any {
+	value, exists := c.Get(key)
+	if !exists {
+		panic("key not found: " + key)
+	}
+	return value
+}
+
+// Abort aborts the call | 中止调用
+func (c *GRPCContext) Abort() {
+	c.aborted = true
+}
+
+// IsAborted checks if the call is aborted | 检查调用是否已中止
+func (c *GRPCContext) IsAborted() bool {
+	return c.aborted
+}
+
+// responseHeader gets header metadata set during checks | 获取检查过程中设置的响应头metadata
+func (c *GRPCContext) responseHeader() metadata.MD {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.header.Copy()
+}
+
+// ============ Call Context Helpers | 调用上下文辅助函数 ============
+
+// WithSaToken returns a copy of ctx carrying the Sa-Token context | 返回携带Sa-Token上下文的ctx副本
+func WithSaToken(ctx context.Context, saCtx *core.SaTokenContext) context.Context {
+	return context.WithValue(ctx, saTokenKey, saCtx)
+}
+
+// FromContext gets Sa-Token context inside a handler | 在处理器中获取Sa-Token上下文
+func FromContext(ctx context.Context) (*core.SaTokenContext, bool) {
+	saCtx, ok := ctx.Value(saTokenKey).(*core.SaTokenContext)
+	return saCtx, ok
+}
+
+// GetLoginIDFromContext gets login ID of an authenticated call | 获取已认证调用的登录ID
+func GetLoginIDFromContext(ctx context.Context) (string, bool) {
+	saCtx, ok := FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	loginID, err := saCtx.GetLoginID()
+	return loginID, err == nil
+}
@@ -0,0 +1,53 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestGetClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies("10.0.0.0/8", "192.168.1.1")
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies() error = %v", err)
+	}
+	if _, err := ParseTrustedProxies("proxy.local"); err == nil {
+		t.Error("ParseTrustedProxies() should reject a host name")
+	}
+
+	tests := []struct {
+		name    string
+		remote  string
+		md      []string
+		trusted bool
+		want    string
+	}{
+		{"port stripped", "203.0.113.7:51234", nil, false, "203.0.113.7"},
+		{"ipv6 port stripped", "[2001:db8::1]:443", nil, false, "2001:db8::1"},
+		{"untrusted forwarding ignored", "203.0.113.7:51234", []string{"x-real-ip", "1.2.3.4", "x-forwarded-for", "1.2.3.4"}, true, "203.0.113.7"},
+		{"no proxies trusted by default", "10.0.0.2:80", []string{"x-forwarded-for", "1.2.3.4"}, false, "10.0.0.2"},
+		{"real ip from proxy", "10.0.0.2:80", []string{"x-real-ip", "198.51.100.9"}, true, "198.51.100.9"},
+		{"nearest untrusted hop", "10.0.0.2:80", []string{"x-forwarded-for", "1.2.3.4, 198.51.100.9, 192.168.1.1"}, true, "198.51.100.9"},
+		{"only proxies in chain", "10.0.0.2:80", []string{"x-forwarded-for", "10.0.0.3"}, true, "10.0.0.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, err := net.ResolveTCPAddr("tcp", tt.remote)
+			if err != nil {
+				t.Fatalf("ResolveTCPAddr() error = %v", err)
+			}
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(tt.md...))
+			options := ContextOptions{}
+			if tt.trusted {
+				options.TrustedProxies = proxies
+			}
+			if got := NewGRPCContextWithOptions(ctx, "/svc/Method", options).GetClientIP(); got != tt.want {
+				t.Errorf("GetClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,639 @@
+package grpc
+
+import (
+	"time"
+
+	"suwei.sa_token/core"
+	"suwei.sa_token/stputil"
+)
+
+// ============ Re-export core types | 重新导出核心类型 ============
+
+// Configuration related types | 配置相关类型
+type (
+	Config              = core.Config
+	CookieConfig        = core.CookieConfig
+	TokenBindingConfig  = core.TokenBindingConfig
+	JwtRevocationConfig = core.JwtRevocationConfig
+	BindingPolicy       = core.BindingPolicy
+	TokenStyle          = core.TokenStyle
+	JwtMode             = core.JwtMode
+)
+
+// Token style constants | Token风格常量
+const (
+	TokenStyleUUID           = core.TokenStyleUUID
+	TokenStyleSimple         = core.TokenStyleSimple
+	TokenStyleRandom32       = core.TokenStyleRandom32
+	TokenStyleRandom64       = core.TokenStyleRandom64
+	TokenStyleRandom128      = core.TokenStyleRandom128
+	TokenStyleJWT            = core.TokenStyleJWT
+	TokenStyleHash           = core.TokenStyleHash
+	TokenStyleTimestamp      = core.TokenStyleTimestamp
+	TokenStyleTik            = core.TokenStyleTik
+	TokenStyleULID           = core.TokenStyleULID
+	TokenStyleSnowflake      = core.TokenStyleSnowflake
+	TokenStylePasetoV4Public = core.TokenStylePasetoV4Public
+	TokenStylePasetoV4Local  = core.TokenStylePasetoV4Local
+)
+
+// Token source constants | Token来源常量
+const (
+	TokenSourceHeader = core.TokenSourceHeader
+	TokenSourceCookie = core.TokenSourceCookie
+	TokenSourceBody   = core.TokenSourceBody
+	TokenSourceQuery  = core.TokenSourceQuery
+)
+
+// Token binding policy constants | Token绑定策略常量
+const (
+	BindingPolicyReject = core.BindingPolicyReject
+	BindingPolicySafe   = core.BindingPolicySafe
+	BindingPolicyEvent  = core.BindingPolicyEvent
+)
+
+// JWT mode constants | JWT模式常量
+const (
+	JwtModeSimple    = core.JwtModeSimple
+	JwtModeMixin     = core.JwtModeMixin
+	JwtModeStateless = core.JwtModeStateless
+)
+
+// JWT signing algorithm constants | JWT签名算法常量
+const (
+	JwtAlgHS256 = core.JwtAlgHS256
+	JwtAlgRS256 = core.JwtAlgRS256
+	JwtAlgES256 = core.JwtAlgES256
+	JwtAlgEdDSA = core.JwtAlgEdDSA
+)
+
+// Core types | 核心类型
+type (
+	Manager              = core.Manager
+	TokenInfo            = core.TokenInfo
+	LoginOptions         = core.LoginOptions
+	ClientFingerprint    = core.ClientFingerprint
+	TimedGrant           = core.TimedGrant
+	Session              = core.Session
+	TokenGenerator       = core.TokenGenerator
+	SaTokenContext       = core.SaTokenContext
+	Builder              = core.Builder
+	NonceManager         = core.NonceManager
+	RefreshTokenInfo     = core.RefreshTokenInfo
+	RefreshTokenManager  = core.RefreshTokenManager
+	OAuth2Server         = core.OAuth2Server
+	OAuth2Client         = core.OAuth2Client
+	OAuth2AccessToken    = core.OAuth2AccessToken
+	OAuth2GrantType      = core.OAuth2GrantType
+	PermissionMatcher    = core.PermissionMatcher
+	Policy               = core.Policy
+	PolicyFunc           = core.PolicyFunc
+	PolicyRule           = core.PolicyRule
+	PolicyRequest        = core.PolicyRequest
+	PolicySubject        = core.PolicySubject
+	PolicyResource       = core.PolicyResource
+	PolicyDecision       = core.PolicyDecision
+	ACLStore             = core.ACLStore
+	ACLGrant             = core.ACLGrant
+	Router               = core.Router
+	RouterRule           = core.RouterRule
+	RouterCheckFunc      = core.RouterCheckFunc
+	RealtimeHub          = core.RealtimeHub
+	RealtimeIdentity     = core.RealtimeIdentity
+	RealtimeConn         = core.RealtimeConn
+	RealtimeConnFunc     = core.RealtimeConnFunc
+	HandshakeAuth        = core.HandshakeAuth
+	MiddlewareEngine     = core.MiddlewareEngine
+	SaTokenError         = core.SaTokenError
+	MessageCatalog       = core.MessageCatalog
+	ErrorRenderer        = core.ErrorRenderer
+	ErrorRendererFunc    = core.ErrorRendererFunc
+	ErrorResponse        = core.ErrorResponse
+	JSONErrorRenderer    = core.JSONErrorRenderer
+	ProblemErrorRenderer = core.ProblemErrorRenderer
+	JwtKey               = core.JwtKey
+	JwtKeySet            = core.JwtKeySet
+	JWKS                 = core.JWKS
+	TokenStyleGenerator  = core.TokenStyleGenerator
+)
+
+// Language constants | 语言常量
+const (
+	LangEN = core.LangEN
+	LangZH = core.LangZH
+)
+
+// Policy decision constants | 策略决策常量
+const (
+	PolicyNotApplicable = core.PolicyNotApplicable
+	PolicyAllow         = core.PolicyAllow
+	PolicyDeny          = core.PolicyDeny
+)
+
+// Adapter interfaces | 适配器接口
+type (
+	Storage        = core.Storage
+	RequestContext = core.RequestContext
+)
+
+// Event related types | 事件相关类型
+type (
+	EventListener  = core.EventListener
+	EventManager   = core.EventManager
+	EventData      = core.EventData
+	Event          = core.Event
+	ListenerFunc   = core.ListenerFunc
+	ListenerConfig = core.ListenerConfig
+)
+
+// Event constants | 事件常量
+const (
+	EventLogin             = core.EventLogin
+	EventLogout            = core.EventLogout
+	EventKickout           = core.EventKickout
+	EventDisable           = core.EventDisable
+	EventUntie             = core.EventUntie
+	EventRenew             = core.EventRenew
+	EventCreateSession     = core.EventCreateSession
+	EventDestroySession    = core.EventDestroySession
+	EventPermissionCheck   = core.EventPermissionCheck
+	EventRoleCheck         = core.EventRoleCheck
+	EventBindingMismatch   = core.EventBindingMismatch
+	EventRefreshTokenReuse = core.EventRefreshTokenReuse
+	EventAll               = core.EventAll
+)
+
+// OAuth2 grant type constants | OAuth2授权类型常量
+const (
+	GrantTypeAuthorizationCode = core.GrantTypeAuthorizationCode
+	GrantTypeRefreshToken      = core.GrantTypeRefreshToken
+	GrantTypeClientCredentials = core.GrantTypeClientCredentials
+	GrantTypePassword          = core.GrantTypePassword
+)
+
+// Utility functions | 工具函数
+var (
+	RandomString   = core.RandomString
+	IsEmpty        = core.IsEmpty
+	IsNotEmpty     = core.IsNotEmpty
+	DefaultString  = core.DefaultString
+	ContainsString = core.ContainsString
+	RemoveString   = core.RemoveString
+	UniqueStrings  = core.UniqueStrings
+	MergeStrings   = core.MergeStrings
+	MatchPattern   = core.MatchPattern
+)
+
+// ============ Core constructor functions | 核心构造函数 ============
+
+// DefaultConfig returns default configuration | 返回默认配置
+func DefaultConfig() *Config {
+	return core.DefaultConfig()
+}
+
+// NewManager creates a new authentication manager | 创建新的认证管理器
+func NewManager(storage Storage, cfg *Config) *Manager {
+	return core.NewManager(storage, cfg)
+}
+
+// NewContext creates a new Sa-Token context | 创建新的Sa-Token上下文
+func NewContext(ctx RequestContext, mgr *Manager) *SaTokenContext {
+	return core.NewContext(ctx, mgr)
+}
+
+// NewSession creates a new session | 创建新的Session
+func NewSession(id string, storage Storage, prefix string) *Session {
+	return core.NewSession(id, storage, prefix)
+}
+
+// LoadSession loads an existing session | 加载已存在的Session
+func LoadSession(id string, storage Storage, prefix string) (*Session, error) {
+	return core.LoadSession(id, storage, prefix)
+}
+
+// NewTokenGenerator creates a new token generator | 创建新的Token生成器
+func NewTokenGenerator(cfg *Config) *TokenGenerator {
+	return core.NewTokenGenerator(cfg)
+}
+
+// NewEventManager creates a new event manager | 创建新的事件管理器
+func NewEventManager() *EventManager {
+	return core.NewEventManager()
+}
+
+// NewBuilder creates a new builder for fluent configuration | 创建新的Builder构建器（用于流式配置）
+func NewBuilder() *Builder {
+	return core.NewBuilder()
+}
+
+// NewNonceManager creates a new nonce manager | 创建新的Nonce管理器
+func NewNonceManager(storage Storage, prefix string, ttl ...int64) *NonceManager {
+	return core.NewNonceManager(storage, prefix, ttl...)
+}
+
+// NewRefreshTokenManager creates a new refresh token manager | 创建新的刷新令牌管理器
+func NewRefreshTokenManager(storage Storage, prefix string, cfg *Config) *RefreshTokenManager {
+	return core.NewRefreshTokenManager(storage, prefix, cfg)
+}
+
+// NewPolicyResource creates a policy resource | 创建策略资源
+func NewPolicyResource(resourceType, id string, attributes map[string]any) *PolicyResource {
+	return core.NewPolicyResource(resourceType, id, attributes)
+}
+
+// NewRouter creates a new route rule engine | 创建新的路由规则引擎
+func NewRouter(mgr *Manager) *Router {
+	return core.NewRouter(mgr)
+}
+
+// NewHandshakeAuth creates a WebSocket/SSE handshake authenticator | 创建WebSocket/SSE握手认证器
+func NewHandshakeAuth(mgr *Manager) *HandshakeAuth {
+	return core.NewHandshakeAuth(mgr)
+}
+
+// NewRealtimeHub creates a hub closing live connections on logout, kickout and disable |
+// 创建在登出、踢下线和封禁时关闭存活连接的连接中心
+func NewRealtimeHub(mgr *Manager) *RealtimeHub {
+	return core.NewRealtimeHub(mgr)
+}
+
+// NewMiddlewareEngine creates a framework-agnostic middleware engine | 创建与框架无关的中间件引擎
+func NewMiddlewareEngine(mgr *Manager) *MiddlewareEngine {
+	return core.NewMiddlewareEngine(mgr)
+}
+
+// SetErrorRenderer sets the global error renderer used by all middlewares | 设置所有中间件使用的全局错误渲染器
+func SetErrorRenderer(renderer ErrorRenderer) {
+	core.SetErrorRenderer(renderer)
+}
+
+// GetMessageCatalog gets the global catalog of localized error messages | 获取全局本地化错误消息目录
+func GetMessageCatalog() *MessageCatalog {
+	return core.GetMessageCatalog()
+}
+
+// RegisterTokenGenerator registers the generator of a token style | 注册某种Token风格的生成器
+func RegisterTokenGenerator(style TokenStyle, gen TokenStyleGenerator) error {
+	return core.RegisterTokenGenerator(style, gen)
+}
+
+// NewRandomTokenGenerator creates a generator of random tokens with a given length and charset | 创建指定长度和字符集的随机Token生成器
+func NewRandomTokenGenerator(length int, charset string) TokenStyleGenerator {
+	return core.NewRandomTokenGenerator(length, charset)
+}
+
+// NewJwtKeySet creates an empty JWT key set | 创建空的JWT密钥集
+func NewJwtKeySet() *JwtKeySet {
+	return core.NewJwtKeySet()
+}
+
+// GenerateJwtKey generates an RS256, ES256 or EdDSA signing key | 生成RS256、ES256或EdDSA签名密钥
+func GenerateJwtKey(id string, algorithm string) (*JwtKey, error) {
+	return core.GenerateJwtKey(id, algorithm)
+}
+
+// ParseJWKS builds a verification-only key set from a JWKS document | 从JWKS文档构建仅用于验证的密钥集
+func ParseJWKS(data []byte) (*JwtKeySet, error) {
+	return core.ParseJWKS(data)
+}
+
+// NewACLStore creates a new ACL store | 创建新的ACL存储
+func NewACLStore(storage Storage, prefix string) *ACLStore {
+	return core.NewACLStore(storage, prefix)
+}
+
+// ACLUserSubject builds ACL subject for a login ID | 构建登录ID的ACL主体
+func ACLUserSubject(loginID string) string {
+	return core.ACLUserSubject(loginID)
+}
+
+// ACLRoleSubject builds ACL subject for a role | 构建角色的ACL主体
+func ACLRoleSubject(role string) string {
+	return core.ACLRoleSubject(role)
+}
+
+// NewOAuth2Server creates a new OAuth2 server | 创建新的OAuth2服务器
+func NewOAuth2Server(storage Storage, prefix string) *OAuth2Server {
+	return core.NewOAuth2Server(storage, prefix)
+}
+
+// ============ Global StpUtil functions | 全局StpUtil函数 ============
+
+// SetManager sets the global Manager (must be called first) | 设置全局Manager（必须先调用此方法）
+func SetManager(mgr *Manager) {
+	stputil.SetManager(mgr)
+}
+
+// GetManager gets the global Manager | 获取全局Manager
+func GetManager() *Manager {
+	return stputil.GetManager()
+}
+
+// ============ Authentication | 登录认证 ============
+
+// Login performs user login | 用户登录
+func Login(loginID interface{}, device ...string) (string, error) {
+	return stputil.Login(loginID, device...)
+}
+
+// LoginWithOptions performs user login with per-login options | 使用单次登录选项登录
+func LoginWithOptions(loginID interface{}, opts *LoginOptions) (string, error) {
+	return stputil.LoginWithOptions(loginID, opts)
+}
+
+// LoginByToken performs login with specified token | 使用指定Token登录
+func LoginByToken(loginID interface{}, tokenValue string, device ...string) error {
+	return stputil.LoginByToken(loginID, tokenValue, device...)
+}
+
+// Logout performs user logout | 用户登出
+func Logout(loginID interface{}, device ...string) error {
+	return stputil.Logout(loginID, device...)
+}
+
+// LogoutByToken performs logout by token | 根据Token登出
+func LogoutByToken(tokenValue string) error {
+	return stputil.LogoutByToken(tokenValue)
+}
+
+// IsLogin checks if the user is logged in | 检查用户是否已登录
+func IsLogin(tokenValue string) bool {
+	return stputil.IsLogin(tokenValue)
+}
+
+// CheckLoginByToken checks login status (throws error if not logged in) | 检查登录状态（未登录抛出错误）
+func CheckLoginByToken(tokenValue string) error {
+	return stputil.CheckLogin(tokenValue)
+}
+
+// GetLoginID gets the login ID from token | 从Token获取登录ID
+func GetLoginID(tokenValue string) (string, error) {
+	return stputil.GetLoginID(tokenValue)
+}
+
+// GetLoginIDNotCheck gets login ID without checking | 获取登录ID（不检查）
+func GetLoginIDNotCheck(tokenValue string) (string, error) {
+	return stputil.GetLoginIDNotCheck(tokenValue)
+}
+
+// GetTokenValue gets the token value for a login ID | 获取登录ID对应的Token值
+func GetTokenValue(loginID interface{}, device ...string) (string, error) {
+	return stputil.GetTokenValue(loginID, device...)
+}
+
+// GetTokenInfo gets token information | 获取Token信息
+func GetTokenInfo(tokenValue string) (*TokenInfo, error) {
+	return stputil.GetTokenInfo(tokenValue)
+}
+
+// GetTokenTimeout gets the timeout a token was issued with in seconds | 获取Token签发时的超时时间（秒）
+func GetTokenTimeout(tokenValue string) int64 {
+	return stputil.GetTokenTimeout(tokenValue)
+}
+
+// ============ Kickout | 踢人下线 ============
+
+// Kickout kicks out a user session | 踢人下线
+func Kickout(loginID interface{}, device ...string) error {
+	return stputil.Kickout(loginID, device...)
+}
+
+// ============ Account Disable | 账号封禁 ============
+
+// Disable disables an account for specified duration | 封禁账号（指定时长）
+func Disable(loginID interface{}, duration time.Duration) error {
+	return stputil.Disable(loginID, duration)
+}
+
+// IsDisable checks if an account is disabled | 检查账号是否被封禁
+func IsDisable(loginID interface{}) bool {
+	return stputil.IsDisable(loginID)
+}
+
+// CheckDisable checks if account is disabled (throws error if disabled) | 检查账号是否被封禁（被封禁则抛出错误）
+func CheckDisableByToken(tokenValue string) error {
+	return stputil.CheckDisable(tokenValue)
+}
+
+// GetDisableTime gets remaining disabled time | 获取账号剩余封禁时间
+func GetDisableTime(loginID interface{}) (int64, error) {
+	return stputil.GetDisableTime(loginID)
+}
+
+// Untie unties/unlocks an account | 解除账号封禁
+func Untie(loginID interface{}) error {
+	return stputil.Untie(loginID)
+}
+
+// ============ Permission Check | 权限验证 ============
+
+// CheckPermission checks if the account has specified permission | 检查账号是否拥有指定权限
+func CheckPermissionByToken(tokenValue string, permission string) error {
+	return stputil.CheckPermission(tokenValue, permission)
+}
+
+// HasPermission checks if the account has specified permission (returns bool) | 检查账号是否拥有指定权限（返回布尔值）
+func HasPermission(loginID interface{}, permission string) bool {
+	return stputil.HasPermission(loginID, permission)
+}
+
+// CheckPermissionAnd checks if the account has all specified permissions (AND logic) | 检查账号是否拥有所有指定权限（AND逻辑）
+func CheckPermissionAndByToken(tokenValue string, permissions []string) error {
+	return stputil.CheckPermissionAnd(tokenValue, permissions)
+}
+
+// CheckPermissionOr checks if the account has any of the specified permissions (OR logic) | 检查账号是否拥有指定权限中的任意一个（OR逻辑）
+func CheckPermissionOrByToken(tokenValue string, permissions []string) error {
+	return stputil.CheckPermissionOr(tokenValue, permissions)
+}
+
+// GetPermissionList gets the permission list for an account | 获取账号的权限列表
+func GetPermissionListByToken(tokenValue string) ([]string, error) {
+	return stputil.GetPermissionList(tokenValue)
+}
+
+// ============ Role Check | 角色验证 ============
+
+// CheckRole checks if the account has specified role | 检查账号是否拥有指定角色
+func CheckRoleByToken(tokenValue string, role string) error {
+	return stputil.CheckRole(tokenValue, role)
+}
+
+// HasRole checks if the account has specified role (returns bool) | 检查账号是否拥有指定角色（返回布尔值）
+func HasRole(loginID interface{}, role string) bool {
+	return stputil.HasRole(loginID, role)
+}
+
+// CheckRoleAnd checks if the account has all specified roles (AND logic) | 检查账号是否拥有所有指定角色（AND逻辑）
+func CheckRoleAndByToken(tokenValue string, roles []string) error {
+	return stputil.CheckRoleAnd(tokenValue, roles)
+}
+
+// CheckRoleOr checks if the account has any of the specified roles (OR logic) | 检查账号是否拥有指定角色中的任意一个（OR逻辑）
+func CheckRoleOrByToken(tokenValue string, roles []string) error {
+	return stputil.CheckRoleOr(tokenValue, roles)
+}
+
+// GetRoleList gets the role list for an account | 获取账号的角色列表
+func GetRoleListByToken(tokenValue string) ([]string, error) {
+	return stputil.GetRoleList(tokenValue)
+}
+
+// ============ Time-limited Grants | 限时授权 ============
+
+// GrantPermission grants a permission for duration | 限时授予权限
+func GrantPermission(loginID interface{}, permission string, duration time.Duration) error {
+	return stputil.GrantPermission(loginID, permission, duration)
+}
+
+// RevokePermissionGrant revokes a time-limited permission | 撤销限时权限
+func RevokePermissionGrant(loginID interface{}, permission string) error {
+	return stputil.RevokePermissionGrant(loginID, permission)
+}
+
+// ListPermissionGrants lists active and expired permission grants | 列出有效和已过期的权限授权
+func ListPermissionGrants(loginID interface{}) ([]TimedGrant, error) {
+	return stputil.ListPermissionGrants(loginID)
+}
+
+// GrantRole grants a role for duration | 限时授予角色
+func GrantRole(loginID interface{}, role string, duration time.Duration) error {
+	return stputil.GrantRole(loginID, role, duration)
+}
+
+// RevokeRoleGrant revokes a time-limited role | 撤销限时角色
+func RevokeRoleGrant(loginID interface{}, role string) error {
+	return stputil.RevokeRoleGrant(loginID, role)
+}
+
+// ListRoleGrants lists active and expired role grants | 列出有效和已过期的角色授权
+func ListRoleGrants(loginID interface{}) ([]TimedGrant, error) {
+	return stputil.ListRoleGrants(loginID)
+}
+
+// ============ Second-level Auth | 二级认证 ============
+
+// OpenSafe opens second-level auth for token | 为Token开启二级认证
+func OpenSafe(tokenValue, service string, duration time.Duration) error {
+	return stputil.OpenSafe(tokenValue, service, duration)
+}
+
+// IsSafe checks second-level auth of token | 检查Token是否处于二级认证状态
+func IsSafe(tokenValue, service string) bool {
+	return stputil.IsSafe(tokenValue, service)
+}
+
+// CheckSafeByToken checks second-level auth (throws error if not open) | 检查二级认证（未开启抛出错误）
+func CheckSafeByToken(tokenValue, service string) error {
+	return stputil.CheckSafe(tokenValue, service)
+}
+
+// GetSafeTime gets remaining second-level auth time in seconds | 获取二级认证剩余时间（秒）
+func GetSafeTime(tokenValue, service string) (int64, error) {
+	return stputil.GetSafeTime(tokenValue, service)
+}
+
+// CloseSafe closes second-level auth | 关闭二级认证
+func CloseSafe(tokenValue, service string) error {
+	return stputil.CloseSafe(tokenValue, service)
+}
+
+// ============ One-time Tickets | 一次性票据 ============
+
+// IssueTicket issues a one-time ticket for WebSocket/SSE handshakes | 签发用于WebSocket/SSE握手的一次性票据
+func IssueTicket(tokenValue string, ttl time.Duration) (string, error) {
+	return stputil.IssueTicket(tokenValue, ttl)
+}
+
+// ConsumeTicket consumes a ticket and returns its token | 消费票据并返回对应的Token
+func ConsumeTicket(ticket string) (string, error) {
+	return stputil.ConsumeTicket(ticket)
+}
+
+// ============ CSRF Tokens | CSRF令牌 ============
+
+// GetCSRFToken gets the CSRF token of a logged-in token, issuing one if missing | 获取已登录Token的CSRF令牌，不存在时签发
+func GetCSRFToken(tokenValue string) (string, error) {
+	return stputil.GetCSRFToken(tokenValue)
+}
+
+// CheckCSRFToken checks a submitted CSRF token against the token's one | 校验提交的CSRF令牌是否与Token的一致
+func CheckCSRFToken(tokenValue, csrfToken string) error {
+	return stputil.CheckCSRFToken(tokenValue, csrfToken)
+}
+
+// ============ Session Management | Session管理 ============
+
+// GetSession gets the session for a login ID | 获取登录ID的Session
+func GetSession(loginID interface{}) (*Session, error) {
+	return stputil.GetSession(loginID)
+}
+
+// GetSessionByToken gets the session by token | 根据Token获取Session
+func GetSessionByToken(tokenValue string) (*Session, error) {
+	return stputil.GetSessionByToken(tokenValue)
+}
+
+// GetTokenSession gets the token session | 获取Token的Session
+func GetTokenSession(tokenValue string) (*Session, error) {
+	return stputil.GetTokenSession(tokenValue)
+}
+
+// ============ Token Renewal | Token续期 ============
+
+// RenewTimeout renews token timeout | 续期Token超时时间
+
+// ============ Security Features | 安全特性 ============
+
+// GenerateNonce generates a new nonce token | 生成新的Nonce令牌
+func GenerateNonce() (string, error) {
+	return stputil.GenerateNonce()
+}
+
+// VerifyNonce verifies a nonce token | 验证Nonce令牌
+func VerifyNonce(nonce string) bool {
+	return stputil.VerifyNonce(nonce)
+}
+
+// LoginWithRefreshToken performs login and returns both access token and refresh token | 登录并返回访问令牌和刷新令牌
+func LoginWithRefreshToken(loginID interface{}, device ...string) (*RefreshTokenInfo, error) {
+	return stputil.LoginWithRefreshToken(loginID, device...)
+}
+
+// RefreshAccessToken refreshes the access token using a refresh token | 使用刷新令牌刷新访问令牌
+func RefreshAccessToken(refreshToken string) (*RefreshTokenInfo, error) {
+	return stputil.RefreshAccessToken(refreshToken)
+}
+
+// RevokeRefreshToken revokes a refresh token | 撤销刷新令牌
+func RevokeRefreshToken(refreshToken string) error {
+	return stputil.RevokeRefreshToken(refreshToken)
+}
+
+// GetRefreshTokenListByLoginID gets the current refresh tokens of an account, optionally of one device | 获取账号当前的刷新令牌，可只获取某一设备的
+func GetRefreshTokenListByLoginID(loginID interface{}, device ...string) ([]*RefreshTokenInfo, error) {
+	return stputil.GetRefreshTokenListByLoginID(loginID, device...)
+}
+
+// RevokeRefreshTokensByLoginID revokes the refresh tokens of an account, optionally of one device | 撤销账号的刷新令牌，可只撤销某一设备的
+func RevokeRefreshTokensByLoginID(loginID interface{}, device ...string) error {
+	return stputil.RevokeRefreshTokensByLoginID(loginID, device...)
+}
+
+// RevokeJTI revokes the token with a jti until exp | 撤销携带该jti的Token直至exp
+func RevokeJTI(jti string, exp time.Time) error {
+	return stputil.RevokeJTI(jti, exp)
+}
+
+// SyncRevocations reloads the local revocation filter from storage | 从存储重新加载本地撤销过滤器
+func SyncRevocations() error {
+	return stputil.SyncRevocations()
+}
+
+// GetOAuth2Server gets the OAuth2 server instance | 获取OAuth2服务器实例
+func GetOAuth2Server() *OAuth2Server {
+	return stputil.GetOAuth2Server()
+}
+
+// Version Sa-Token-Go version | Sa-Token-Go版本
+const Version = core.Version
@@ -0,0 +1,24 @@
+module suwei.sa_token/integrations/grpc
+
+go 1.23.0
+
+require (
+	google.golang.org/grpc v1.75.1
+	suwei.sa_token/core v0.1.2
+	suwei.sa_token/stputil v0.0.0-20251017234446-3cf2bdee68cc
+)
+
+require (
+	github.com/golang-jwt/jwt/v5 v5.2.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	golang.org/x/net v0.41.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	golang.org/x/text v0.26.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250707201910-8d1bb00bc6a7 // indirect
+	google.golang.org/protobuf v1.36.6 // indirect
+)
+
+replace (
+	suwei.sa_token/core => ../../core
+	suwei.sa_token/stputil => ../../stputil
+)
@@ -0,0 +1,262 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	grpcfw "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"suwei.sa_token/core"
+	"suwei.sa_token/core/annotation"
+)
+
+// Plugin gRPC plugin for Sa-Token | gRPC插件
+//
+// Rules are keyed by full method name ("/package.Service/Method") and may use core.MatchPath
+// patterns such as "/package.Service/*". Exact names win over patterns, patterns are tried in
+// registration order, and methods without a rule use the default rule (none by default).
+// 规则以完整方法名（"/package.Service/Method"）为键，可使用 core.MatchPath 模式，如
+// "/package.Service/*"。精确方法名优先于模式，模式按注册顺序匹配，未配置规则的方法使用默认规则（默认无）。
+//
+//	p := sagrpc.NewPlugin(manager).
+//	    TokenKeys("x-token").
+//	    Rule("/user.UserService/*", sagrpc.RequireLogin()).
+//	    Rule("/user.UserService/Delete", sagrpc.RequirePermission("user:delete"))
+//	server := grpc.NewServer(
+//	    grpc.UnaryInterceptor(p.UnaryServerInterceptor()),
+//	    grpc.StreamInterceptor(p.StreamServerInterceptor()),
+//	)
+type Plugin struct {
+	manager     *core.Manager
+	mu          sync.RWMutex
+	tokenKeys   []string
+	exact       map[string][]*Annotation
+	patterns    []methodRule
+	defaultRule []*Annotation
+	options     ContextOptions
+}
+
+// methodRule Pattern rule | 模式规则
+type methodRule struct {
+	pattern     string
+	annotations []*Annotation
+}
+
+// NewPlugin creates a gRPC plugin | 创建gRPC插件
+func NewPlugin(manager *core.Manager) *Plugin {
+	return &Plugin{
+		manager: manager,
+		exact:   make(map[string][]*Annotation),
+	}
+}
+
+// TokenKeys sets extra metadata keys to read the token from, tried before the configured
+// token name and "authorization: Bearer" | 设置读取Token的额外metadata键，优先于配置的Token名称和 "authorization: Bearer"
+func (p *Plugin) TokenKeys(keys ...string) *Plugin {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tokenKeys = keys
+	return p
+}
+
+// SetTrustedProxies trusts x-real-ip and x-forwarded-for only from these IPs or CIDRs, e.g. "10.0.0.0/8" |
+// 仅信任来自这些IP或CIDR的 x-real-ip 和 x-forwarded-for，例如 "10.0.0.0/8"
+func (p *Plugin) SetTrustedProxies(proxies ...string) error {
+	networks, err := ParseTrustedProxies(proxies...)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.options.TrustedProxies = networks
+	return nil
+}
+
+// Rule sets the checks of a method or method pattern | 设置方法或方法模式的检查规则
+func (p *Plugin) Rule(method string, annotations ...*Annotation) *Plugin {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if strings.Contains(method, "*") {
+		p.patterns = append(p.patterns, methodRule{pattern: method, annotations: annotations})
+	} else {
+		p.exact[method] = annotations
+	}
+	return p
+}
+
+// Rules sets several method rules at once | 一次设置多个方法规则
+func (p *Plugin) Rules(rules map[string][]*Annotation) *Plugin {
+	for method, annotations := range rules {
+		p.Rule(method, annotations...)
+	}
+	return p
+}
+
+// DefaultRule sets the checks of methods without a rule | 设置未配置规则方法的检查
+func (p *Plugin) DefaultRule(annotations ...*Annotation) *Plugin {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.defaultRule = annotations
+	return p
+}
+
+// RequireLogin annotation requiring login | 需要登录的注解
+func RequireLogin() *Annotation {
+	return &Annotation{CheckLogin: true}
+}
+
+// RequirePermission annotation requiring all permissions | 需要全部权限的注解
+func RequirePermission(perms ...string) *Annotation {
+	return &Annotation{CheckPermission: perms, PermissionMode: ModeAnd}
+}
+
+// RequirePermissionOr annotation requiring any permission | 需要任一权限的注解
+func RequirePermissionOr(perms ...string) *Annotation {
+	return &Annotation{CheckPermission: perms, PermissionMode: ModeOr}
+}
+
+// RequireRole annotation requiring all roles | 需要全部角色的注解
+func RequireRole(roles ...string) *Annotation {
+	return &Annotation{CheckRole: roles, RoleMode: ModeAnd}
+}
+
+// RequireRoleOr annotation requiring any role | 需要任一角色的注解
+func RequireRoleOr(roles ...string) *Annotation {
+	return &Annotation{CheckRole: roles, RoleMode: ModeOr}
+}
+
+// Public annotation skipping all checks | 跳过所有检查的注解
+func Public() *Annotation {
+	return &Annotation{Ignore: true}
+}
+
+// UnaryServerInterceptor unary server interceptor | 一元服务端拦截器
+func (p *Plugin) UnaryServerInterceptor() grpcfw.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpcfw.UnaryServerInfo, handler grpcfw.UnaryHandler) (any, error) {
+		newCtx, err := p.authorize(ctx, info.FullMethod, grpcfw.SetHeader)
+		if err != nil {
+			return nil, err
+		}
+		return handler(newCtx, req)
+	}
+}
+
+// StreamServerInterceptor stream server interceptor | 流式服务端拦截器
+func (p *Plugin) StreamServerInterceptor() grpcfw.StreamServerInterceptor {
+	return func(srv any, ss grpcfw.ServerStream, info *grpcfw.StreamServerInfo, handler grpcfw.StreamHandler) error {
+		setHeader := func(_ context.Context, md metadata.MD) error {
+			return ss.SetHeader(md)
+		}
+		newCtx, err := p.authorize(ss.Context(), info.FullMethod, setHeader)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: newCtx})
+	}
+}
+
+// authorize runs the method rule and returns the context for the handler | 执行方法规则并返回处理器使用的上下文
+func (p *Plugin) authorize(ctx context.Context, fullMethod string, setHeader func(context.Context, metadata.MD) error) (context.Context, error) {
+	annotations, ok := p.lookup(fullMethod)
+	if !ok || annotation.IsIgnored(annotations...) {
+		return ctx, nil
+	}
+
+	p.mu.RLock()
+	options := p.options
+	p.mu.RUnlock()
+	rc := newGRPCContext(ctx, fullMethod, options)
+	p.resolveToken(rc)
+	saCtx := core.NewContext(rc, p.manager)
+
+	err := core.CheckAnnotations(saCtx, annotations...)
+	if md := rc.responseHeader(); len(md) > 0 {
+		_ = setHeader(ctx, md)
+	}
+	if err != nil {
+		// Localized by the accept-language metadata | 按 accept-language metadata 本地化
+		return ctx, ToStatusError(core.LocalizeError(rc, err))
+	}
+	return WithSaToken(ctx, saCtx), nil
+}
+
+// lookup finds the rule of a method | 查找方法规则
+func (p *Plugin) lookup(fullMethod string) ([]*Annotation, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if annotations, ok := p.exact[fullMethod]; ok {
+		return annotations, true
+	}
+	for _, rule := range p.patterns {
+		if core.MatchPath(rule.pattern, fullMethod) {
+			return rule.annotations, true
+		}
+	}
+	if p.defaultRule != nil {
+		return p.defaultRule, true
+	}
+	return nil, false
+}
+
+// resolveToken copies a token found under TokenKeys to the configured token name | 将TokenKeys中找到的Token复制到配置的Token名称
+func (p *Plugin) resolveToken(rc *GRPCContext) {
+	p.mu.RLock()
+	keys := p.tokenKeys
+	p.mu.RUnlock()
+
+	for _, key := range keys {
+		if token := strings.TrimSpace(rc.GetHeader(key)); token != "" {
+			rc.md.Set(p.manager.GetConfig().TokenName, token)
+			return
+		}
+	}
+}
+
+// serverStream wraps ServerStream to carry the authorized context | 包装ServerStream以携带认证后的上下文
+type serverStream struct {
+	grpcfw.ServerStream
+	ctx context.Context
+}
+
+// Context returns the authorized context | 返回认证后的上下文
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// ============ Error Handling Helpers | 错误处理辅助函数 ============
+
+// ToStatusError converts an error to a gRPC status error | 将错误转换为gRPC状态错误
+func ToStatusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	saErr := core.ToSaTokenError(err)
+	return status.Error(getGRPCCodeFromCode(saErr.Code), saErr.Message)
+}
+
+// getGRPCCodeFromCode converts Sa-Token error code to gRPC status code | 将Sa-Token错误码转换为gRPC状态码
+func getGRPCCodeFromCode(code int) codes.Code {
+	switch code {
+	case core.CodeNotLogin, core.CodeTokenInvalid, core.CodeTokenExpired, core.CodeKickedOut, core.CodeActiveTimeout,
+		core.CodeTokenBindingMismatch:
+		return codes.Unauthenticated
+	case core.CodePermissionDenied, core.CodeAccountDisabled, core.CodeNotSafe, core.CodeCSRFInvalid:
+		return codes.PermissionDenied
+	case core.CodeBadRequest, core.CodeInvalidParameter:
+		return codes.InvalidArgument
+	case core.CodeNotFound:
+		return codes.NotFound
+	case core.CodeMaxLoginCount:
+		return codes.ResourceExhausted
+	default:
+		return codes.Internal
+	}
+}
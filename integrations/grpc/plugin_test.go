@@ -0,0 +1,190 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	grpcfw "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"suwei.sa_token/core"
+)
+
+// mapStorage minimal in-memory adapter.Storage for tests
+type mapStorage struct {
+	mu sync.Mutex
+	m  map[string]any
+}
+
+func (s *mapStorage) Set(key string, value any, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[key] = value
+	return nil
+}
+func (s *mapStorage) Get(key string) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m[key], nil
+}
+func (s *mapStorage) Exists(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.m[key]
+	return ok
+}
+func (s *mapStorage) Delete(keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, k := range keys {
+		delete(s.m, k)
+	}
+	return nil
+}
+func (s *mapStorage) Keys(pattern string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for k := range s.m {
+		if strings.HasPrefix(k, strings.TrimSuffix(pattern, "*")) {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+func (s *mapStorage) Expire(string, time.Duration) error { return nil }
+func (s *mapStorage) TTL(string) (time.Duration, error)  { return -1, nil }
+func (s *mapStorage) Clear() error                       { return nil }
+func (s *mapStorage) Ping() error                        { return nil }
+
+const (
+	checkMethod = "/grpc.health.v1.Health/Check"
+	watchMethod = "/grpc.health.v1.Health/Watch"
+)
+
+// startServer serves the health service over bufconn with the plugin interceptors
+func startServer(t *testing.T, p *Plugin) *bufconn.Listener {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpcfw.NewServer(
+		grpcfw.UnaryInterceptor(p.UnaryServerInterceptor()),
+		grpcfw.StreamInterceptor(p.StreamServerInterceptor()),
+	)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	return lis
+}
+
+// dial connects to the bufconn listener
+func dial(t *testing.T, lis *bufconn.Listener, opts ...grpcfw.DialOption) healthpb.HealthClient {
+	t.Helper()
+	opts = append(opts,
+		grpcfw.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpcfw.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	conn, err := grpcfw.NewClient("passthrough:///bufnet", opts...)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func newManager(t *testing.T) (*core.Manager, string) {
+	t.Helper()
+	mgr := core.NewManager(&mapStorage{m: map[string]any{}}, core.DefaultConfig())
+	token, err := mgr.Login("1001")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	_ = mgr.SetPermissions("1001", []string{"health:check"})
+	return mgr, token
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	mgr, token := newManager(t)
+	p := NewPlugin(mgr).TokenKeys("x-token").Rule(checkMethod, RequireLogin(), RequirePermission("health:check"))
+	client := dial(t, startServer(t, p))
+	ctx := context.Background()
+
+	_, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("no token: code = %v, want Unauthenticated", status.Code(err))
+	}
+
+	if _, err := client.Check(WithToken(ctx, token), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Errorf("bearer token: error = %v", err)
+	}
+
+	custom := metadata.AppendToOutgoingContext(ctx, "x-token", token)
+	if _, err := client.Check(custom, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Errorf("custom key: error = %v", err)
+	}
+
+	p.Rule(checkMethod, RequireRole("admin"))
+	_, err = client.Check(WithToken(ctx, token), &healthpb.HealthCheckRequest{})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("missing role: code = %v, want PermissionDenied", status.Code(err))
+	}
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	mgr, token := newManager(t)
+	p := NewPlugin(mgr).Rule("/grpc.health.v1.Health/*", RequireLogin())
+	lis := startServer(t, p)
+
+	stream, err := dial(t, lis).Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("no token: code = %v, want Unauthenticated", status.Code(err))
+	}
+
+	client := dial(t, lis, grpcfw.WithStreamInterceptor(StreamClientInterceptor(StaticToken(token))))
+	stream, err = client.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Errorf("client interceptor: error = %v", err)
+	}
+}
+
+func TestPublicAndDefaultRule(t *testing.T) {
+	mgr, _ := newManager(t)
+	p := NewPlugin(mgr).DefaultRule(RequireLogin()).Rule(checkMethod, Public())
+	client := dial(t, startServer(t, p))
+
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Errorf("public method: error = %v", err)
+	}
+	if _, ok := p.lookup(watchMethod); !ok {
+		t.Error("default rule should apply to methods without a rule")
+	}
+}
+
+func TestToStatusError(t *testing.T) {
+	cases := map[int]codes.Code{
+		core.CodeNotLogin:         codes.Unauthenticated,
+		core.CodePermissionDenied: codes.PermissionDenied,
+		core.CodeNotSafe:          codes.PermissionDenied,
+		core.CodeBadRequest:       codes.InvalidArgument,
+		core.CodeServerError:      codes.Internal,
+	}
+	for code, want := range cases {
+		if got := status.Code(ToStatusError(core.NewError(code, "x", nil))); got != want {
+			t.Errorf("code %d: got %v, want %v", code, got, want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package nethttp
+
+import (
+	"net/http"
+
+	"suwei.sa_token/core"
+	"suwei.sa_token/core/annotation"
+	"suwei.sa_token/stputil"
+)
+
+// Annotation constants | 注解常量
+const (
+	TagSaCheckLogin      = annotation.TagSaCheckLogin
+	TagSaCheckRole       = annotation.TagSaCheckRole
+	TagSaCheckPermission = annotation.TagSaCheckPermission
+	TagSaCheckDisable    = annotation.TagSaCheckDisable
+	TagSaCheckSafe       = annotation.TagSaCheckSafe
+	TagSaIgnore          = annotation.TagSaIgnore
+)
+
+// Annotation annotation structure | 注解结构体
+type Annotation = core.Annotation
+
+// Annotation mode constants | 注解模式常量
+const (
+	ModeAnd = core.AnnotationModeAnd
+	ModeOr  = core.AnnotationModeOr
+)
+
+// ParseTag parses struct tags | 解析结构体标签
+func ParseTag(tag string) *Annotation {
+	return core.ParseAnnotationTag(tag)
+}
+
+// GetHandler gets handler with annotations | 获取带注解的处理器
+func GetHandler(handler http.Handler, annotations ...*Annotation) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		saCtx, err := core.NewMiddlewareEngine(stputil.GetManager()).CheckAnnotations(NewHTTPContext(w, r), annotations...)
+		if err != nil {
+			writeErrorResponse(w, r, err)
+			return
+		}
+		if saCtx != nil {
+			r = r.WithContext(WithSaToken(r.Context(), saCtx))
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// Middleware creates middleware from annotations, e.g. mux.Handle("GET /user", Middleware(ann)(h)) | 根据注解创建中间件
+func Middleware(annotations ...*Annotation) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return GetHandler(next, annotations...)
+	}
+}
+
+// Decorator functions | 装饰器函数
+
+// CheckLogin decorator for login checking | 检查登录装饰器
+func CheckLogin() func(http.Handler) http.Handler {
+	return Middleware(&Annotation{CheckLogin: true})
+}
+
+// CheckRole decorator requiring all roles | 检查角色装饰器（需要全部角色）
+func CheckRole(roles ...string) func(http.Handler) http.Handler {
+	return Middleware(&Annotation{CheckRole: roles, RoleMode: ModeAnd})
+}
+
+// CheckRoleOr decorator requiring any role | 检查角色装饰器（任一角色即可）
+func CheckRoleOr(roles ...string) func(http.Handler) http.Handler {
+	return Middleware(&Annotation{CheckRole: roles, RoleMode: ModeOr})
+}
+
+// CheckPermission decorator requiring all permissions | 检查权限装饰器（需要全部权限）
+func CheckPermission(perms ...string) func(http.Handler) http.Handler {
+	return Middleware(&Annotation{CheckPermission: perms, PermissionMode: ModeAnd})
+}
+
+// CheckPermissionOr decorator requiring any permission | 检查权限装饰器（任一权限即可）
+func CheckPermissionOr(perms ...string) func(http.Handler) http.Handler {
+	return Middleware(&Annotation{CheckPermission: perms, PermissionMode: ModeOr})
+}
+
+// CheckDisable decorator for checking if account is disabled | 检查是否被封禁装饰器
+func CheckDisable() func(http.Handler) http.Handler {
+	return Middleware(&Annotation{CheckDisable: true})
+}
+
+// CheckSafe decorator for second-level auth, empty service means default | 二级认证装饰器，服务为空表示默认服务
+func CheckSafe(service ...string) func(http.Handler) http.Handler {
+	ann := &Annotation{CheckSafe: true}
+	if len(service) > 0 {
+		ann.SafeService = service[0]
+	}
+	return Middleware(ann)
+}
+
+// Ignore decorator to ignore authentication | 忽略认证装饰器
+func Ignore() func(http.Handler) http.Handler {
+	return Middleware(&Annotation{Ignore: true})
+}
+
+// WithAnnotation decorator with custom annotation | 使用自定义注解装饰器
+func WithAnnotation(ann *Annotation) func(http.Handler) http.Handler {
+	return Middleware(ann)
+}
+
+// BindController wraps `sa` tagged handler fields of a controller pointer | 包装控制器指针中带 `sa` 标签的处理器字段
+//
+//	type UserController struct {
+//	    Info   http.HandlerFunc `sa:"login"`
+//	    Delete http.HandlerFunc `sa:"role=admin,permission=user:delete"`
+//	}
+func BindController(controller any) error {
+	return annotation.BindStruct(controller, func(h http.HandlerFunc, ann *Annotation) http.HandlerFunc {
+		return GetHandler(h, ann).ServeHTTP
+	})
+}
@@ -0,0 +1,328 @@
+package nethttp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"suwei.sa_token/core"
+	"suwei.sa_token/core/adapter"
+)
+
+// contextKey Private type for request context keys | 请求上下文键的私有类型
+type contextKey struct{}
+
+// saTokenKey Key of SaTokenContext in http.Request.Context() | SaTokenContext在http.Request.Context()中的键
+var saTokenKey = contextKey{}
+
+// DefaultMaxBodySize Largest body GetBody reads, 1 MiB | GetBody读取的最大请求体，1 MiB
+const DefaultMaxBodySize int64 = 1 << 20
+
+// ContextOptions Settings of HTTPContext | HTTPContext设置
+type ContextOptions struct {
+	// TrustedProxies Networks whose X-Real-IP and X-Forwarded-For are believed, none by default |
+	// 信任其 X-Real-IP 和 X-Forwarded-For 的网络，默认不信任任何网络
+	TrustedProxies []*net.IPNet
+	// MaxBodySize Largest body GetBody reads, DefaultMaxBodySize when 0 | GetBody读取的最大请求体，为0时使用DefaultMaxBodySize
+	MaxBodySize int64
+}
+
+// ParseTrustedProxies Parses IP addresses and CIDRs of trusted proxies | 解析受信任代理的IP地址和CIDR
+func ParseTrustedProxies(proxies ...string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy: %q", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy: %w", err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// HTTPContext net/http request context adapter | net/http请求上下文适配器
+type HTTPContext struct {
+	w       http.ResponseWriter
+	r       *http.Request
+	options ContextOptions
+	mu      sync.RWMutex
+	values  map[string]any
+	aborted bool
+}
+
+// NewHTTPContext creates a net/http context adapter with default options | 使用默认选项创建net/http上下文适配器
+func NewHTTPContext(w http.ResponseWriter, r *http.Request) adapter.RequestContext {
+	return NewHTTPContextWithOptions(w, r, ContextOptions{})
+}
+
+// NewHTTPContextWithOptions creates a net/http context adapter | 创建net/http上下文适配器
+func NewHTTPContextWithOptions(w http.ResponseWriter, r *http.Request, options ContextOptions) adapter.RequestContext {
+	if options.MaxBodySize <= 0 {
+		options.MaxBodySize = DefaultMaxBodySize
+	}
+	return &HTTPContext{
+		w:       w,
+		r:       r,
+		options: options,
+		values:  make(map[string]any),
+	}
+}
+
+// GetHeader gets request header | 获取请求头
+func (c *HTTPContext) GetHeader(key string) string {
+	return c.r.Header.Get(key)
+}
+
+// GetHeaders gets all request headers | 获取所有请求头
+func (c *HTTPContext) GetHeaders() map[string][]string {
+	headers := make(map[string][]string, len(c.r.Header))
+	for key, values := range c.r.Header {
+		headers[key] = values
+	}
+	return headers
+}
+
+// GetQuery gets query parameter | 获取查询参数
+func (c *HTTPContext) GetQuery(key string) string {
+	return c.r.URL.Query().Get(key)
+}
+
+// GetQueryAll gets all query parameters | 获取所有查询参数
+func (c *HTTPContext) GetQueryAll() map[string][]string {
+	query := c.r.URL.Query()
+	params := make(map[string][]string, len(query))
+	for key, values := range query {
+		params[key] = values
+	}
+	return params
+}
+
+// GetPostForm gets form parameter | 获取表单参数
+func (c *HTTPContext) GetPostForm(key string) string {
+	return c.r.PostFormValue(key)
+}
+
+// GetCookie gets cookie | 获取Cookie
+func (c *HTTPContext) GetCookie(key string) string {
+	cookie, err := c.r.Cookie(key)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// GetBody gets request body up to MaxBodySize, the body stays readable for the handler |
+// 获取不超过MaxBodySize的请求体，处理器仍可再次读取
+func (c *HTTPContext) GetBody() ([]byte, error) {
+	original := c.r.Body
+	var read bytes.Buffer
+	body, err := io.ReadAll(http.MaxBytesReader(c.w, io.NopCloser(io.TeeReader(original, &read)), c.options.MaxBodySize))
+	if err != nil {
+		// Hand back everything consumed, the handler decides about the rest | 交还已读取的全部内容，其余由处理器决定
+		c.r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(&read, original), original}
+		return nil, err
+	}
+	c.r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// GetClientIP gets client IP address without port, forwarding headers count only from trusted proxies |
+// 获取不含端口的客户端IP地址，转发请求头仅在来自受信任代理时生效
+func (c *HTTPContext) GetClientIP() string {
+	remote := c.r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	if !c.isTrustedProxy(remote) {
+		return remote
+	}
+
+	if ip := strings.TrimSpace(c.r.Header.Get("X-Real-IP")); net.ParseIP(ip) != nil {
+		return ip
+	}
+	// Walk the chain from the nearest hop, the first untrusted address is the client |
+	// 从最近的一跳向前遍历，第一个不受信任的地址即为客户端
+	hops := strings.Split(strings.Join(c.r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(hops[i])
+		if net.ParseIP(ip) == nil {
+			break
+		}
+		if !c.isTrustedProxy(ip) {
+			return ip
+		}
+		remote = ip
+	}
+	return remote
+}
+
+// isTrustedProxy checks if ip belongs to a trusted proxy | 检查IP是否属于受信任代理
+func (c *HTTPContext) isTrustedProxy(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range c.options.TrustedProxies {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetMethod gets request method | 获取请求方法
+func (c *HTTPContext) GetMethod() string {
+	return c.r.Method
+}
+
+// GetPath gets request path | 获取请求路径
+func (c *HTTPContext) GetPath() string {
+	return c.r.URL.Path
+}
+
+// GetURL gets full request URL | 获取完整请求URL
+func (c *HTTPContext) GetURL() string {
+	return c.r.URL.String()
+}
+
+// GetUserAgent gets User-Agent | 获取User-Agent
+func (c *HTTPContext) GetUserAgent() string {
+	return c.r.UserAgent()
+}
+
+// SetHeader sets response header | 设置响应头
+func (c *HTTPContext) SetHeader(key, value string) {
+	c.w.Header().Set(key, value)
+}
+
+// SetCookie sets cookie | 设置Cookie
+func (c *HTTPContext) SetCookie(name, value string, maxAge int, path, domain string, secure, httpOnly bool) {
+	http.SetCookie(c.w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		MaxAge:   maxAge,
+		Path:     path,
+		Domain:   domain,
+		Secure:   secure,
+		HttpOnly: httpOnly,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// SetCookieWithOptions sets cookie with options | 使用选项设置Cookie
+func (c *HTTPContext) SetCookieWithOptions(options *adapter.CookieOptions) {
+	http.SetCookie(c.w, &http.Cookie{
+		Name:     options.Name,
+		Value:    options.Value,
+		MaxAge:   options.HTTPMaxAge(),
+		Path:     options.Path,
+		Domain:   options.Domain,
+		Secure:   options.Secure,
+		HttpOnly: options.HttpOnly,
+		SameSite: toSameSite(options.SameSite),
+	})
+}
+
+// Set sets context value | 设置上下文值
+func (c *HTTPContext) Set(key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key] = value
+}
+
+// Get gets context value | 获取上下文值
+func (c *HTTPContext) Get(key string) (any, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, exists := c.values[key]
+	return value, exists
+}
+
+// GetString gets string context value | 获取字符串上下文值
+func (c *HTTPContext) GetString(key string) string {
+	if value, exists := c.Get(key); exists {
+		if str, ok := value.(string); ok {
+			return str
+		}
+	}
+	return ""
+}
+
+// MustGet gets context value, panics if missing | 获取上下文值，不存在则panic
+func (c *HTTPContext) MustGet(key string) any {
+	value, exists := c.Get(key)
+	if !exists {
+		panic("key not found: " + key)
+	}
+	return value
+}
+
+// Abort aborts the request | 中止请求
+func (c *HTTPContext) Abort() {
+	c.aborted = true
+}
+
+// IsAborted checks if request is aborted | 检查请求是否已中止
+func (c *HTTPContext) IsAborted() bool {
+	return c.aborted
+}
+
+// ============ Request Context Helpers | 请求上下文辅助函数 ============
+
+// WithSaToken returns a copy of ctx carrying the Sa-Token context | 返回携带Sa-Token上下文的ctx副本
+func WithSaToken(ctx context.Context, saCtx *core.SaTokenContext) context.Context {
+	return context.WithValue(ctx, saTokenKey, saCtx)
+}
+
+// FromContext gets Sa-Token context from a context.Context | 从context.Context获取Sa-Token上下文
+func FromContext(ctx context.Context) (*core.SaTokenContext, bool) {
+	saCtx, ok := ctx.Value(saTokenKey).(*core.SaTokenContext)
+	return saCtx, ok
+}
+
+// GetSaToken gets Sa-Token context from request | 从请求获取Sa-Token上下文
+func GetSaToken(r *http.Request) (*core.SaTokenContext, bool) {
+	return FromContext(r.Context())
+}
+
+// GetLoginIDFromRequest gets login ID of an authenticated request | 获取已认证请求的登录ID
+func GetLoginIDFromRequest(r *http.Request) (string, bool) {
+	saCtx, ok := GetSaToken(r)
+	if !ok {
+		return "", false
+	}
+	loginID, err := saCtx.GetLoginID()
+	return loginID, err == nil
+}
+
+// toSameSite converts SameSite string to http.SameSite | 将SameSite字符串转换为http.SameSite
+func toSameSite(mode string) http.SameSite {
+	switch strings.ToLower(mode) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
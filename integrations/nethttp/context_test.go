@@ -0,0 +1,118 @@
+package nethttp
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"suwei.sa_token/core/adapter"
+)
+
+func TestGetClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies("10.0.0.0/8", "192.168.1.1")
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies() error = %v", err)
+	}
+	if _, err := ParseTrustedProxies("proxy.local"); err == nil {
+		t.Error("ParseTrustedProxies() should reject a host name")
+	}
+
+	tests := []struct {
+		name    string
+		remote  string
+		headers map[string]string
+		trusted bool
+		want    string
+	}{
+		{"port stripped", "203.0.113.7:51234", nil, false, "203.0.113.7"},
+		{"ipv6 port stripped", "[2001:db8::1]:443", nil, false, "2001:db8::1"},
+		{"untrusted forwarding ignored", "203.0.113.7:51234", map[string]string{"X-Real-IP": "1.2.3.4", "X-Forwarded-For": "1.2.3.4"}, true, "203.0.113.7"},
+		{"no proxies trusted by default", "10.0.0.2:80", map[string]string{"X-Forwarded-For": "1.2.3.4"}, false, "10.0.0.2"},
+		{"real ip from proxy", "10.0.0.2:80", map[string]string{"X-Real-IP": "198.51.100.9"}, true, "198.51.100.9"},
+		{"nearest untrusted hop", "10.0.0.2:80", map[string]string{"X-Forwarded-For": "1.2.3.4, 198.51.100.9, 192.168.1.1"}, true, "198.51.100.9"},
+		{"only proxies in chain", "10.0.0.2:80", map[string]string{"X-Forwarded-For": "10.0.0.3"}, true, "10.0.0.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remote
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			options := ContextOptions{}
+			if tt.trusted {
+				options.TrustedProxies = proxies
+			}
+			if got := NewHTTPContextWithOptions(httptest.NewRecorder(), r, options).GetClientIP(); got != tt.want {
+				t.Errorf("GetClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBody(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"satoken":"abc"}`))
+	ctx := NewHTTPContext(httptest.NewRecorder(), r)
+	body, err := ctx.GetBody()
+	if err != nil || string(body) != `{"satoken":"abc"}` {
+		t.Fatalf("GetBody() = %q, %v", body, err)
+	}
+	// The handler can read it again | 处理器可再次读取
+	if again, _ := io.ReadAll(r.Body); string(again) != string(body) {
+		t.Errorf("body after GetBody() = %q", again)
+	}
+
+	// Oversized bodies are refused and stay whole for the handler | 超大请求体被拒绝，处理器仍可完整读取
+	large := strings.Repeat("x", 64)
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(large))
+	ctx = NewHTTPContextWithOptions(httptest.NewRecorder(), r, ContextOptions{MaxBodySize: 16})
+	var tooLarge *http.MaxBytesError
+	if _, err := ctx.GetBody(); !errors.As(err, &tooLarge) {
+		t.Errorf("GetBody() over the limit error = %v", err)
+	}
+	if rest, _ := io.ReadAll(r.Body); string(rest) != large {
+		t.Errorf("body after refused GetBody() has %d bytes", len(rest))
+	}
+}
+
+func TestCookies(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx := NewHTTPContext(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	ctx.SetCookieWithOptions(&adapter.CookieOptions{
+		Name: "satoken", Value: "abc", MaxAge: 60, Path: "/", Secure: true, HttpOnly: true, SameSite: "Strict",
+	})
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("cookies = %v", cookies)
+	}
+	c := cookies[0]
+	if c.Value != "abc" || c.MaxAge != 60 || !c.Secure || !c.HttpOnly || c.SameSite != http.SameSiteStrictMode {
+		t.Errorf("cookie = %+v", c)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(c)
+	if got := NewHTTPContext(httptest.NewRecorder(), r).GetCookie("satoken"); got != "abc" {
+		t.Errorf("GetCookie() = %q", got)
+	}
+
+	// MaxAge 0 deletes, -1 and Session write a session cookie | MaxAge为0删除，-1和Session写入会话Cookie
+	for _, tc := range []struct {
+		options *adapter.CookieOptions
+		header  string
+	}{
+		{&adapter.CookieOptions{Name: "satoken", MaxAge: 0}, "satoken=; Max-Age=0; SameSite=Lax"},
+		{&adapter.CookieOptions{Name: "satoken", Value: "abc", MaxAge: -1}, "satoken=abc; SameSite=Lax"},
+		{&adapter.CookieOptions{Name: "satoken", Value: "abc", MaxAge: 60, Session: true}, "satoken=abc; SameSite=Lax"},
+	} {
+		w := httptest.NewRecorder()
+		NewHTTPContext(w, httptest.NewRequest(http.MethodGet, "/", nil)).SetCookieWithOptions(tc.options)
+		if got := w.Header().Get("Set-Cookie"); got != tc.header {
+			t.Errorf("SetCookieWithOptions(%+v) = %q, want %q", tc.options, got, tc.header)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package nethttp
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"suwei.sa_token/core"
+	"suwei.sa_token/core/adapter"
+)
+
+// Plugin net/http plugin for Sa-Token | net/http插件
+//
+// Middlewares have the standard func(http.Handler) http.Handler shape and work with
+// http.ServeMux, gorilla/mux, chi or any router built on net/http.
+// 中间件为标准的 func(http.Handler) http.Handler 形式，可用于 http.ServeMux、gorilla/mux、
+// chi 以及任何基于 net/http 的路由器。
+type Plugin struct {
+	manager *core.Manager
+	engine  *core.MiddlewareEngine
+	options ContextOptions
+}
+
+// NewPlugin creates a net/http plugin | 创建net/http插件
+func NewPlugin(manager *core.Manager) *Plugin {
+	return &Plugin{
+		manager: manager,
+		engine:  core.NewMiddlewareEngine(manager),
+	}
+}
+
+// SetErrorRenderer overrides the global error renderer for this plugin | 为此插件覆盖全局错误渲染器
+func (p *Plugin) SetErrorRenderer(renderer core.ErrorRenderer) *Plugin {
+	p.engine.SetErrorRenderer(renderer)
+	return p
+}
+
+// SetTrustedProxies trusts X-Real-IP and X-Forwarded-For only from these IPs or CIDRs, e.g. "10.0.0.0/8" |
+// 仅信任来自这些IP或CIDR的 X-Real-IP 和 X-Forwarded-For，例如 "10.0.0.0/8"
+func (p *Plugin) SetTrustedProxies(proxies ...string) error {
+	networks, err := ParseTrustedProxies(proxies...)
+	if err != nil {
+		return err
+	}
+	p.options.TrustedProxies = networks
+	return nil
+}
+
+// SetMaxBodySize limits how much of the body is read for the token, DefaultMaxBodySize by default |
+// 限制读取Token时读取的请求体大小，默认为DefaultMaxBodySize
+func (p *Plugin) SetMaxBodySize(size int64) *Plugin {
+	p.options.MaxBodySize = size
+	return p
+}
+
+// NewContext creates a request context adapter with the plugin's options | 使用插件选项创建请求上下文适配器
+func (p *Plugin) NewContext(w http.ResponseWriter, r *http.Request) adapter.RequestContext {
+	return NewHTTPContextWithOptions(w, r, p.options)
+}
+
+// AuthMiddleware authentication middleware | 认证中间件
+func (p *Plugin) AuthMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			saCtx, err := p.engine.CheckLogin(p.NewContext(w, r))
+			p.proceed(w, r, next, saCtx, err)
+		})
+	}
+}
+
+// PermissionRequired permission validation middleware | 权限验证中间件
+func (p *Plugin) PermissionRequired(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			saCtx, err := p.engine.CheckPermission(p.NewContext(w, r), permission)
+			p.proceed(w, r, next, saCtx, err)
+		})
+	}
+}
+
+// RoleRequired role validation middleware | 角色验证中间件
+func (p *Plugin) RoleRequired(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			saCtx, err := p.engine.CheckRole(p.NewContext(w, r), role)
+			p.proceed(w, r, next, saCtx, err)
+		})
+	}
+}
+
+// AuthorizeRequired policy authorization middleware | 策略授权中间件
+// resolver builds the resource from the request, e.g. from r.PathValue | resolver 从请求构建资源，例如从 r.PathValue
+func (p *Plugin) AuthorizeRequired(action string, resolver func(r *http.Request) (*core.PolicyResource, error)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			saCtx, err := p.engine.Authorize(p.NewContext(w, r), action, func() (*core.PolicyResource, error) {
+				return resolver(r)
+			})
+			p.proceed(w, r, next, saCtx, err)
+		})
+	}
+}
+
+// ACLRequired resource ACL middleware, reads the resource ID with r.PathValue (Go 1.22 ServeMux patterns) |
+// 资源ACL中间件，通过 r.PathValue 读取资源ID（Go 1.22 ServeMux 模式）
+func (p *Plugin) ACLRequired(resourceType, param, action string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			saCtx, err := p.engine.CheckAccess(p.NewContext(w, r), resourceType, r.PathValue(param), action)
+			p.proceed(w, r, next, saCtx, err)
+		})
+	}
+}
+
+// CSRFRequired CSRF middleware, checks unsafe requests authenticated by cookie | CSRF中间件，检查通过Cookie认证的非安全请求
+func (p *Plugin) CSRFRequired() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			saCtx, err := p.engine.CheckCSRF(p.NewContext(w, r))
+			p.proceed(w, r, next, saCtx, err)
+		})
+	}
+}
+
+// RouterMiddleware global route rule middleware, wrap the root handler once | 全局路由规则中间件，包装根处理器一次
+func (p *Plugin) RouterMiddleware(rt *core.Router) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := p.engine.CheckRouter(p.NewContext(w, r), rt); err != nil {
+				p.writeError(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// proceed serves next with the checked context stored in the request, or writes the rendered error |
+// 将检查后的上下文存入请求并继续处理，或写入渲染后的错误
+func (p *Plugin) proceed(w http.ResponseWriter, r *http.Request, next http.Handler, saCtx *core.SaTokenContext, err error) {
+	if err != nil {
+		p.writeError(w, r, err)
+		return
+	}
+	next.ServeHTTP(w, r.WithContext(WithSaToken(r.Context(), saCtx)))
+}
+
+// LoginHandler login handler example, writes the token cookie when IsReadCookie is enabled |
+// 登录处理器示例，开启 IsReadCookie 时写入Token Cookie
+func (p *Plugin) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Device   string `json:"device"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		p.writeError(w, r, core.NewError(core.CodeBadRequest, "invalid request parameters", err))
+		return
+	}
+
+	// TODO: Validate username and password (should call your user service) | 验证用户名密码（这里应该调用你的用户服务）
+
+	device := req.Device
+	if device == "" {
+		device = "default"
+	}
+
+	// Issued through the context so the response header and cookie settings apply | 通过上下文签发，使响应头和Cookie配置生效
+	token, err := core.NewContext(p.NewContext(w, r), p.manager).Login(req.Username, device)
+	if err != nil {
+		p.writeError(w, r, core.NewError(core.CodeServerError, "login failed", err))
+		return
+	}
+
+	writeSuccessResponse(w, map[string]interface{}{
+		"token": token,
+	})
+}
+
+// LogoutHandler logout handler, logs out the current token and clears its cookie | 登出处理器，注销当前Token并清除Cookie
+func (p *Plugin) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	saCtx := core.NewContext(p.NewContext(w, r), p.manager)
+
+	if err := saCtx.CheckLogin(); err != nil {
+		p.writeError(w, r, core.ToSaTokenError(err))
+		return
+	}
+
+	if err := saCtx.Logout(); err != nil {
+		p.writeError(w, r, core.NewError(core.CodeServerError, "logout failed", err))
+		return
+	}
+
+	writeSuccessResponse(w, map[string]interface{}{
+		"message": "logout successful",
+	})
+}
+
+// UserInfoHandler user info handler example | 获取用户信息处理器示例
+func (p *Plugin) UserInfoHandler(w http.ResponseWriter, r *http.Request) {
+	saCtx := core.NewContext(p.NewContext(w, r), p.manager)
+
+	loginID, err := saCtx.GetLoginID()
+	if err != nil {
+		p.writeError(w, r, core.ToSaTokenError(err))
+		return
+	}
+
+	permissions, _ := p.manager.GetPermissions(loginID)
+	roles, _ := p.manager.GetRoles(loginID)
+
+	writeSuccessResponse(w, map[string]interface{}{
+		"loginId":     loginID,
+		"permissions": permissions,
+		"roles":       roles,
+	})
+}
+
+// ============ Error Handling Helpers | 错误处理辅助函数 ============
+
+// writeErrorResponse writes err with the global error renderer | 使用全局错误渲染器写入错误
+func writeErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	writeRendered(w, core.RenderError(NewHTTPContext(w, r), err))
+}
+
+// writeError writes err with the plugin's error renderer | 使用插件的错误渲染器写入错误
+func (p *Plugin) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	writeRendered(w, p.engine.Render(p.NewContext(w, r), err))
+}
+
+// writeRendered writes a rendered error response | 写入渲染后的错误响应
+func writeRendered(w http.ResponseWriter, resp *core.ErrorResponse) {
+	w.Header().Set("Content-Type", resp.ContentType)
+	w.WriteHeader(resp.Status)
+	w.Write(resp.Body)
+}
+
+// writeSuccessResponse writes a standardized success response | 写入标准化的成功响应
+func writeSuccessResponse(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code":    core.CodeSuccess,
+		"message": "success",
+		"data":    data,
+	})
+}
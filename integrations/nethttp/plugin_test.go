@@ -0,0 +1,179 @@
+package nethttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"suwei.sa_token/core"
+)
+
+// mapStorage minimal in-memory core.Storage for tests
+type mapStorage struct {
+	mu sync.Mutex
+	m  map[string]any
+}
+
+func (s *mapStorage) Set(key string, value any, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[key] = value
+	return nil
+}
+func (s *mapStorage) Get(key string) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m[key], nil
+}
+func (s *mapStorage) Exists(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.m[key]
+	return ok
+}
+func (s *mapStorage) Delete(keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, k := range keys {
+		delete(s.m, k)
+	}
+	return nil
+}
+func (s *mapStorage) Keys(pattern string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for k := range s.m {
+		if strings.HasPrefix(k, strings.TrimSuffix(pattern, "*")) {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+func (s *mapStorage) Expire(string, time.Duration) error { return nil }
+func (s *mapStorage) TTL(string) (time.Duration, error)  { return -1, nil }
+func (s *mapStorage) Clear() error                       { return nil }
+func (s *mapStorage) Ping() error                        { return nil }
+
+func newTestPlugin(cfg *core.Config) (*Plugin, *core.Manager) {
+	mgr := core.NewManager(&mapStorage{m: map[string]any{}}, cfg)
+	return NewPlugin(mgr), mgr
+}
+
+// serve Runs one request through handler | 通过处理器执行一次请求
+func serve(handler http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func TestMiddlewares(t *testing.T) {
+	plugin, mgr := newTestPlugin(core.DefaultConfig())
+	tokenValue, _ := mgr.Login("1001")
+	_ = mgr.SetPermissions("1001", []string{"user:read"})
+	tokenName := mgr.GetConfig().TokenName
+
+	var loginID string
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		loginID, _ = GetLoginIDFromRequest(r)
+	})
+	request := func() *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/docs/7", nil)
+		r.Header.Set(tokenName, tokenValue)
+		return r
+	}
+
+	if w := serve(plugin.AuthMiddleware()(ok), httptest.NewRequest(http.MethodGet, "/", nil)); w.Code != http.StatusUnauthorized {
+		t.Errorf("no token: status = %d", w.Code)
+	}
+	if w := serve(plugin.AuthMiddleware()(ok), request()); w.Code != http.StatusOK || loginID != "1001" {
+		t.Errorf("logged in: status = %d, login id = %q", w.Code, loginID)
+	}
+	if w := serve(plugin.PermissionRequired("user:read")(ok), request()); w.Code != http.StatusOK {
+		t.Errorf("granted permission: status = %d", w.Code)
+	}
+	if w := serve(plugin.PermissionRequired("user:write")(ok), request()); w.Code != http.StatusForbidden {
+		t.Errorf("missing permission: status = %d", w.Code)
+	}
+	if w := serve(plugin.RoleRequired("admin")(ok), request()); w.Code != http.StatusForbidden {
+		t.Errorf("missing role: status = %d", w.Code)
+	}
+
+	// ACL reads the ID from the ServeMux pattern, a missing one is a bad request |
+	// ACL从ServeMux模式读取ID，缺失时为错误请求
+	_ = mgr.GrantAccess(core.ACLUserSubject("1001"), "doc", "7", "read")
+	mux := http.NewServeMux()
+	mux.Handle("/docs/{id}", plugin.ACLRequired("doc", "id", "read")(ok))
+	mux.Handle("/docs", plugin.ACLRequired("doc", "id", "read")(ok))
+	if w := serve(mux, request()); w.Code != http.StatusOK {
+		t.Errorf("granted resource: status = %d", w.Code)
+	}
+	r := request()
+	r.URL.Path = "/docs"
+	if w := serve(mux, r); w.Code != http.StatusBadRequest {
+		t.Errorf("missing resource id: status = %d", w.Code)
+	}
+}
+
+func TestLoginLogoutCookie(t *testing.T) {
+	cfg := core.DefaultConfig().SetIsReadCookie(true)
+	plugin, mgr := newTestPlugin(cfg)
+
+	w := serve(http.HandlerFunc(plugin.LoginHandler), httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"username":"1001"}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("login: status = %d, body = %s", w.Code, w.Body)
+	}
+	var tokenCookie *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == cfg.TokenName {
+			tokenCookie = c
+		}
+	}
+	if tokenCookie == nil || !mgr.IsLogin(tokenCookie.Value) {
+		t.Fatalf("login cookie = %+v", tokenCookie)
+	}
+
+	// The cookie alone authenticates | 仅凭Cookie即可认证
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(tokenCookie)
+	if w := serve(plugin.AuthMiddleware()(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})), r); w.Code != http.StatusOK {
+		t.Errorf("cookie auth: status = %d", w.Code)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/logout", nil)
+	r.AddCookie(tokenCookie)
+	w = serve(http.HandlerFunc(plugin.LogoutHandler), r)
+	if w.Code != http.StatusOK || mgr.IsLogin(tokenCookie.Value) {
+		t.Fatalf("logout: status = %d, still logged in = %v", w.Code, mgr.IsLogin(tokenCookie.Value))
+	}
+	// The last Set-Cookie wins | 以最后一个Set-Cookie为准
+	var last *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == cfg.TokenName {
+			last = c
+		}
+	}
+	if last == nil || last.MaxAge >= 0 {
+		t.Errorf("logout should delete the cookie, got %+v", last)
+	}
+}
+
+func TestPluginTrustedProxies(t *testing.T) {
+	plugin, _ := newTestPlugin(core.DefaultConfig())
+	if err := plugin.SetTrustedProxies("not-an-ip"); err == nil {
+		t.Error("SetTrustedProxies() should reject invalid proxies")
+	}
+	if err := plugin.SetTrustedProxies("10.0.0.0/8"); err != nil {
+		t.Fatalf("SetTrustedProxies() error = %v", err)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.1.2.3:4567"
+	r.Header.Set("X-Forwarded-For", "198.51.100.9")
+	if got := plugin.NewContext(httptest.NewRecorder(), r).GetClientIP(); got != "198.51.100.9" {
+		t.Errorf("GetClientIP() = %q", got)
+	}
+}
@@ -92,6 +92,41 @@ func (s *Storage) Get(key string) (any, error) {
 	return item.value, nil
 }
 
+// CompareAndSwap 仅当键当前值为oldValue时设置为newValue（原子操作）
+func (s *Storage) CompareAndSwap(key string, oldValue, newValue string, expiration time.Duration) (bool, error) {
+	now := time.Now().Unix()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, exists := s.data[key]
+	if !exists || current.isExpired(now) {
+		return false, nil
+	}
+	switch value := current.value.(type) {
+	case string:
+		if value != oldValue {
+			return false, nil
+		}
+	case []byte:
+		if string(value) != oldValue {
+			return false, nil
+		}
+	default:
+		return false, nil
+	}
+
+	var exp int64
+	if expiration > 0 {
+		exp = time.Now().Add(expiration).Unix()
+	}
+	s.data[key] = &item{
+		value:      newValue,
+		expiration: exp,
+	}
+	return true, nil
+}
+
 // Delete 删除键
 func (s *Storage) Delete(keys ...string) error {
 	s.mu.Lock()
@@ -120,6 +120,30 @@ func (s *Storage) Get(key string) (any, error) {
 	return val, nil
 }
 
+// compareAndSwapScript 比较并交换脚本：值相等时才写入（ARGV[3]为毫秒过期时间，0表示永不过期）
+var compareAndSwapScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+if tonumber(ARGV[3]) > 0 then
+	redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+else
+	redis.call("SET", KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// CompareAndSwap 仅当键当前值为oldValue时设置为newValue（原子操作）
+func (s *Storage) CompareAndSwap(key string, oldValue, newValue string, expiration time.Duration) (bool, error) {
+	ctx, cancel := s.withTimeout()
+	defer cancel()
+	swapped, err := compareAndSwapScript.Run(ctx, s.client, []string{s.getKey(key)}, oldValue, newValue, expiration.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return swapped == 1, nil
+}
+
 // Delete 删除键
 func (s *Storage) Delete(keys ...string) error {
 	if len(keys) == 0 {
@@ -147,7 +171,7 @@ func (s *Storage) Exists(key string) bool {
 	return result > 0
 }
 
-// Keys 获取匹配模式的所有键
+// Keys 获取匹配模式的所有键，使用 SCAN 分批遍历，不会像 KEYS 那样阻塞 Redis
 func (s *Storage) Keys(pattern string) ([]string, error) {
 	ctx, cancel := s.withTimeout()
 	defer cancel()
@@ -7,6 +7,7 @@ import (
 
 	"suwei.sa_token/core/manager"
 	"suwei.sa_token/core/oauth2"
+	"suwei.sa_token/core/policy"
 	"suwei.sa_token/core/security"
 	"suwei.sa_token/core/session"
 )
@@ -42,6 +43,11 @@ func Login(loginID interface{}, device ...string) (string, error) {
 	return GetManager().Login(toString(loginID), device...)
 }
 
+// LoginWithOptions performs user login with per-login options | 使用单次登录选项登录
+func LoginWithOptions(loginID interface{}, opts *manager.LoginOptions) (string, error) {
+	return GetManager().LoginWithOptions(toString(loginID), opts)
+}
+
 // LoginByToken performs login with specified token | 使用指定Token登录
 func LoginByToken(loginID interface{}, tokenValue string, device ...string) error {
 	return GetManager().LoginByToken(toString(loginID), tokenValue, device...)
@@ -87,6 +93,11 @@ func GetTokenInfo(tokenValue string) (*manager.TokenInfo, error) {
 	return GetManager().GetTokenInfo(tokenValue)
 }
 
+// GetTokenTimeout gets the timeout a token was issued with in seconds | 获取Token签发时的超时时间（秒）
+func GetTokenTimeout(tokenValue string) int64 {
+	return GetManager().GetTokenTimeout(tokenValue)
+}
+
 // ============ Kickout | 踢人下线 ============
 
 // Kickout kicks out a user session | 踢人下线
@@ -116,6 +127,57 @@ func GetDisableTime(loginID interface{}) (int64, error) {
 	return GetManager().GetDisableTime(toString(loginID))
 }
 
+// ============ Second-level Auth | 二级认证 ============
+
+// OpenSafe opens second-level auth for token | 为Token开启二级认证
+func OpenSafe(tokenValue, service string, duration time.Duration) error {
+	return GetManager().OpenSafe(tokenValue, service, duration)
+}
+
+// IsSafe checks second-level auth of token | 检查Token是否处于二级认证状态
+func IsSafe(tokenValue, service string) bool {
+	return GetManager().IsSafe(tokenValue, service)
+}
+
+// CheckSafe checks second-level auth (throws error if not open) | 检查二级认证（未开启抛出错误）
+func CheckSafe(tokenValue, service string) error {
+	return GetManager().CheckSafe(tokenValue, service)
+}
+
+// GetSafeTime gets remaining second-level auth time in seconds | 获取二级认证剩余时间（秒）
+func GetSafeTime(tokenValue, service string) (int64, error) {
+	return GetManager().GetSafeTime(tokenValue, service)
+}
+
+// CloseSafe closes second-level auth | 关闭二级认证
+func CloseSafe(tokenValue, service string) error {
+	return GetManager().CloseSafe(tokenValue, service)
+}
+
+// ============ One-time Tickets | 一次性票据 ============
+
+// IssueTicket issues a one-time ticket for WebSocket/SSE handshakes | 签发用于WebSocket/SSE握手的一次性票据
+func IssueTicket(tokenValue string, ttl time.Duration) (string, error) {
+	return GetManager().IssueTicket(tokenValue, ttl)
+}
+
+// ConsumeTicket consumes a ticket and returns its token | 消费票据并返回对应的Token
+func ConsumeTicket(ticket string) (string, error) {
+	return GetManager().ConsumeTicket(ticket)
+}
+
+// ============ CSRF Tokens | CSRF令牌 ============
+
+// GetCSRFToken gets the CSRF token of a logged-in token, issuing one if missing | 获取已登录Token的CSRF令牌，不存在时签发
+func GetCSRFToken(tokenValue string) (string, error) {
+	return GetManager().GetCSRFToken(tokenValue)
+}
+
+// CheckCSRFToken checks a submitted CSRF token against the token's one | 校验提交的CSRF令牌是否与Token的一致
+func CheckCSRFToken(tokenValue, csrfToken string) error {
+	return GetManager().CheckCSRFToken(tokenValue, csrfToken)
+}
+
 // ============ Session Management | Session管理 ============
 
 // GetSession gets session by login ID | 根据登录ID获取Session
@@ -187,6 +249,59 @@ func HasRolesOr(loginID interface{}, roles []string) bool {
 	return GetManager().HasRolesOr(toString(loginID), roles)
 }
 
+// ============ Time-limited Grants | 限时授权 ============
+
+// GrantPermission grants a permission for duration | 限时授予权限
+func GrantPermission(loginID interface{}, permission string, duration time.Duration) error {
+	return GetManager().GrantPermission(toString(loginID), permission, duration)
+}
+
+// RevokePermissionGrant revokes a time-limited permission | 撤销限时权限
+func RevokePermissionGrant(loginID interface{}, permission string) error {
+	return GetManager().RevokePermissionGrant(toString(loginID), permission)
+}
+
+// ListPermissionGrants lists active and expired permission grants | 列出有效和已过期的权限授权
+func ListPermissionGrants(loginID interface{}) ([]manager.TimedGrant, error) {
+	return GetManager().ListPermissionGrants(toString(loginID))
+}
+
+// GrantRole grants a role for duration | 限时授予角色
+func GrantRole(loginID interface{}, role string, duration time.Duration) error {
+	return GetManager().GrantRole(toString(loginID), role, duration)
+}
+
+// RevokeRoleGrant revokes a time-limited role | 撤销限时角色
+func RevokeRoleGrant(loginID interface{}, role string) error {
+	return GetManager().RevokeRoleGrant(toString(loginID), role)
+}
+
+// ListRoleGrants lists active and expired role grants | 列出有效和已过期的角色授权
+func ListRoleGrants(loginID interface{}) ([]manager.TimedGrant, error) {
+	return GetManager().ListRoleGrants(toString(loginID))
+}
+
+// ============ Policy Authorization | 策略授权 ============
+
+// RegisterPolicy registers an ABAC policy | 注册ABAC策略
+func RegisterPolicy(name string, p policy.Policy) error {
+	return GetManager().RegisterPolicy(name, p)
+}
+
+// Authorize evaluates policies for action on resource | 评估对资源执行操作的策略
+func Authorize(loginID interface{}, action string, resource *policy.Resource) (policy.Decision, error) {
+	return GetManager().Authorize(toString(loginID), action, resource)
+}
+
+// CheckAuthorize checks policies for the token (only an explicit Allow passes) | 检查Token对应账号的策略（只有明确允许才通过）
+func CheckAuthorize(tokenValue string, action string, resource *policy.Resource) error {
+	loginID, err := GetLoginID(tokenValue)
+	if err != nil {
+		return err
+	}
+	return GetManager().CheckAuthorize(loginID, action, resource)
+}
+
 // ============ Token标签 ============
 
 // SetTokenTag 设置Token标签
@@ -316,6 +431,34 @@ func RevokeRefreshToken(refreshToken string) error {
 	return globalManager.RevokeRefreshToken(refreshToken)
 }
 
+func GetRefreshTokenListByLoginID(loginID interface{}, device ...string) ([]*security.RefreshTokenInfo, error) {
+	if globalManager == nil {
+		panic("Manager not initialized. Call stputil.SetManager() first")
+	}
+	return globalManager.GetRefreshTokenListByLoginID(fmt.Sprintf("%v", loginID), device...)
+}
+
+func RevokeRefreshTokensByLoginID(loginID interface{}, device ...string) error {
+	if globalManager == nil {
+		panic("Manager not initialized. Call stputil.SetManager() first")
+	}
+	return globalManager.RevokeRefreshTokensByLoginID(fmt.Sprintf("%v", loginID), device...)
+}
+
+func RevokeJTI(jti string, exp time.Time) error {
+	if globalManager == nil {
+		panic("Manager not initialized. Call stputil.SetManager() first")
+	}
+	return globalManager.RevokeJTI(jti, exp)
+}
+
+func SyncRevocations() error {
+	if globalManager == nil {
+		panic("Manager not initialized. Call stputil.SetManager() first")
+	}
+	return globalManager.SyncRevocations()
+}
+
 func GetOAuth2Server() *oauth2.OAuth2Server {
 	if globalManager == nil {
 		panic("Manager not initialized. Call stputil.SetManager() first")